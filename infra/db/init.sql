@@ -1,19 +1,33 @@
 CREATE DATABASE IF NOT EXISTS amr_fleet;
 USE amr_fleet;
 
+CREATE TABLE IF NOT EXISTS experiments (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    description TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE IF NOT EXISTS runs (
     id VARCHAR(64) PRIMARY KEY,
     mode ENUM('baseline','ga') NOT NULL,
     seed INT NOT NULL,
-    scale ENUM('mini','small','demo','large') NOT NULL,
+    scale VARCHAR(32) NOT NULL,
     robots_count INT NULL,
     jobs_count INT NULL,
     scenario_hash VARCHAR(128) NOT NULL,
-    status ENUM('started','completed','failed') NOT NULL DEFAULT 'started',
+    scenario_hash_version INT NOT NULL DEFAULT 1,
+    status ENUM('started','completed','failed','stopped','cancelled') NOT NULL DEFAULT 'started',
     error_message TEXT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    completed_at TIMESTAMP NULL
+    completed_at TIMESTAMP NULL,
+    ga_params JSON NULL,
+    retry_of VARCHAR(64) NULL,
+    experiment_id VARCHAR(64) NULL,
+    progress_pct DOUBLE NULL,
+    replan_interval_s INT NULL,
+    CONSTRAINT fk_runs_experiment FOREIGN KEY (experiment_id) REFERENCES experiments(id) ON DELETE SET NULL
 );
 
 CREATE TABLE IF NOT EXISTS run_metrics (
@@ -29,6 +43,21 @@ CREATE TABLE IF NOT EXISTS run_metrics (
     CONSTRAINT fk_run_metrics_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS run_metrics_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    run_id VARCHAR(64) NOT NULL,
+    sim_time_s INT NULL,
+    on_time_rate DOUBLE NOT NULL,
+    total_distance DOUBLE NOT NULL,
+    avg_completion_time DOUBLE NOT NULL,
+    max_lateness DOUBLE NOT NULL,
+    completed_jobs INT NOT NULL,
+    failed_jobs INT NOT NULL,
+    total_jobs INT NOT NULL,
+    recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_run_metrics_history_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS jobs (
     id VARCHAR(64) NOT NULL,
     run_id VARCHAR(64) NOT NULL,
@@ -72,7 +101,48 @@ CREATE TABLE IF NOT EXISTS run_events (
     CONSTRAINT fk_run_events_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS run_notes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    run_id VARCHAR(64) NOT NULL,
+    author VARCHAR(64) NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_run_notes_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS run_tags (
+    run_id VARCHAR(64) NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (run_id, tag),
+    CONSTRAINT fk_run_tags_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    action VARCHAR(32) NOT NULL,
+    run_id VARCHAR(64) NULL,
+    outcome VARCHAR(16) NOT NULL,
+    error_message VARCHAR(500) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE INDEX idx_telemetry_run_robot_ts ON telemetry (run_id, robot_id, sim_time_s);
 CREATE INDEX idx_jobs_run_state_deadline ON jobs (run_id, state, deadline_ts);
 CREATE INDEX idx_runs_status_created ON runs (status, created_at);
+CREATE INDEX idx_runs_retry_of ON runs (retry_of);
+CREATE INDEX idx_runs_experiment_created ON runs (experiment_id, created_at);
 CREATE INDEX idx_run_metrics_created ON run_metrics (created_at);
+CREATE INDEX idx_run_metrics_history_run ON run_metrics_history (run_id, id);
+CREATE INDEX idx_run_notes_run_created ON run_notes (run_id, created_at);
+CREATE INDEX idx_maintenance_windows_ends ON maintenance_windows (ends_at);
+CREATE INDEX idx_audit_log_run_created ON audit_log (run_id, created_at);
+CREATE INDEX idx_run_events_run_created ON run_events (run_id, created_at);
+CREATE INDEX idx_run_tags_tag_run ON run_tags (tag, run_id);
@@ -0,0 +1,2 @@
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS scenario_hash_version INT NOT NULL DEFAULT 1;
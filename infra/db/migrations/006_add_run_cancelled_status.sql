@@ -0,0 +1,2 @@
+ALTER TABLE runs
+MODIFY COLUMN status ENUM('started','completed','failed','stopped','cancelled') NOT NULL DEFAULT 'started';
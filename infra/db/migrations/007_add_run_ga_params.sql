@@ -0,0 +1,2 @@
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS ga_params JSON NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS retry_of VARCHAR(64) NULL;
@@ -0,0 +1 @@
+CREATE INDEX idx_runs_retry_of ON runs (retry_of);
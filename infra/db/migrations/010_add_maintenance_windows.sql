@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_maintenance_windows_ends ON maintenance_windows (ends_at);
@@ -0,0 +1,2 @@
+ALTER TABLE runs
+MODIFY COLUMN scale VARCHAR(32) NOT NULL;
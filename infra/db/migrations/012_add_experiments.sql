@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS experiments (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    description TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS experiment_id VARCHAR(64) NULL;
+
+CREATE INDEX idx_runs_experiment_created ON runs (experiment_id, created_at);
+
+ALTER TABLE runs
+ADD CONSTRAINT fk_runs_experiment FOREIGN KEY (experiment_id) REFERENCES experiments(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS progress_pct DOUBLE NULL;
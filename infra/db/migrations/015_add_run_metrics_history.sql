@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS run_metrics_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    run_id VARCHAR(64) NOT NULL,
+    sim_time_s INT NULL,
+    on_time_rate DOUBLE NOT NULL,
+    total_distance DOUBLE NOT NULL,
+    avg_completion_time DOUBLE NOT NULL,
+    max_lateness DOUBLE NOT NULL,
+    completed_jobs INT NOT NULL,
+    failed_jobs INT NOT NULL,
+    total_jobs INT NOT NULL,
+    recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_run_metrics_history_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_run_metrics_history_run ON run_metrics_history (run_id, id);
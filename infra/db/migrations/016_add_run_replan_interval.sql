@@ -0,0 +1,2 @@
+ALTER TABLE runs
+ADD COLUMN IF NOT EXISTS replan_interval_s INT NULL;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS run_tags (
+    run_id VARCHAR(64) NOT NULL,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (run_id, tag),
+    CONSTRAINT fk_run_tags_run FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_run_tags_tag_run ON run_tags (tag, run_id);
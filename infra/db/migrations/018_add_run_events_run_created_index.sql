@@ -0,0 +1 @@
+CREATE INDEX idx_run_events_run_created ON run_events (run_id, created_at);
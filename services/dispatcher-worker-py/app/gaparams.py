@@ -0,0 +1,21 @@
+from __future__ import annotations
+
+"""
+File: services/dispatcher-worker-py/app/gaparams.py
+Purpose: Per-run GA optimizer parameters.
+Key responsibilities:
+- Read a run's ga_params (sent by fleet-api) from run.started for optimizer-service.
+"""
+
+from typing import Any
+
+GA_PARAM_NAMES = ("population_size", "generations", "elite_size", "mutation_rate", "crossover_rate")
+
+
+def resolve_ga_params(event: dict[str, Any]) -> dict[str, Any] | None:
+    """Return the run's set ga_params fields, or None to use the optimizer defaults."""
+    raw = event.get("ga_params")
+    if not isinstance(raw, dict):
+        return None
+    params = {name: raw[name] for name in GA_PARAM_NAMES if raw.get(name) is not None}
+    return params or None
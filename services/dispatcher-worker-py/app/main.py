@@ -25,8 +25,10 @@ from typing import Any
 import aio_pika
 
 from app.baseline import compute_baseline_assignments
+from app.gaparams import resolve_ga_params
 from app.mq import connect, publish_event, setup_topology
 from app.planner_client import request_ga_plan
+from app.replan import resolve_replan_interval
 from app.settings import rabbit_url, settings
 
 logging.basicConfig(
@@ -49,6 +51,8 @@ class RunState:
     pending_assignments: dict[int, str] = field(default_factory=dict)
     planned_queues: dict[int, list[str]] = field(default_factory=dict)
     optimizer_in_flight: bool = False
+    replan_interval_s: int = 0
+    ga_params: dict[str, Any] | None = None
     next_periodic_replan_sim_s: int | None = None
     last_baseline_dispatch_sim_s: int | None = None
     baseline_lock: asyncio.Lock = field(default_factory=asyncio.Lock)
@@ -109,13 +113,16 @@ class DispatcherWorker:
         mode = str(event.get("mode", settings.fleet_mode))
         seed = int(event.get("seed", settings.fleet_seed))
         scale = str(event.get("scale", settings.fleet_scale))
+        replan_interval_s = resolve_replan_interval(event, settings.ga_replan_interval_s)
 
         state = RunState(
             run_id=run_id,
             mode=mode,
             seed=seed,
             scale=scale,
-            next_periodic_replan_sim_s=settings.ga_replan_interval_s if settings.ga_replan_interval_s > 0 else None,
+            replan_interval_s=replan_interval_s,
+            ga_params=resolve_ga_params(event),
+            next_periodic_replan_sim_s=replan_interval_s if replan_interval_s > 0 else None,
         )
         self.states[run_id] = state
         logger.info("run started run_id=%s mode=%s seed=%s scale=%s", run_id, mode, seed, scale)
@@ -219,7 +226,7 @@ class DispatcherWorker:
         await self._emit_planned_for_idle_robot(state, robot_id=robot_id, sim_time_s=sim_time_s)
 
         if (
-            settings.ga_replan_interval_s > 0
+            state.replan_interval_s > 0
             and state.next_periodic_replan_sim_s is not None
             and sim_time_s >= state.next_periodic_replan_sim_s
             and self._has_pending_jobs(state)
@@ -227,7 +234,7 @@ class DispatcherWorker:
         ):
             await self._replan_ga(state, sim_time_s=sim_time_s, reason="periodic")
             while state.next_periodic_replan_sim_s is not None and state.next_periodic_replan_sim_s <= sim_time_s:
-                state.next_periodic_replan_sim_s += settings.ga_replan_interval_s
+                state.next_periodic_replan_sim_s += state.replan_interval_s
 
         transitioned_to_idle = prev_state != "idle" and new_state == "idle"
         queue_empty = len(state.planned_queues.get(robot_id, [])) == 0
@@ -313,6 +320,7 @@ class DispatcherWorker:
                 sim_time_s=sim_time_s,
                 robots=robots,
                 pending_jobs=pending,
+                ga_params=state.ga_params,
             )
 
             new_queues: dict[int, list[str]] = {int(r["id"]): [] for r in robots}
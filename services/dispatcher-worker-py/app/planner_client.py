@@ -4,7 +4,7 @@ from __future__ import annotations
 File: services/dispatcher-worker-py/app/planner_client.py
 Purpose: HTTP client for optimizer-service GA planning.
 Key responsibilities:
-- Call /optimize with current robots + pending jobs.
+- Call /optimize with current robots + pending jobs (and the run's ga_params, if any).
 - Normalize and sort assignment responses.
 """
 
@@ -19,6 +19,7 @@ async def request_ga_plan(
     sim_time_s: int,
     robots: list[dict],
     pending_jobs: list[dict],
+    ga_params: dict | None = None,
 ) -> list[dict]:
     """Call optimizer-service and return normalized assignments."""
     payload = {
@@ -30,6 +31,8 @@ async def request_ga_plan(
         "robots": robots,
         "pending_jobs": pending_jobs,
     }
+    if ga_params:
+        payload["ga_params"] = ga_params
     async with httpx.AsyncClient(timeout=10.0) as client:
         resp = await client.post(f"{optimizer_url}/optimize", json=payload)
         resp.raise_for_status()
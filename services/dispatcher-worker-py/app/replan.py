@@ -0,0 +1,21 @@
+from __future__ import annotations
+
+"""
+File: services/dispatcher-worker-py/app/replan.py
+Purpose: Per-run periodic GA replan interval.
+Key responsibilities:
+- Prefer the run's replan_interval_s (sent by fleet-api) over GA_REPLAN_INTERVAL_S.
+"""
+
+from typing import Any
+
+
+def resolve_replan_interval(event: dict[str, Any], default: int) -> int:
+    """Return the periodic replan interval for a run.started event; 0 disables periodic replans."""
+    value = event.get("replan_interval_s")
+    if value is None:
+        return max(default, 0)
+    try:
+        return max(int(value), 0)
+    except (TypeError, ValueError):
+        return max(default, 0)
@@ -0,0 +1,13 @@
+from app.gaparams import resolve_ga_params
+
+
+def test_ga_params_absent_use_optimizer_defaults():
+    assert resolve_ga_params({"run_id": "r1"}) is None
+    assert resolve_ga_params({"ga_params": None}) is None
+    assert resolve_ga_params({"ga_params": {}}) is None
+    assert resolve_ga_params({"ga_params": "fast"}) is None
+
+
+def test_ga_params_keep_set_known_fields():
+    event = {"ga_params": {"generations": 100, "mutation_rate": 0.05, "elite_size": None, "seed": 7}}
+    assert resolve_ga_params(event) == {"generations": 100, "mutation_rate": 0.05}
@@ -0,0 +1,16 @@
+from app.replan import resolve_replan_interval
+
+
+def test_replan_interval_defaults_when_unset():
+    assert resolve_replan_interval({"run_id": "r1"}, 30) == 30
+    assert resolve_replan_interval({"run_id": "r1", "replan_interval_s": None}, 0) == 0
+
+
+def test_replan_interval_prefers_event_value():
+    assert resolve_replan_interval({"replan_interval_s": 15}, 30) == 15
+    assert resolve_replan_interval({"replan_interval_s": "45"}, 0) == 45
+
+
+def test_replan_interval_ignores_invalid_values():
+    assert resolve_replan_interval({"replan_interval_s": "soon"}, 30) == 30
+    assert resolve_replan_interval({"replan_interval_s": -5}, 30) == 0
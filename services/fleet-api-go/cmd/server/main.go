@@ -5,8 +5,9 @@ package main
 // Purpose: Process entrypoint for the fleet-api service.
 // Key responsibilities:
 // - Load config from environment.
-// - Connect to MySQL and RabbitMQ.
-// - Register HTTP routes and start the server.
+// - Connect to MySQL (and check its schema) and RabbitMQ (optionally checking the simulator queue).
+// - Register HTTP routes and start the server (plus the admin listener when ADMIN_PORT is set).
+// - Run optional background tasks (heartbeat) and stop them on shutdown.
 // Key entrypoints: main()
 
 import (
@@ -16,9 +17,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
 	httpx "fleet-api-go/internal/http"
 
 	"fleet-api-go/internal/config"
@@ -33,26 +39,82 @@ func main() {
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
+	log.Printf("enabled features: %v", cfg.Features.Names())
 
 	store, err := db.New(cfg.DSN())
 	if err != nil {
 		log.Fatalf("connect db: %v", err)
 	}
 	defer store.Close()
+	if cfg.SkipSchemaCheck {
+		log.Printf("schema check skipped (SKIP_SCHEMA_CHECK=true)")
+	} else {
+		err := retryWithin(cfg.SchemaCheckRetry, "schema check", func() error {
+			schemaCtx, cancelSchema := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancelSchema()
+			return store.CheckSchema(schemaCtx)
+		})
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+	}
 
-	publisher, err := mq.NewPublisher(cfg.RabbitURL(), cfg.ExchangeName)
+	publisher, err := mq.NewPublisher(mq.Options{
+		URLs:            cfg.RabbitURLs(),
+		Exchange:        cfg.ExchangeName,
+		ConnectionName:  cfg.RabbitConnectionName(),
+		Channels:        cfg.PublishChannels,
+		DelayedExchange: cfg.DelayedExchange,
+		SampleRates:     cfg.EventSampleRates,
+		MessageTTL:      cfg.EventTTL,
+	})
 	if err != nil {
 		log.Fatalf("connect rabbitmq: %v", err)
 	}
 	defer publisher.Close()
+	if cfg.VerifyBindings {
+		verifyRunQueue(publisher, mq.ManagementAPI{
+			URL:      cfg.RabbitManagementURL,
+			User:     cfg.RabbitUser,
+			Password: cfg.RabbitPass,
+			Client:   &http.Client{Timeout: verifyTimeout},
+		}, cfg.VerifyQueue, cfg.ExchangeName)
+	}
 
 	runService := services.NewRunService(cfg, store, publisher)
-	h := handlers.New(runService)
+	h := handlers.New(runService, handlers.Options{
+		Features:      cfg.Features,
+		DebugErrors:   cfg.DebugErrors,
+		SeparateAdmin: cfg.AdminPort > 0,
+	})
 
-	router := httpx.NewRouter(h.Register)
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	if cfg.Features.Enabled(config.FeatureHeartbeat) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			runService.RunHeartbeat(bgCtx, cfg.HeartbeatEvery)
+		}()
+	}
+
+	inFlight := httpx.NewInFlight()
+	router := httpx.NewRouter(h.Register, httpx.Options{
+		BasePath:      cfg.APIBasePath,
+		ExemptPaths:   cfg.APIBaseExempt,
+		SampledPaths:  cfg.LogSamplePaths,
+		SampleRate:    cfg.LogSampleRate,
+		MaxBodyBytes:  cfg.MaxBodyBytes,
+		ReadOnly:      cfg.ReadOnly,
+		RequireJSON:   cfg.RequireJSON,
+		CamelCaseJSON: cfg.JSONFieldCase == "camel",
+		OmitNulls:     cfg.OmitNulls,
+		InFlight:      inFlight,
+		GzipLevel:     cfg.GzipLevel,
+	})
 	server := &http.Server{
 		Addr:         ":" + intToString(cfg.Port),
-		Handler:      router,
+		Handler:      publicHandler(router, cfg.Features),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -65,15 +127,138 @@ func main() {
 		}
 	}()
 
+	var adminServer *http.Server
+	if cfg.AdminPort > 0 {
+		adminServer = &http.Server{
+			Addr: ":" + intToString(cfg.AdminPort),
+			Handler: httpx.NewRouter(h.RegisterAdmin, httpx.Options{
+				MaxBodyBytes: cfg.MaxBodyBytes,
+				ReadOnly:     cfg.ReadOnly,
+				RequireJSON:  cfg.RequireJSON,
+				InFlight:     inFlight,
+			}),
+			ReadTimeout: 10 * time.Second,
+			// Long enough for CPU profiles and traces longer than the public write timeout.
+			WriteTimeout: 120 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+		go func() {
+			log.Printf("fleet-api admin listening on %s", adminServer.Addr)
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("admin listen: %v", err)
+			}
+		}()
+	}
+
 	shutdownCh := make(chan os.Signal, 1)
 	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
 	<-shutdownCh
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	log.Printf("shutting down: draining %d requests", inFlight.Count())
+	drainStart := time.Now()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("shutdown error: %v", err)
 	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			log.Printf("admin shutdown error: %v", err)
+		}
+	}
+	log.Printf("drained in %s (%d requests still in flight)", time.Since(drainStart).Round(time.Millisecond), inFlight.Count())
+	stopBackground()
+	background.Wait()
+}
+
+// publicHandler wraps the public router for cleartext HTTP/2 when the h2c feature is
+// on, for gateways that speak h2c; HTTP/1.1 clients are still served.
+func publicHandler(router http.Handler, features config.Features) http.Handler {
+	if features.Enabled(config.FeatureH2C) {
+		return h2c.NewHandler(router, &http2.Server{})
+	}
+	return router
+}
+
+// verifyRunQueue warns when the queue sim-runner takes run events from is missing,
+// has no consumers, or is not bound to run.created and run.started: runs would then
+// be created but never simulated. It never stops startup, since sim-runner may
+// simply connect later.
+func verifyRunQueue(publisher queueInspector, mgmt bindingLister, queue, exchange string) {
+	q, err := publisher.InspectQueue(queue)
+	switch {
+	case err != nil:
+		log.Printf("WARNING: VERIFY_BINDINGS: %v; run.created/run.started events have no simulator queue and are dropped", err)
+		return
+	case q.Consumers == 0:
+		log.Printf("WARNING: VERIFY_BINDINGS: queue %s has no consumers (%d messages waiting); runs will not start until sim-runner connects", q.Name, q.Messages)
+	default:
+		log.Printf("verified queue %s: %d consumers", q.Name, q.Consumers)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+	missing, err := mgmt.MissingBindings(ctx, queue, exchange, runQueueKeys)
+	switch {
+	case err != nil:
+		log.Printf("WARNING: VERIFY_BINDINGS: could not check bindings: %v", err)
+	case len(missing) > 0:
+		log.Printf("WARNING: VERIFY_BINDINGS: queue %s is not bound to %s on %s; those events are dropped", queue, strings.Join(missing, ", "), exchange)
+	default:
+		log.Printf("verified queue %s bindings: %s", queue, strings.Join(runQueueKeys, ", "))
+	}
+}
+
+// runQueueKeys are the routing keys sim-runner's run queue must be bound to.
+var runQueueKeys = []string{"run.created", "run.started"}
+
+// verifyTimeout bounds the startup management API call.
+const verifyTimeout = 5 * time.Second
+
+// queueInspector and bindingLister are the parts of mq verifyRunQueue uses,
+// replaced in tests.
+type (
+	queueInspector interface {
+		InspectQueue(queue string) (mq.QueueState, error)
+	}
+	bindingLister interface {
+		MissingBindings(ctx context.Context, queue, exchange string, keys []string) ([]string, error)
+	}
+)
+
+// Startup retries back off from retryInitialBackoff, doubling up to retryMaxBackoff.
+const (
+	retryInitialBackoff = 500 * time.Millisecond
+	retryMaxBackoff     = 10 * time.Second
+)
+
+// retryNow and retrySleep are retryWithin's clock, replaced in tests.
+var (
+	retryNow   = time.Now
+	retrySleep = time.Sleep
+)
+
+// retryWithin calls fn until it succeeds or window has elapsed, sleeping with
+// doubling backoff between attempts, and returns the last error. A zero window
+// makes a single attempt. It covers deployments where migrations run as a
+// separate job that may finish after this process starts.
+func retryWithin(window time.Duration, what string, fn func() error) error {
+	deadline := retryNow().Add(window)
+	backoff := retryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		remaining := deadline.Sub(retryNow())
+		if remaining <= 0 {
+			return err
+		}
+		wait := min(backoff, remaining)
+		log.Printf("%s attempt %d failed, retrying in %s: %v", what, attempt, wait, err)
+		retrySleep(wait)
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
 }
 
 func intToString(v int) string {
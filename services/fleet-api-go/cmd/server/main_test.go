@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/mq"
+)
+
+// fakeRetryClock replaces retryWithin's clock with one that only advances when
+// it sleeps, recording each sleep.
+func fakeRetryClock(t *testing.T) *[]time.Duration {
+	t.Helper()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var sleeps []time.Duration
+	prevNow, prevSleep := retryNow, retrySleep
+	retryNow = func() time.Time { return now }
+	retrySleep = func(d time.Duration) {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+	}
+	t.Cleanup(func() { retryNow, retrySleep = prevNow, prevSleep })
+	return &sleeps
+}
+
+func TestRetryWithinBacksOffUntilTheWindowCloses(t *testing.T) {
+	sleeps := fakeRetryClock(t)
+	errDown := errors.New("schema missing")
+	calls := 0
+	err := retryWithin(30*time.Second, "schema check", func() error {
+		calls++
+		return errDown
+	})
+	if !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want the last attempt's error", err)
+	}
+	// 0.5+1+2+4+8 = 15.5s, then capped at 10s, then the 4.5s left in the window.
+	want := []time.Duration{
+		500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		10 * time.Second, 4500 * time.Millisecond,
+	}
+	if !slices.Equal(*sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", *sleeps, want)
+	}
+	if calls != len(want)+1 {
+		t.Errorf("calls = %d, want %d", calls, len(want)+1)
+	}
+}
+
+func TestRetryWithinStopsOnSuccess(t *testing.T) {
+	sleeps := fakeRetryClock(t)
+	calls := 0
+	err := retryWithin(time.Minute, "schema check", func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("retryWithin: %v", err)
+	}
+	if want := []time.Duration{500 * time.Millisecond, time.Second}; !slices.Equal(*sleeps, want) {
+		t.Errorf("sleeps = %v, want %v", *sleeps, want)
+	}
+}
+
+func TestRetryWithinZeroWindowTriesOnce(t *testing.T) {
+	sleeps := fakeRetryClock(t)
+	calls := 0
+	if err := retryWithin(0, "schema check", func() error {
+		calls++
+		return errors.New("down")
+	}); err == nil {
+		t.Fatal("retryWithin succeeded with a failing fn")
+	}
+	if calls != 1 || len(*sleeps) != 0 {
+		t.Errorf("calls = %d, sleeps = %v, want one attempt and no sleep", calls, *sleeps)
+	}
+}
+
+type fakeInspector struct {
+	state mq.QueueState
+	err   error
+}
+
+func (f fakeInspector) InspectQueue(string) (mq.QueueState, error) { return f.state, f.err }
+
+type fakeBindings struct {
+	missing []string
+	err     error
+	calls   int
+}
+
+func (f *fakeBindings) MissingBindings(context.Context, string, string, []string) ([]string, error) {
+	f.calls++
+	return f.missing, f.err
+}
+
+func TestVerifyRunQueue(t *testing.T) {
+	consumed := mq.QueueState{Name: "sim_runner.run_started", Consumers: 1}
+	for _, tc := range []struct {
+		name      string
+		inspector fakeInspector
+		bindings  *fakeBindings
+		want      string
+		wantCalls int
+	}{
+		{"healthy", fakeInspector{state: consumed}, &fakeBindings{}, "verified queue sim_runner.run_started bindings: run.created, run.started", 1},
+		{"missing binding", fakeInspector{state: consumed}, &fakeBindings{missing: []string{"run.created"}},
+			"WARNING: VERIFY_BINDINGS: queue sim_runner.run_started is not bound to run.created on amr.events", 1},
+		{"management API down", fakeInspector{state: consumed}, &fakeBindings{err: errors.New("connection refused")},
+			"WARNING: VERIFY_BINDINGS: could not check bindings: connection refused", 1},
+		{"missing queue skips the binding check", fakeInspector{err: errors.New("NOT_FOUND")}, &fakeBindings{},
+			"WARNING: VERIFY_BINDINGS: NOT_FOUND", 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+			t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+			verifyRunQueue(tc.inspector, tc.bindings, "sim_runner.run_started", "amr.events")
+			if !strings.Contains(buf.String(), tc.want) {
+				t.Errorf("log = %q, want %q", buf.String(), tc.want)
+			}
+			if tc.bindings.calls != tc.wantCalls {
+				t.Errorf("binding checks = %d, want %d", tc.bindings.calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+// h2cClient speaks HTTP/2 with prior knowledge over plain TCP, as an h2c gateway does.
+func h2cClient() *http.Client {
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestPublicHandlerH2C(t *testing.T) {
+	proto := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	for _, tc := range []struct {
+		name     string
+		features string
+		wantH2C  bool
+	}{
+		{"enabled", "h2c", true},
+		{"disabled", `{"h2c":false}`, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("FEATURES", tc.features)
+			cfg, err := config.Load()
+			if err != nil {
+				t.Fatalf("config.Load: %v", err)
+			}
+			srv := httptest.NewServer(publicHandler(proto, cfg.Features))
+			t.Cleanup(srv.Close)
+
+			// HTTP/1.1 clients are served either way.
+			if got := fetch(t, srv.Client(), srv.URL); got != "HTTP/1.1" {
+				t.Errorf("HTTP/1.1 client saw %q, want HTTP/1.1", got)
+			}
+
+			resp, err := h2cClient().Get(srv.URL)
+			if !tc.wantH2C {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("h2c request succeeded with the feature off")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("h2c request: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+				t.Errorf("h2c response proto %s, handler saw %q; want HTTP/2.0", resp.Proto, body)
+			}
+		})
+	}
+}
+
+func fetch(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return string(body)
+}
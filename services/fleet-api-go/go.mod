@@ -6,6 +6,10 @@ require (
 	github.com/go-sql-driver/mysql v1.8.1
 	github.com/google/uuid v1.6.0
 	github.com/streadway/amqp v1.1.0
+	golang.org/x/net v0.35.0
 )
 
-require filippo.io/edwards25519 v1.1.0 // indirect
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
+)
@@ -5,25 +5,42 @@ package config
 // Purpose: Centralized configuration parsing and derived helpers (DSN, Rabbit URL).
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"net"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// ScenarioHashVersion identifies the scenario-hash algorithm new runs are recorded with.
+// Bump it whenever the simulator's hash computation changes so compare never matches
+// runs hashed by different algorithms.
+const ScenarioHashVersion = 1
+
 // ScaleConfig defines robot/job counts for a named fleet scale.
 type ScaleConfig struct {
 	Robots int
 	Jobs   int
 }
 
-// ScaleMap defines the default scale presets.
-var ScaleMap = map[string]ScaleConfig{
+// builtinScales are the scale presets before FLEET_ROBOTS/FLEET_JOBS and CUSTOM_SCALES.
+// It is never modified.
+var builtinScales = map[string]ScaleConfig{
 	"mini":  {Robots: 5, Jobs: 5},
 	"small": {Robots: 5, Jobs: 25},
 	"demo":  {Robots: 10, Jobs: 50},
 	"large": {Robots: 20, Jobs: 100},
 }
 
+// ScaleMap holds the active scale presets. Load replaces it with a freshly built map
+// rather than mutating it in place; treat it as read-only.
+var ScaleMap = maps.Clone(builtinScales)
+
 // Config stores parsed environment configuration for fleet-api.
 type Config struct {
 	Port             int
@@ -36,43 +53,240 @@ type Config struct {
 	MySQLPassword    string
 	MySQLDB          string
 	RabbitHost       string
+	RabbitHosts      []string
 	RabbitPort       string
 	RabbitUser       string
 	RabbitPass       string
 	ExchangeName     string
+	ServiceName      string
+	Hostname         string
 	GAReplanInterval int
+	APIBasePath      string
+	BulkStatusMax    int
+	PublishAttempts  int
+	PublishBackoff   time.Duration
+	APIBaseExempt    []string
+	MaxBodyBytes     int64
+	LogSamplePaths   []string
+	LogSampleRate    int
+	// DevMode allows development-only operations such as seeding; FEATURES=dev_seed
+	// only mounts the route.
+	DevMode          bool
+	DevSeedCount     int
+	MaxActiveRuns    int
+	HeartbeatEvery   time.Duration
+	MetricThresholds []MetricThreshold
+	ReadOnly         bool
+	AdminPort        int
+	DebugErrors      bool
+	JSONFieldCase    string
+	StrictFleet      bool
+	RunStartedAlias  bool
+	ScaleDefaultMode map[string]string
+	MetricsChunkSize int
+	RunIDScheme      string
+	PublishChannels  int
+	DelayedExchange  string
+	// CreateOnlyScales may be used by POST /runs but not compared; CompareOnlyScales the reverse.
+	CreateOnlyScales  []string
+	CompareOnlyScales []string
+	RequireJSON       bool
+	ErrorMessageMax   int
+	Features          Features
+	EventSampleRates  map[string]int
+	RunWaitMax        time.Duration
+	CompareMinRuns    int
+	// AuditLog is where write audit entries go: off, stdout or db.
+	AuditLog string
+	// EventTTL expires published events after this long in a queue; zero disables it.
+	EventTTL time.Duration
+	// SkipSchemaCheck disables the startup check of the runs and run_metrics columns.
+	SkipSchemaCheck bool
+	// VerifyBindings checks VerifyQueue at startup and warns when nothing consumes it
+	// or, via the management API at RabbitManagementURL, when it is not bound to
+	// run.created and run.started.
+	VerifyBindings      bool
+	VerifyQueue         string
+	RabbitManagementURL string
+	// SchemaCheckRetry keeps retrying a failing schema check (with backoff) for this
+	// long before startup fails; zero fails on the first attempt.
+	SchemaCheckRetry time.Duration
+	// CompareCacheSize and CompareCacheTTL bound the in-memory cache of compare
+	// reads; either being zero disables it. The size defaults to 0: completions
+	// sim-runner writes to MySQL cannot invalidate entries, only expire them.
+	CompareCacheSize int
+	CompareCacheTTL  time.Duration
+	// OmitNulls strips null-valued fields from JSON responses.
+	OmitNulls bool
+	// GzipLevel is the gzip response compression level (1-9); 0 disables compression.
+	GzipLevel int
 }
 
 // Load parses environment variables and returns a validated Config.
+// With LOAD_DOTENV=true, variables from DOTENV_PATH (default .env) fill in any not already set.
 func Load() (*Config, error) {
-	port, err := atoiWithDefault(os.Getenv("FLEET_API_PORT"), 8000)
+	loadEnvFile, err := boolWithDefault(env("LOAD_DOTENV"), false)
+	if err != nil {
+		return nil, err
+	}
+	if loadEnvFile {
+		if err := loadDotenv(getenv("DOTENV_PATH", ".env")); err != nil {
+			return nil, fmt.Errorf("load dotenv: %w", err)
+		}
+	}
+
+	port, err := atoiWithDefault(env("FLEET_API_PORT"), 8000)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := atoiWithDefault(env("FLEET_SEED"), 42)
+	if err != nil {
+		return nil, err
+	}
+	replan, err := atoiWithDefault(env("GA_REPLAN_INTERVAL_S"), 0)
+	if err != nil {
+		return nil, err
+	}
+	bulkStatusMax, err := atoiWithDefault(env("BULK_STATUS_MAX_ITEMS"), 100)
+	if err != nil {
+		return nil, err
+	}
+	errorMessageMax, err := atoiWithDefault(env("ERROR_MESSAGE_MAX_LEN"), 2000)
+	if err != nil {
+		return nil, err
+	}
+	if errorMessageMax < 0 {
+		return nil, fmt.Errorf("invalid ERROR_MESSAGE_MAX_LEN: %d (must be >= 0)", errorMessageMax)
+	}
+	metricsChunkSize, err := atoiWithDefault(env("METRICS_QUERY_CHUNK_SIZE"), 100)
+	if err != nil {
+		return nil, err
+	}
+	if metricsChunkSize < 1 {
+		return nil, fmt.Errorf("invalid METRICS_QUERY_CHUNK_SIZE: %d (must be >= 1)", metricsChunkSize)
+	}
+	publishAttempts, err := atoiWithDefault(env("PUBLISH_RETRY_ATTEMPTS"), 3)
+	if err != nil {
+		return nil, err
+	}
+	if publishAttempts < 1 {
+		return nil, fmt.Errorf("invalid PUBLISH_RETRY_ATTEMPTS: %d (must be >= 1)", publishAttempts)
+	}
+	publishBackoffMs, err := atoiWithDefault(env("PUBLISH_RETRY_BACKOFF_MS"), 100)
+	if err != nil {
+		return nil, err
+	}
+	logSampleRate, err := atoiWithDefault(env("LOG_SAMPLE_RATE"), 100)
+	if err != nil {
+		return nil, err
+	}
+	devMode, err := boolWithDefault(env("DEV_MODE"), false)
+	if err != nil {
+		return nil, err
+	}
+	devSeedCount, err := atoiWithDefault(env("DEV_SEED_COUNT"), 3)
+	if err != nil {
+		return nil, err
+	}
+	maxActiveRuns, err := atoiWithDefault(env("MAX_ACTIVE_RUNS"), 0)
+	if err != nil {
+		return nil, err
+	}
+	heartbeatEnabled, err := boolWithDefault(env("ENABLE_HEARTBEAT"), false)
+	if err != nil {
+		return nil, err
+	}
+	heartbeatSeconds, err := atoiWithDefault(env("HEARTBEAT_INTERVAL_S"), 30)
+	if err != nil {
+		return nil, err
+	}
+	if heartbeatSeconds <= 0 {
+		return nil, fmt.Errorf("invalid HEARTBEAT_INTERVAL_S: %d (must be > 0)", heartbeatSeconds)
+	}
+	maxBodyBytes, err := atoiWithDefault(env("MAX_BODY_BYTES"), 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	runStartedAlias, err := boolWithDefault(env("PUBLISH_RUN_STARTED_ALIAS"), true)
 	if err != nil {
 		return nil, err
 	}
-	seed, err := atoiWithDefault(os.Getenv("FLEET_SEED"), 42)
+	strictFleet, err := boolWithDefault(env("STRICT_FLEET"), false)
 	if err != nil {
 		return nil, err
 	}
-	replan, err := atoiWithDefault(os.Getenv("GA_REPLAN_INTERVAL_S"), 0)
+	jsonFieldCase := strings.ToLower(getenv("JSON_FIELD_CASE", "snake"))
+	if jsonFieldCase != "snake" && jsonFieldCase != "camel" {
+		return nil, fmt.Errorf("invalid JSON_FIELD_CASE: %s (must be snake or camel)", jsonFieldCase)
+	}
+	debugErrors, err := boolWithDefault(env("DEBUG_ERRORS"), false)
+	if err != nil {
+		return nil, err
+	}
+	adminPort, err := atoiWithDefault(env("ADMIN_PORT"), 0)
+	if err != nil {
+		return nil, err
+	}
+	if adminPort != 0 && adminPort == port {
+		return nil, fmt.Errorf("invalid ADMIN_PORT: %d (must differ from FLEET_API_PORT)", adminPort)
+	}
+	enablePprof, err := boolWithDefault(env("ENABLE_PPROF"), false)
 	if err != nil {
 		return nil, err
 	}
-	overrideRobots, err := atoiWithDefault(os.Getenv("FLEET_ROBOTS"), 0)
+	readOnly, err := boolWithDefault(env("READ_ONLY"), false)
 	if err != nil {
 		return nil, err
 	}
-	overrideJobs, err := atoiWithDefault(os.Getenv("FLEET_JOBS"), 0)
+	requireJSON, err := boolWithDefault(env("REQUIRE_JSON_CONTENT_TYPE"), true)
+	if err != nil {
+		return nil, err
+	}
+	enableH2C, err := boolWithDefault(env("ENABLE_H2C"), false)
+	if err != nil {
+		return nil, err
+	}
+	features, err := parseFeatures(env("FEATURES"), map[string]bool{
+		// Admin routes are unauthenticated, so they default on only where they are
+		// kept off the public port (ADMIN_PORT) or in development (DEV_MODE).
+		FeatureAdmin:     adminPort > 0 || devMode,
+		FeatureDevSeed:   devMode,
+		FeaturePprof:     enablePprof,
+		FeatureHeartbeat: heartbeatEnabled,
+		FeatureH2C:       enableH2C,
+	})
+	if err != nil {
+		return nil, err
+	}
+	metricThresholds, err := ParseThresholds(env("METRIC_THRESHOLDS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid METRIC_THRESHOLDS: %w", err)
+	}
+	overrideRobots, err := atoiWithDefault(env("FLEET_ROBOTS"), 0)
+	if err != nil {
+		return nil, err
+	}
+	overrideJobs, err := atoiWithDefault(env("FLEET_JOBS"), 0)
 	if err != nil {
 		return nil, err
 	}
 
-	if overrideRobots > 0 && overrideJobs > 0 {
-		for key := range ScaleMap {
-			ScaleMap[key] = ScaleConfig{Robots: overrideRobots, Jobs: overrideJobs}
-		}
+	allowScaleOverride, err := boolWithDefault(env("ALLOW_SCALE_OVERRIDE"), false)
+	if err != nil {
+		return nil, err
 	}
+	customScales, err := parseCustomScales(env("CUSTOM_SCALES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CUSTOM_SCALES: %w", err)
+	}
+	scales, err := buildScaleMap(overrideRobots, overrideJobs, customScales, allowScaleOverride)
+	if err != nil {
+		return nil, err
+	}
+	// The scale validations below read ScaleMap, so publish the new map before them.
+	ScaleMap = scales
 
-	scale := getenv("FLEET_SCALE", "demo")
+	scale := strings.ToLower(getenv("FLEET_SCALE", "demo"))
 	if _, ok := ScaleMap[scale]; !ok {
 		return nil, fmt.Errorf("invalid FLEET_SCALE: %s", scale)
 	}
@@ -82,6 +296,139 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid FLEET_MODE: %s", mode)
 	}
 
+	runIDScheme := strings.ToLower(getenv("RUN_ID_SCHEME", "uuid"))
+	if runIDScheme != "uuid" && runIDScheme != "ulid" {
+		return nil, fmt.Errorf("invalid RUN_ID_SCHEME: %s (must be uuid or ulid)", runIDScheme)
+	}
+
+	scaleDefaultMode, err := parseScaleDefaultModes(env("SCALE_DEFAULT_MODE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCALE_DEFAULT_MODE: %w", err)
+	}
+
+	createOnlyScales, err := parseScaleList(env("SCALES_CREATE_ONLY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCALES_CREATE_ONLY: %w", err)
+	}
+	compareOnlyScales, err := parseScaleList(env("SCALES_COMPARE_ONLY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCALES_COMPARE_ONLY: %w", err)
+	}
+	for _, scale := range createOnlyScales {
+		if slices.Contains(compareOnlyScales, scale) {
+			return nil, fmt.Errorf("invalid scale restrictions: %s is in both SCALES_CREATE_ONLY and SCALES_COMPARE_ONLY", scale)
+		}
+	}
+
+	eventSampleRates, err := parseEventSampleRates(env("EVENT_SAMPLE_RATES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVENT_SAMPLE_RATES: %w", err)
+	}
+	publishChannels, err := atoiWithDefault(env("RABBITMQ_PUBLISH_CHANNELS"), 1)
+	if err != nil {
+		return nil, err
+	}
+	if publishChannels < 1 {
+		return nil, fmt.Errorf("invalid RABBITMQ_PUBLISH_CHANNELS: %d (must be >= 1)", publishChannels)
+	}
+
+	runWaitMaxSeconds, err := atoiWithDefault(env("RUN_WAIT_MAX_S"), 300)
+	if err != nil {
+		return nil, err
+	}
+	if runWaitMaxSeconds <= 0 {
+		return nil, fmt.Errorf("invalid RUN_WAIT_MAX_S: %d (must be > 0)", runWaitMaxSeconds)
+	}
+
+	compareMinRuns, err := atoiWithDefault(env("COMPARE_MIN_RUNS"), 1)
+	if err != nil {
+		return nil, err
+	}
+	if compareMinRuns < 1 {
+		return nil, fmt.Errorf("invalid COMPARE_MIN_RUNS: %d (must be >= 1)", compareMinRuns)
+	}
+
+	auditLog := strings.ToLower(getenv("AUDIT_LOG", "stdout"))
+	if auditLog != "off" && auditLog != "stdout" && auditLog != "db" {
+		return nil, fmt.Errorf("invalid AUDIT_LOG: %s (must be off, stdout or db)", auditLog)
+	}
+
+	eventTTLMillis, err := atoiWithDefault(env("EVENT_TTL_MS"), 0)
+	if err != nil {
+		return nil, err
+	}
+	if eventTTLMillis < 0 {
+		return nil, fmt.Errorf("invalid EVENT_TTL_MS: %d (must be >= 0)", eventTTLMillis)
+	}
+
+	skipSchemaCheck, err := boolWithDefault(env("SKIP_SCHEMA_CHECK"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	verifyBindings, err := boolWithDefault(env("VERIFY_BINDINGS"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaCheckRetrySeconds, err := atoiWithDefault(env("SCHEMA_CHECK_RETRY_S"), 0)
+	if err != nil {
+		return nil, err
+	}
+	if schemaCheckRetrySeconds < 0 {
+		return nil, fmt.Errorf("invalid SCHEMA_CHECK_RETRY_S: %d (must be >= 0)", schemaCheckRetrySeconds)
+	}
+
+	compareCacheSize, err := atoiWithDefault(env("COMPARE_CACHE_SIZE"), 0)
+	if err != nil {
+		return nil, err
+	}
+	if compareCacheSize < 0 {
+		return nil, fmt.Errorf("invalid COMPARE_CACHE_SIZE: %d (must be >= 0)", compareCacheSize)
+	}
+	compareCacheTTLMillis, err := atoiWithDefault(env("COMPARE_CACHE_TTL_MS"), 5000)
+	if err != nil {
+		return nil, err
+	}
+	if compareCacheTTLMillis < 0 {
+		return nil, fmt.Errorf("invalid COMPARE_CACHE_TTL_MS: %d (must be >= 0)", compareCacheTTLMillis)
+	}
+
+	omitNulls, err := boolWithDefault(env("OMIT_NULLS"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipLevel, err := atoiWithDefault(env("GZIP_LEVEL"), 6)
+	if err != nil {
+		return nil, err
+	}
+	if gzipLevel < 0 || gzipLevel > 9 {
+		return nil, fmt.Errorf("invalid GZIP_LEVEL: %d (must be 1-9, or 0 to disable compression)", gzipLevel)
+	}
+
+	rabbitHost := getenv("RABBITMQ_HOST", "rabbitmq")
+	rawHosts := env("RABBITMQ_HOSTS")
+	rabbitHosts := parseList(rawHosts)
+	if rawHosts != "" && len(rabbitHosts) == 0 {
+		return nil, fmt.Errorf("invalid RABBITMQ_HOSTS: no hosts in %q", rawHosts)
+	}
+	if len(rabbitHosts) == 0 {
+		rabbitHosts = []string{rabbitHost}
+	}
+
+	hostname := env("HOSTNAME")
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	basePathExempt := parseList(env("API_BASE_PATH_EXEMPT"))
+	for _, path := range basePathExempt {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid API_BASE_PATH_EXEMPT entry %q: must start with /", path)
+		}
+	}
+
 	cfg := &Config{
 		Port:             port,
 		DefaultScale:     scale,
@@ -92,33 +439,267 @@ func Load() (*Config, error) {
 		MySQLUser:        getenv("MYSQL_USER", "amr"),
 		MySQLPassword:    getenv("MYSQL_PASSWORD", "amrpass"),
 		MySQLDB:          getenv("MYSQL_DB", "amr_fleet"),
-		RabbitHost:       getenv("RABBITMQ_HOST", "rabbitmq"),
+		RabbitHost:       rabbitHost,
+		RabbitHosts:      rabbitHosts,
 		RabbitPort:       getenv("RABBITMQ_PORT", "5672"),
 		RabbitUser:       getenv("RABBITMQ_USER", "amr"),
 		RabbitPass:       getenv("RABBITMQ_PASS", "amrpass"),
 		ExchangeName:     "amr.events",
+		ServiceName:      getenv("SERVICE_NAME", "fleet-api"),
+		Hostname:         hostname,
 		GAReplanInterval: replan,
+		BulkStatusMax:    bulkStatusMax,
+		PublishAttempts:  publishAttempts,
+		PublishBackoff:   time.Duration(publishBackoffMs) * time.Millisecond,
+		APIBasePath:      strings.TrimRight(env("API_BASE_PATH"), "/"),
+		APIBaseExempt:    basePathExempt,
+		MaxBodyBytes:     int64(maxBodyBytes),
+		LogSamplePaths:   parseList(getenv("LOG_SAMPLE_PATHS", "/health")),
+		LogSampleRate:    logSampleRate,
+		DevMode:          devMode,
+		DevSeedCount:     devSeedCount,
+		MaxActiveRuns:    maxActiveRuns,
+		HeartbeatEvery:   time.Duration(heartbeatSeconds) * time.Second,
+		MetricThresholds: metricThresholds,
+		ReadOnly:         readOnly,
+		AdminPort:        adminPort,
+		DebugErrors:      debugErrors,
+		JSONFieldCase:    jsonFieldCase,
+		StrictFleet:      strictFleet,
+		RunStartedAlias:  runStartedAlias,
+		ScaleDefaultMode: scaleDefaultMode,
+		MetricsChunkSize: metricsChunkSize,
+		RunIDScheme:      runIDScheme,
+		PublishChannels:  publishChannels,
+		DelayedExchange:  env("RABBITMQ_DELAYED_EXCHANGE"),
+
+		CreateOnlyScales:    createOnlyScales,
+		CompareOnlyScales:   compareOnlyScales,
+		RequireJSON:         requireJSON,
+		ErrorMessageMax:     errorMessageMax,
+		Features:            features,
+		EventSampleRates:    eventSampleRates,
+		RunWaitMax:          time.Duration(runWaitMaxSeconds) * time.Second,
+		CompareMinRuns:      compareMinRuns,
+		AuditLog:            auditLog,
+		EventTTL:            time.Duration(eventTTLMillis) * time.Millisecond,
+		SkipSchemaCheck:     skipSchemaCheck,
+		VerifyBindings:      verifyBindings,
+		VerifyQueue:         getenv("VERIFY_BINDINGS_QUEUE", "sim_runner.run_started"),
+		RabbitManagementURL: strings.TrimRight(getenv("RABBITMQ_MANAGEMENT_URL", "http://"+net.JoinHostPort(rabbitHost, "15672")), "/"),
+		SchemaCheckRetry:    time.Duration(schemaCheckRetrySeconds) * time.Second,
+		CompareCacheSize:    compareCacheSize,
+		CompareCacheTTL:     time.Duration(compareCacheTTLMillis) * time.Millisecond,
+		OmitNulls:           omitNulls,
+		GzipLevel:           gzipLevel,
 	}
 	return cfg, nil
 }
 
+// ModeForScale returns the mode a run at scale gets when it omits mode:
+// the SCALE_DEFAULT_MODE entry if present, otherwise DefaultMode.
+func (c *Config) ModeForScale(scale string) string {
+	if mode, ok := c.ScaleDefaultMode[scale]; ok {
+		return mode
+	}
+	return c.DefaultMode
+}
+
+// parseScaleDefaultModes parses a JSON object of scale -> mode, e.g.
+// {"large":"ga","mini":"baseline"}. Scale keys are case-insensitive.
+func parseScaleDefaultModes(raw string) (map[string]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var entries map[string]string
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("must be a JSON object of scale to mode: %w", err)
+	}
+	modes := make(map[string]string, len(entries))
+	for scale, mode := range entries {
+		key := strings.ToLower(strings.TrimSpace(scale))
+		if _, ok := ScaleMap[key]; !ok {
+			return nil, fmt.Errorf("unknown scale %q", scale)
+		}
+		if mode != "baseline" && mode != "ga" {
+			return nil, fmt.Errorf("scale %q: mode must be baseline or ga, got %q", scale, mode)
+		}
+		modes[key] = mode
+	}
+	return modes, nil
+}
+
+// lifecycleRoutingKeys always publish; sampling them would lose run state transitions.
+var lifecycleRoutingKeys = map[string]bool{
+	"run.created":   true,
+	"run.started":   true,
+	"run.completed": true,
+	"run.cancelled": true,
+}
+
+// parseEventSampleRates parses "run.progress=10,robot.telemetry=5" into routing key
+// to 1-in-N rate. Rates must be >= 1; lifecycle keys cannot be sampled.
+func parseEventSampleRates(raw string) (map[string]int, error) {
+	rates := map[string]int{}
+	for _, item := range parseList(raw) {
+		key, rawRate, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q: expected routing_key=N", item)
+		}
+		if lifecycleRoutingKeys[key] {
+			return nil, fmt.Errorf("%s is a lifecycle event and is always published", key)
+		}
+		rate, err := strconv.Atoi(strings.TrimSpace(rawRate))
+		if err != nil || rate < 1 {
+			return nil, fmt.Errorf("%s: rate must be an integer >= 1", key)
+		}
+		rates[key] = rate
+	}
+	return rates, nil
+}
+
 // DSN returns a MySQL DSN string based on the config.
 func (c *Config) DSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&multiStatements=true", c.MySQLUser, c.MySQLPassword, c.MySQLHost, c.MySQLPort, c.MySQLDB)
 }
 
-// RabbitURL returns the AMQP URL used by the publisher.
+// RabbitURL returns the AMQP URL for the primary RabbitMQ host.
 func (c *Config) RabbitURL() string {
-	return fmt.Sprintf("amqp://%s:%s@%s:%s/", c.RabbitUser, c.RabbitPass, c.RabbitHost, c.RabbitPort)
+	return c.RabbitURLs()[0]
+}
+
+// RabbitURLs returns one AMQP URL per configured RabbitMQ host, in failover order.
+// Hosts without an explicit port use RABBITMQ_PORT.
+func (c *Config) RabbitURLs() []string {
+	hosts := c.RabbitHosts
+	if len(hosts) == 0 {
+		hosts = []string{c.RabbitHost}
+	}
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		addr := host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			addr = net.JoinHostPort(host, c.RabbitPort)
+		}
+		urls = append(urls, fmt.Sprintf("amqp://%s:%s@%s/", c.RabbitUser, c.RabbitPass, addr))
+	}
+	return urls
+}
+
+// RabbitConnectionName returns the client-provided AMQP connection name (service@host).
+func (c *Config) RabbitConnectionName() string {
+	if c.Hostname == "" {
+		return c.ServiceName
+	}
+	return c.ServiceName + "@" + c.Hostname
 }
 
 func getenv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
+	if v := env(key); v != "" {
 		return v
 	}
 	return fallback
 }
 
+// env reads a config variable. With CONFIG_PREFIX set (e.g. FLEETA_), a non-empty
+// FLEETA_<key> wins over <key>, so several instances can share one environment.
+func env(key string) string {
+	if prefix := os.Getenv("CONFIG_PREFIX"); prefix != "" {
+		if v := os.Getenv(prefix + key); v != "" {
+			return v
+		}
+	}
+	return os.Getenv(key)
+}
+
+// parseList splits a comma-separated list, trimming blanks.
+func parseList(raw string) []string {
+	var hosts []string
+	for _, part := range strings.Split(raw, ",") {
+		if host := strings.TrimSpace(part); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// buildScaleMap returns a new scale map from the built-in presets: FLEET_ROBOTS/FLEET_JOBS
+// (when both > 0) resize every built-in, then custom scales are added on top so their
+// explicit sizes win. A custom scale named like a built-in is an error unless
+// allowOverride is set. Neither builtinScales nor ScaleMap is modified.
+func buildScaleMap(robots, jobs int, custom map[string]ScaleConfig, allowOverride bool) (map[string]ScaleConfig, error) {
+	scales := make(map[string]ScaleConfig, len(builtinScales)+len(custom))
+	for name, preset := range builtinScales {
+		if robots > 0 && jobs > 0 {
+			preset = ScaleConfig{Robots: robots, Jobs: jobs}
+		}
+		scales[name] = preset
+	}
+	for _, name := range sortedKeys(custom) {
+		if _, builtin := builtinScales[name]; builtin && !allowOverride {
+			return nil, fmt.Errorf("invalid CUSTOM_SCALES: %q shadows a built-in scale (set ALLOW_SCALE_OVERRIDE=true to redefine it)", name)
+		}
+		scales[name] = custom[name]
+	}
+	return scales, nil
+}
+
+// maxScaleNameLength matches the runs.scale column width.
+const maxScaleNameLength = 32
+
+// parseCustomScales parses a JSON object of scale -> size, e.g.
+// {"xl":{"robots":40,"jobs":200}}. Names are case-insensitive; sizes must be positive.
+func parseCustomScales(raw string) (map[string]ScaleConfig, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var entries map[string]struct {
+		Robots int `json:"robots"`
+		Jobs   int `json:"jobs"`
+	}
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, fmt.Errorf("must be a JSON object of scale to {robots, jobs}: %w", err)
+	}
+	scales := make(map[string]ScaleConfig, len(entries))
+	for name, size := range entries {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return nil, fmt.Errorf("empty scale name")
+		}
+		if len(key) > maxScaleNameLength {
+			return nil, fmt.Errorf("scale %q: name longer than %d characters", name, maxScaleNameLength)
+		}
+		if size.Robots <= 0 || size.Jobs <= 0 {
+			return nil, fmt.Errorf("scale %q: robots and jobs must be > 0", name)
+		}
+		scales[key] = ScaleConfig{Robots: size.Robots, Jobs: size.Jobs}
+	}
+	return scales, nil
+}
+
+// sortedKeys returns m's keys in order, so map-driven validation errors are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseScaleList parses a comma-separated list of scale names (case-insensitive),
+// rejecting names not in ScaleMap.
+func parseScaleList(raw string) ([]string, error) {
+	scales := parseList(strings.ToLower(raw))
+	for _, scale := range scales {
+		if _, ok := ScaleMap[scale]; !ok {
+			return nil, fmt.Errorf("unknown scale %q", scale)
+		}
+	}
+	return scales, nil
+}
+
 func atoiWithDefault(raw string, fallback int) (int, error) {
 	if raw == "" {
 		return fallback, nil
@@ -129,3 +710,14 @@ func atoiWithDefault(raw string, fallback int) (int, error) {
 	}
 	return v, nil
 }
+
+func boolWithDefault(raw string, fallback bool) (bool, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid bool %q: %w", raw, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,484 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadGzipLevel(t *testing.T) {
+	cases := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"", 6, false},
+		{"1", 1, false},
+		{"9", 9, false},
+		{"0", 0, false},
+		{"10", 0, true},
+		{"-1", 0, true},
+		{"fast", 0, true},
+	}
+	for _, tc := range cases {
+		t.Run("GZIP_LEVEL="+tc.raw, func(t *testing.T) {
+			t.Setenv("GZIP_LEVEL", tc.raw)
+			cfg, err := Load()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Load() = nil error, want one for GZIP_LEVEL=%q", tc.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.GzipLevel != tc.want {
+				t.Fatalf("GzipLevel = %d, want %d", cfg.GzipLevel, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadGzipLevelErrorNamesVariable(t *testing.T) {
+	t.Setenv("GZIP_LEVEL", "12")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "GZIP_LEVEL") {
+		t.Fatalf("Load() error = %v, want one naming GZIP_LEVEL", err)
+	}
+}
+
+func TestLoadCompareCacheIsOffByDefault(t *testing.T) {
+	t.Setenv("COMPARE_CACHE_SIZE", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CompareCacheSize != 0 {
+		t.Fatalf("CompareCacheSize = %d, want 0", cfg.CompareCacheSize)
+	}
+
+	t.Setenv("COMPARE_CACHE_SIZE", "64")
+	if cfg, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CompareCacheSize != 64 {
+		t.Fatalf("CompareCacheSize = %d, want 64", cfg.CompareCacheSize)
+	}
+}
+
+func TestRabbitConnectionName(t *testing.T) {
+	for _, tc := range []struct {
+		service, host, want string
+	}{
+		{"fleet-api", "pod-7", "fleet-api@pod-7"},
+		{"fleet-api", "", "fleet-api"},
+	} {
+		cfg := &Config{ServiceName: tc.service, Hostname: tc.host}
+		if got := cfg.RabbitConnectionName(); got != tc.want {
+			t.Errorf("RabbitConnectionName(%q, %q) = %q, want %q", tc.service, tc.host, got, tc.want)
+		}
+	}
+
+	t.Setenv("SERVICE_NAME", "fleet-api-canary")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cfg.RabbitConnectionName(), "fleet-api-canary") {
+		t.Errorf("RabbitConnectionName = %q, want SERVICE_NAME first", cfg.RabbitConnectionName())
+	}
+}
+
+func TestLoadJSONFieldCase(t *testing.T) {
+	for raw, want := range map[string]string{"": "snake", "snake": "snake", "Camel": "camel"} {
+		t.Setenv("JSON_FIELD_CASE", raw)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("JSON_FIELD_CASE=%q: %v", raw, err)
+		}
+		if cfg.JSONFieldCase != want {
+			t.Errorf("JSON_FIELD_CASE=%q: JSONFieldCase = %q, want %q", raw, cfg.JSONFieldCase, want)
+		}
+	}
+	t.Setenv("JSON_FIELD_CASE", "kebab")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "JSON_FIELD_CASE") {
+		t.Errorf("Load() error = %v, want one naming JSON_FIELD_CASE", err)
+	}
+}
+
+func TestParseEventSampleRates(t *testing.T) {
+	rates, err := parseEventSampleRates(" run.progress = 10, robot.telemetry=5 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 2 || rates["run.progress"] != 10 || rates["robot.telemetry"] != 5 {
+		t.Errorf("rates = %v", rates)
+	}
+	for _, raw := range []string{"run.progress", "=3", "run.progress=0", "run.progress=x", "run.completed=2"} {
+		if _, err := parseEventSampleRates(raw); err == nil {
+			t.Errorf("parseEventSampleRates(%q) accepted an invalid rate", raw)
+		}
+	}
+}
+
+func TestLoadCustomScaleCollision(t *testing.T) {
+	t.Run("rejected by default", func(t *testing.T) {
+		t.Setenv("CUSTOM_SCALES", `{"Demo":{"robots":12,"jobs":60}}`)
+		_, err := Load()
+		if err == nil || !strings.Contains(err.Error(), `"demo" shadows a built-in scale`) {
+			t.Fatalf("Load() error = %v, want a shadowing error", err)
+		}
+	})
+	t.Run("allowed with ALLOW_SCALE_OVERRIDE", func(t *testing.T) {
+		t.Setenv("CUSTOM_SCALES", `{"Demo":{"robots":12,"jobs":60},"xl":{"robots":40,"jobs":200}}`)
+		t.Setenv("ALLOW_SCALE_OVERRIDE", "true")
+		if _, err := Load(); err != nil {
+			t.Fatal(err)
+		}
+		if got := ScaleMap["demo"]; got != (ScaleConfig{Robots: 12, Jobs: 60}) {
+			t.Errorf("demo = %+v, want the custom size", got)
+		}
+		if got := ScaleMap["xl"]; got != (ScaleConfig{Robots: 40, Jobs: 200}) {
+			t.Errorf("xl = %+v, want the custom size", got)
+		}
+		if builtinScales["demo"] != (ScaleConfig{Robots: 10, Jobs: 50}) {
+			t.Error("override modified the built-in presets")
+		}
+	})
+	t.Run("new names need no override", func(t *testing.T) {
+		t.Setenv("CUSTOM_SCALES", `{"xl":{"robots":40,"jobs":200}}`)
+		if _, err := Load(); err != nil {
+			t.Fatal(err)
+		}
+		if ScaleMap["demo"] != builtinScales["demo"] {
+			t.Errorf("demo = %+v, want the built-in preset", ScaleMap["demo"])
+		}
+	})
+}
+
+func TestLoadFleetOverrideBuildsFreshMap(t *testing.T) {
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	before := ScaleMap
+	snapshot := maps.Clone(before)
+
+	t.Setenv("FLEET_ROBOTS", "7")
+	t.Setenv("FLEET_JOBS", "21")
+	if _, err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	for name := range builtinScales {
+		if got := ScaleMap[name]; got != (ScaleConfig{Robots: 7, Jobs: 21}) {
+			t.Errorf("%s = %+v, want the FLEET_ROBOTS/FLEET_JOBS override", name, got)
+		}
+	}
+	// A reader still holding the previous map must not see the override.
+	if !maps.Equal(before, snapshot) {
+		t.Errorf("previous ScaleMap was mutated: %v, want %v", before, snapshot)
+	}
+	if builtinScales["demo"] != (ScaleConfig{Robots: 10, Jobs: 50}) {
+		t.Error("override modified the built-in presets")
+	}
+}
+
+func TestBuildScaleMap(t *testing.T) {
+	custom := map[string]ScaleConfig{"xl": {Robots: 40, Jobs: 200}}
+	customBefore := maps.Clone(custom)
+
+	got, err := buildScaleMap(7, 21, custom, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]ScaleConfig{
+		"mini": {Robots: 7, Jobs: 21}, "small": {Robots: 7, Jobs: 21},
+		"demo": {Robots: 7, Jobs: 21}, "large": {Robots: 7, Jobs: 21},
+		// Custom scales keep their own size; the fleet override applies to presets only.
+		"xl": {Robots: 40, Jobs: 200},
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("buildScaleMap = %v, want %v", got, want)
+	}
+	if !maps.Equal(custom, customBefore) {
+		t.Errorf("custom scales were mutated: %v", custom)
+	}
+
+	// Only one of robots/jobs set leaves the presets alone.
+	got, err = buildScaleMap(7, 0, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(got, builtinScales) {
+		t.Errorf("buildScaleMap(7, 0) = %v, want the built-in presets", got)
+	}
+	got["demo"] = ScaleConfig{Robots: 1, Jobs: 1}
+	if builtinScales["demo"] != (ScaleConfig{Robots: 10, Jobs: 50}) {
+		t.Error("the returned map aliases the built-in presets")
+	}
+}
+
+func TestLoadEventTTL(t *testing.T) {
+	for raw, want := range map[string]time.Duration{"": 0, "0": 0, "1500": 1500 * time.Millisecond} {
+		t.Setenv("EVENT_TTL_MS", raw)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.EventTTL != want {
+			t.Errorf("EVENT_TTL_MS=%q: EventTTL = %s, want %s", raw, cfg.EventTTL, want)
+		}
+	}
+	t.Setenv("EVENT_TTL_MS", "-1")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "EVENT_TTL_MS") {
+		t.Errorf("EVENT_TTL_MS=-1: err = %v, want an invalid EVENT_TTL_MS error", err)
+	}
+}
+
+func TestLoadSkipSchemaCheck(t *testing.T) {
+	for raw, want := range map[string]bool{"": false, "false": false, "true": true} {
+		t.Setenv("SKIP_SCHEMA_CHECK", raw)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.SkipSchemaCheck != want {
+			t.Errorf("SKIP_SCHEMA_CHECK=%q: SkipSchemaCheck = %v, want %v", raw, cfg.SkipSchemaCheck, want)
+		}
+	}
+}
+
+func TestLoadVerifyBindings(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.VerifyBindings || cfg.VerifyQueue != "sim_runner.run_started" {
+		t.Errorf("defaults = %v/%q, want off and sim_runner.run_started", cfg.VerifyBindings, cfg.VerifyQueue)
+	}
+	t.Setenv("VERIFY_BINDINGS", "true")
+	t.Setenv("VERIFY_BINDINGS_QUEUE", "custom.runs")
+	if cfg, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.VerifyBindings || cfg.VerifyQueue != "custom.runs" {
+		t.Errorf("VerifyBindings = %v, VerifyQueue = %q", cfg.VerifyBindings, cfg.VerifyQueue)
+	}
+}
+
+func TestLoadScaleDefaultMode(t *testing.T) {
+	t.Setenv("FLEET_MODE", "baseline")
+	t.Setenv("SCALE_DEFAULT_MODE", `{"Large":"ga","mini":"baseline"}`)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for scale, want := range map[string]string{"large": "ga", "mini": "baseline", "demo": "baseline"} {
+		if got := cfg.ModeForScale(scale); got != want {
+			t.Errorf("ModeForScale(%q) = %q, want %q", scale, got, want)
+		}
+	}
+
+	for _, raw := range []string{`["ga"]`, `{"huge":"ga"}`, `{"large":"random"}`} {
+		t.Setenv("SCALE_DEFAULT_MODE", raw)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), "SCALE_DEFAULT_MODE") {
+			t.Errorf("SCALE_DEFAULT_MODE=%s: err = %v, want an invalid SCALE_DEFAULT_MODE error", raw, err)
+		}
+	}
+}
+
+func TestLoadScaleRestrictions(t *testing.T) {
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.CreateOnlyScales) != 0 || len(cfg.CompareOnlyScales) != 0 {
+		t.Errorf("defaults = %v/%v, want every scale allowed everywhere", cfg.CreateOnlyScales, cfg.CompareOnlyScales)
+	}
+
+	t.Setenv("SCALES_CREATE_ONLY", "Mini")
+	t.Setenv("SCALES_COMPARE_ONLY", "large")
+	if cfg, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.CreateOnlyScales, []string{"mini"}) || !slices.Equal(cfg.CompareOnlyScales, []string{"large"}) {
+		t.Errorf("restrictions = %v/%v, want [mini]/[large]", cfg.CreateOnlyScales, cfg.CompareOnlyScales)
+	}
+
+	for _, tc := range []struct{ createOnly, compareOnly, want string }{
+		{"huge", "", "SCALES_CREATE_ONLY"},
+		{"", "huge", "SCALES_COMPARE_ONLY"},
+		{"mini", "mini", "mini is in both"},
+	} {
+		t.Setenv("SCALES_CREATE_ONLY", tc.createOnly)
+		t.Setenv("SCALES_COMPARE_ONLY", tc.compareOnly)
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("create=%q compare=%q: err = %v, want %q", tc.createOnly, tc.compareOnly, err, tc.want)
+		}
+	}
+}
+
+func TestParseFeatures(t *testing.T) {
+	defaults := map[string]bool{FeatureAdmin: true, FeaturePprof: false}
+	for _, tc := range []struct {
+		raw  string
+		want []string
+	}{
+		{"", []string{"admin"}},
+		{"pprof, Heartbeat", []string{"admin", "heartbeat", "pprof"}},
+		{`{"admin":false,"pprof":true}`, []string{"pprof"}},
+		{`{}`, []string{"admin"}},
+	} {
+		f, err := parseFeatures(tc.raw, defaults)
+		if err != nil {
+			t.Fatalf("FEATURES=%q: %v", tc.raw, err)
+		}
+		if got := f.Names(); !slices.Equal(got, tc.want) {
+			t.Errorf("FEATURES=%q: enabled = %v, want %v", tc.raw, got, tc.want)
+		}
+	}
+	for _, raw := range []string{"pprof,telemetry", `{"telemetry":true}`, `{"admin":"yes"}`, `{"admin":`} {
+		if _, err := parseFeatures(raw, defaults); err == nil || !strings.Contains(err.Error(), "invalid FEATURES") {
+			t.Errorf("FEATURES=%q: err = %v, want an invalid FEATURES error", raw, err)
+		}
+	}
+}
+
+func TestLoadAdminFeatureDefault(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"off on the public port by default", nil, false},
+		{"on with ADMIN_PORT", map[string]string{"ADMIN_PORT": "9000"}, true},
+		{"on with DEV_MODE", map[string]string{"DEV_MODE": "true"}, true},
+		{"FEATURES enables it", map[string]string{"FEATURES": "admin"}, true},
+		{"FEATURES disables it with ADMIN_PORT", map[string]string{"ADMIN_PORT": "9000", "FEATURES": `{"admin":false}`}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.Features.Enabled(FeatureAdmin); got != tc.want {
+				t.Errorf("admin enabled = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnvConfigPrefix(t *testing.T) {
+	t.Setenv("MYSQL_HOST", "shared-db")
+	t.Setenv("FLEETA_MYSQL_HOST", "")
+	if got := env("MYSQL_HOST"); got != "shared-db" {
+		t.Errorf("without CONFIG_PREFIX: %q, want shared-db", got)
+	}
+
+	t.Setenv("CONFIG_PREFIX", "FLEETA_")
+	if got := env("MYSQL_HOST"); got != "shared-db" {
+		t.Errorf("prefixed key empty: %q, want the unprefixed fallback", got)
+	}
+	t.Setenv("FLEETA_MYSQL_HOST", "db-a")
+	if got := env("MYSQL_HOST"); got != "db-a" {
+		t.Errorf("prefixed key set: %q, want db-a", got)
+	}
+	if got := getenv("MYSQL_PORT", "3306"); got != "3306" {
+		t.Errorf("neither key set: %q, want the default", got)
+	}
+}
+
+func TestLoadConfigPrefix(t *testing.T) {
+	t.Setenv("CONFIG_PREFIX", "FLEETA_")
+	t.Setenv("MYSQL_HOST", "shared-db")
+	t.Setenv("FLEETA_MYSQL_HOST", "db-a")
+	t.Setenv("FLEET_API_PORT", "8000")
+	t.Setenv("FLEETA_FLEET_API_PORT", "8100")
+	t.Setenv("HOSTNAME", "shared-host")
+	t.Setenv("FLEETA_HOSTNAME", "instance-a")
+	t.Setenv("FEATURES", "")
+	t.Setenv("FLEETA_FEATURES", "pprof")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MySQLHost != "db-a" || cfg.Port != 8100 {
+		t.Errorf("MySQLHost = %q, Port = %d, want the FLEETA_ values", cfg.MySQLHost, cfg.Port)
+	}
+	if cfg.Hostname != "instance-a" || cfg.RabbitConnectionName() != "fleet-api@instance-a" {
+		t.Errorf("Hostname = %q, connection name %q, want FLEETA_HOSTNAME", cfg.Hostname, cfg.RabbitConnectionName())
+	}
+	if !cfg.Features.Enabled(FeaturePprof) {
+		t.Error("FLEETA_FEATURES was not applied")
+	}
+
+	t.Setenv("FLEETA_FLEET_API_PORT", "abc")
+	if _, err := Load(); err == nil {
+		t.Error("invalid prefixed port: Load succeeded")
+	}
+}
+
+func TestLoadRunIDScheme(t *testing.T) {
+	for _, tc := range []struct {
+		raw, want string
+		wantErr   bool
+	}{
+		{"", "uuid", false},
+		{"uuid", "uuid", false},
+		{"ULID", "ulid", false},
+		{"snowflake", "", true},
+	} {
+		t.Run("RUN_ID_SCHEME="+tc.raw, func(t *testing.T) {
+			t.Setenv("RUN_ID_SCHEME", tc.raw)
+			cfg, err := Load()
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "RUN_ID_SCHEME") {
+					t.Fatalf("Load() error = %v, want one naming RUN_ID_SCHEME", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.RunIDScheme != tc.want {
+				t.Errorf("RunIDScheme = %q, want %q", cfg.RunIDScheme, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadErrorMessageMax(t *testing.T) {
+	for _, tc := range []struct {
+		raw     string
+		want    int
+		wantErr string
+	}{
+		{"", 2000, ""},
+		{"500", 500, ""},
+		{"0", 0, ""},
+		{"-1", 0, "invalid ERROR_MESSAGE_MAX_LEN"},
+		{"lots", 0, `invalid int "lots"`},
+	} {
+		t.Run("ERROR_MESSAGE_MAX_LEN="+tc.raw, func(t *testing.T) {
+			t.Setenv("ERROR_MESSAGE_MAX_LEN", tc.raw)
+			cfg, err := Load()
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.ErrorMessageMax != tc.want {
+				t.Errorf("ErrorMessageMax = %d, want %d", cfg.ErrorMessageMax, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+// File: internal/config/features.go
+// Purpose: Feature flags for optional route groups and background tasks (FEATURES).
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Feature names accepted by FEATURES.
+const (
+	// FeatureAdmin mounts the /admin/* routes.
+	FeatureAdmin = "admin"
+	// FeatureDevSeed mounts POST /admin/seed (development only).
+	FeatureDevSeed = "dev_seed"
+	// FeaturePprof mounts net/http/pprof under /debug/pprof/.
+	FeaturePprof = "pprof"
+	// FeatureHeartbeat publishes the periodic fleet.heartbeat event.
+	FeatureHeartbeat = "heartbeat"
+	// FeatureH2C accepts cleartext HTTP/2 on the public port.
+	FeatureH2C = "h2c"
+)
+
+// knownFeatures lists every flag FEATURES may name.
+var knownFeatures = map[string]bool{
+	FeatureAdmin:     true,
+	FeatureDevSeed:   true,
+	FeaturePprof:     true,
+	FeatureHeartbeat: true,
+	FeatureH2C:       true,
+}
+
+// Features is the resolved set of feature flags.
+type Features struct {
+	enabled map[string]bool
+}
+
+// Enabled reports whether the named feature is on. Unknown names are off.
+func (f Features) Enabled(name string) bool {
+	return f.enabled[name]
+}
+
+// Names returns the enabled features in sorted order.
+func (f Features) Names() []string {
+	var names []string
+	for name, on := range f.enabled {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseFeatures applies FEATURES on top of defaults (which come from the legacy
+// ENABLE_* / DEV_MODE variables). The value is either a comma-separated list of
+// features to enable, or a JSON object of name to bool, which can also disable
+// a feature that is on by default, e.g. {"admin":false,"pprof":true}.
+func parseFeatures(raw string, defaults map[string]bool) (Features, error) {
+	enabled := make(map[string]bool, len(knownFeatures))
+	for name, on := range defaults {
+		enabled[name] = on
+	}
+	overrides := map[string]bool{}
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "{") {
+		if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+			return Features{}, fmt.Errorf("invalid FEATURES: %w", err)
+		}
+	} else {
+		for _, name := range parseList(raw) {
+			overrides[strings.ToLower(name)] = true
+		}
+	}
+	for name, on := range overrides {
+		if !knownFeatures[name] {
+			return Features{}, fmt.Errorf("invalid FEATURES: unknown feature %q", name)
+		}
+		enabled[name] = on
+	}
+	return Features{enabled: enabled}, nil
+}
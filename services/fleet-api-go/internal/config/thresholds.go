@@ -0,0 +1,69 @@
+package config
+
+// File: internal/config/thresholds.go
+// Purpose: Parsing of metric pass/fail thresholds (METRIC_THRESHOLDS and per-request specs).
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MetricThreshold is one pass criterion, e.g. on_time_rate >= 0.9.
+type MetricThreshold struct {
+	Metric string
+	Op     string
+	Value  float64
+}
+
+// String renders the threshold in the same syntax ParseThresholds accepts.
+func (t MetricThreshold) String() string {
+	return t.Metric + t.Op + strconv.FormatFloat(t.Value, 'g', -1, 64)
+}
+
+// thresholdMetrics are the run_metrics fields a threshold may reference.
+var thresholdMetrics = map[string]bool{
+	"on_time_rate":        true,
+	"total_distance":      true,
+	"avg_completion_time": true,
+	"max_lateness":        true,
+	"completed_jobs":      true,
+	"failed_jobs":         true,
+	"total_jobs":          true,
+}
+
+// thresholdOps is ordered so two-character operators match before their prefixes.
+var thresholdOps = []string{">=", "<=", ">", "<"}
+
+// ParseThresholds parses a comma-separated list such as
+// "on_time_rate>=0.9,max_lateness<=120". An empty spec yields no thresholds.
+func ParseThresholds(spec string) ([]MetricThreshold, error) {
+	var out []MetricThreshold
+	for _, item := range parseList(spec) {
+		t, err := parseThreshold(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func parseThreshold(item string) (MetricThreshold, error) {
+	for _, op := range thresholdOps {
+		idx := strings.Index(item, op)
+		if idx < 0 {
+			continue
+		}
+		metric := strings.TrimSpace(item[:idx])
+		if !thresholdMetrics[metric] {
+			return MetricThreshold{}, fmt.Errorf("invalid threshold %q: unknown metric %q", item, metric)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(item[idx+len(op):]), 64)
+		if err != nil {
+			return MetricThreshold{}, fmt.Errorf("invalid threshold %q: value must be a number", item)
+		}
+		return MetricThreshold{Metric: metric, Op: op, Value: value}, nil
+	}
+	return MetricThreshold{}, fmt.Errorf("invalid threshold %q: expected metric>=value, metric<=value, metric>value or metric<value", item)
+}
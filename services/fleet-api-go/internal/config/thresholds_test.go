@@ -0,0 +1,44 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseThresholds(t *testing.T) {
+	got, err := ParseThresholds(" on_time_rate >= 0.9, max_lateness<=120,failed_jobs<1,total_jobs>0 ")
+	if err != nil {
+		t.Fatalf("ParseThresholds: %v", err)
+	}
+	want := []MetricThreshold{
+		{"on_time_rate", ">=", 0.9},
+		{"max_lateness", "<=", 120},
+		{"failed_jobs", "<", 1},
+		{"total_jobs", ">", 0},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("thresholds = %+v, want %+v", got, want)
+	}
+	if s := got[0].String(); s != "on_time_rate>=0.9" {
+		t.Errorf("String() = %q, want the spec syntax back", s)
+	}
+
+	if got, err := ParseThresholds(""); err != nil || got != nil {
+		t.Errorf("empty spec = %v, %v; want no thresholds", got, err)
+	}
+}
+
+func TestParseThresholdsErrors(t *testing.T) {
+	for _, tc := range []struct{ spec, want string }{
+		{"speed>=1", `unknown metric "speed"`},
+		{"on_time_rate>=high", "value must be a number"},
+		{"on_time_rate=0.9", "expected metric>=value"},
+		{"on_time_rate>=0.9,bogus", `invalid threshold "bogus"`},
+	} {
+		_, err := ParseThresholds(tc.spec)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseThresholds(%q) error = %v, want %q", tc.spec, err, tc.want)
+		}
+	}
+}
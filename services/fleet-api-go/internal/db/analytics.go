@@ -0,0 +1,191 @@
+package db
+
+// File: internal/db/analytics.go
+// Purpose: Aggregate queries over completed runs (trends, scenario rollups, GA improvements).
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// GetDailyModeTrends returns the average on-time rate per completion day and mode
+// for completed runs, optionally filtered by seed, scale and completion time.
+func (s *Store) GetDailyModeTrends(ctx context.Context, seed *int, scale string, tr models.TimeRange) ([]models.ModeTrendRow, error) {
+	var b strings.Builder
+	b.WriteString(`
+		SELECT DATE(r.completed_at) AS day, r.mode, AVG(rm.on_time_rate), COUNT(*)
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+		WHERE r.status = 'completed' AND r.completed_at IS NOT NULL
+	`)
+	var args []any
+	if seed != nil {
+		b.WriteString(" AND r.seed = ?")
+		args = append(args, *seed)
+	}
+	if scale != "" {
+		b.WriteString(" AND r.scale = ?")
+		args = append(args, scale)
+	}
+	args = appendTimeRange(&b, args, "r.completed_at", tr)
+	b.WriteString(`
+		GROUP BY DATE(r.completed_at), r.mode
+		ORDER BY day ASC, r.mode ASC
+	`)
+
+	rows, err := s.q.QueryContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("select trends: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.ModeTrendRow
+	for rows.Next() {
+		var row models.ModeTrendRow
+		if err := rows.Scan(&row.Day, &row.Mode, &row.AvgOnTimeRate, &row.Runs); err != nil {
+			return nil, fmt.Errorf("scan trends: %w", err)
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trends: %w", err)
+	}
+	return out, nil
+}
+
+// ListScenarios returns distinct (seed, scale, robots, jobs) combinations with
+// per-mode run counts, most recently run first, plus the total number of scenarios.
+// The time range filters on run creation time.
+func (s *Store) ListScenarios(ctx context.Context, tr models.TimeRange, limit, offset int) ([]models.ScenarioSummary, int, error) {
+	var where strings.Builder
+	where.WriteString(" WHERE 1=1")
+	args := appendTimeRange(&where, nil, "created_at", tr)
+
+	var total int
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT 1 FROM runs`+where.String()+` GROUP BY seed, scale, robots_count, jobs_count
+		) scenarios
+	`, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count scenarios: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT seed, scale, robots_count, jobs_count,
+			SUM(mode = 'baseline'), SUM(mode = 'ga'), COUNT(*), MAX(created_at)
+		FROM runs`+where.String()+`
+		GROUP BY seed, scale, robots_count, jobs_count
+		ORDER BY MAX(created_at) DESC, seed ASC, scale ASC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select scenarios: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ScenarioSummary{}
+	for rows.Next() {
+		var sc models.ScenarioSummary
+		if err := rows.Scan(
+			&sc.Seed,
+			&sc.Scale,
+			&sc.Robots,
+			&sc.Jobs,
+			&sc.BaselineRuns,
+			&sc.GARuns,
+			&sc.TotalRuns,
+			&sc.LatestRunAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan scenarios: %w", err)
+		}
+		out = append(out, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate scenarios: %w", err)
+	}
+	return out, total, nil
+}
+
+// scenarioPairsCTE pairs the latest completed baseline and GA run of every scenario
+// recorded with the given scenario-hash version; scenarios missing a mode drop out.
+const scenarioPairsCTE = `
+	WITH latest AS (
+		SELECT r.seed, r.scale, r.robots_count, r.jobs_count, r.mode, rm.run_id, rm.on_time_rate,
+			ROW_NUMBER() OVER (
+				PARTITION BY r.seed, r.scale, r.robots_count, r.jobs_count, r.mode
+				ORDER BY r.completed_at DESC, r.created_at DESC
+			) AS rn
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+		WHERE r.status = 'completed' AND r.scenario_hash_version = ?
+	), pairs AS (
+		SELECT b.seed, b.scale, b.robots_count, b.jobs_count,
+			b.run_id AS baseline_run_id, g.run_id AS ga_run_id,
+			b.on_time_rate AS baseline_on_time_rate, g.on_time_rate AS ga_on_time_rate
+		FROM latest b
+		JOIN latest g ON g.seed = b.seed AND g.scale = b.scale
+			AND g.robots_count <=> b.robots_count AND g.jobs_count <=> b.jobs_count
+			AND g.mode = 'ga' AND g.rn = 1
+		WHERE b.mode = 'baseline' AND b.rn = 1
+	)`
+
+// ListScenarioImprovements returns scenarios ranked by GA on-time-rate improvement
+// over baseline (largest first), plus the total number of paired scenarios.
+func (s *Store) ListScenarioImprovements(ctx context.Context, hashVersion, limit, offset int) ([]models.ScenarioImprovement, int, error) {
+	var total int
+	if err := s.q.QueryRowContext(ctx, scenarioPairsCTE+`
+		SELECT COUNT(*) FROM pairs
+	`, hashVersion).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count scenario improvements: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, scenarioPairsCTE+`
+		SELECT seed, scale, robots_count, jobs_count, baseline_run_id, ga_run_id,
+			baseline_on_time_rate, ga_on_time_rate
+		FROM pairs
+		ORDER BY ga_on_time_rate - baseline_on_time_rate DESC, seed ASC, scale ASC
+		LIMIT ? OFFSET ?
+	`, hashVersion, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select scenario improvements: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ScenarioImprovement{}
+	for rows.Next() {
+		var sc models.ScenarioImprovement
+		if err := rows.Scan(
+			&sc.Seed,
+			&sc.Scale,
+			&sc.Robots,
+			&sc.Jobs,
+			&sc.BaselineRunID,
+			&sc.GARunID,
+			&sc.BaselineOnTimeRate,
+			&sc.GAOnTimeRate,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan scenario improvements: %w", err)
+		}
+		out = append(out, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate scenario improvements: %w", err)
+	}
+	return out, total, nil
+}
+
+// appendTimeRange adds inclusive bounds on column for the set ends of tr.
+func appendTimeRange(b *strings.Builder, args []any, column string, tr models.TimeRange) []any {
+	if tr.From != nil {
+		b.WriteString(" AND " + column + " >= ?")
+		args = append(args, tr.From.UTC())
+	}
+	if tr.To != nil {
+		b.WriteString(" AND " + column + " <= ?")
+		args = append(args, tr.To.UTC())
+	}
+	return args
+}
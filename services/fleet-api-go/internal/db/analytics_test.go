@@ -0,0 +1,162 @@
+package db_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestGetDailyModeTrendsFilters(t *testing.T) {
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	from := day.Add(-24 * time.Hour)
+	seed := 42
+	for _, tc := range []struct {
+		name      string
+		seed      *int
+		scale     string
+		tr        models.TimeRange
+		wantSQL   []string
+		wantArgs  []any
+		wantNoSQL []string
+	}{
+		{"unfiltered", nil, "", models.TimeRange{}, nil, nil, []string{"r.seed = ?", "r.scale = ?", "r.completed_at >= ?"}},
+		{
+			"seed, scale and range", &seed, "demo", models.TimeRange{From: &from, To: &day},
+			[]string{"r.seed = ?", "r.scale = ?", "r.completed_at >= ?", "r.completed_at <= ?"},
+			[]any{int64(42), "demo", from, day}, nil,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, fake := dbtest.Open(t)
+			fake.Return("GROUP BY DATE(r.completed_at)", dbtest.Result{Rows: [][]any{
+				{day, "baseline", 0.8, 3},
+				{day, "ga", 0.9, 2},
+			}})
+
+			rows, err := store.GetDailyModeTrends(context.Background(), tc.seed, tc.scale, tc.tr)
+			if err != nil {
+				t.Fatalf("GetDailyModeTrends: %v", err)
+			}
+			want := []models.ModeTrendRow{{Day: day, Mode: "baseline", AvgOnTimeRate: 0.8, Runs: 3}, {Day: day, Mode: "ga", AvgOnTimeRate: 0.9, Runs: 2}}
+			if !reflect.DeepEqual(rows, want) {
+				t.Errorf("rows = %+v, want %+v", rows, want)
+			}
+			stmts := fake.Matching("GROUP BY DATE(r.completed_at)")
+			if len(stmts) != 1 {
+				t.Fatalf("trend queries = %d, want 1", len(stmts))
+			}
+			for _, frag := range tc.wantSQL {
+				if !strings.Contains(stmts[0].Query, frag) {
+					t.Errorf("query lacks %q", frag)
+				}
+			}
+			for _, frag := range tc.wantNoSQL {
+				if strings.Contains(stmts[0].Query, frag) {
+					t.Errorf("query has %q without the filter", frag)
+				}
+			}
+			if len(stmts[0].Args) != len(tc.wantArgs) || (len(tc.wantArgs) > 0 && !reflect.DeepEqual(stmts[0].Args, tc.wantArgs)) {
+				t.Errorf("args = %v, want %v", stmts[0].Args, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestListScenarios(t *testing.T) {
+	latest := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	from := latest.Add(-time.Hour)
+	store, fake := dbtest.Open(t)
+	fake.Return(") scenarios", dbtest.Result{Rows: [][]any{{7}}})
+	fake.Return("SUM(mode = 'baseline')", dbtest.Result{Rows: [][]any{
+		{42, "demo", nil, nil, 2, 1, 3, latest},
+		{7, "large", 4, 20, 0, 1, 1, from},
+	}})
+
+	scenarios, total, err := store.ListScenarios(context.Background(), models.TimeRange{From: &from}, 2, 4)
+	if err != nil {
+		t.Fatalf("ListScenarios: %v", err)
+	}
+	robots, jobs := 4, 20
+	want := []models.ScenarioSummary{
+		{Seed: 42, Scale: "demo", BaselineRuns: 2, GARuns: 1, TotalRuns: 3, LatestRunAt: latest},
+		{Seed: 7, Scale: "large", Robots: &robots, Jobs: &jobs, GARuns: 1, TotalRuns: 1, LatestRunAt: from},
+	}
+	if total != 7 || !reflect.DeepEqual(scenarios, want) {
+		t.Errorf("scenarios = %+v (total %d), want %+v (total 7)", scenarios, total, want)
+	}
+
+	count, page := fake.Matching(") scenarios"), fake.Matching("SUM(mode = 'baseline')")
+	if len(count) != 1 || len(page) != 1 {
+		t.Fatalf("count queries = %d, page queries = %d, want 1 each", len(count), len(page))
+	}
+	if !strings.Contains(count[0].Query, "created_at >= ?") || !reflect.DeepEqual(count[0].Args, []any{from}) {
+		t.Errorf("count query %q args %v, want the created_at bound", count[0].Query, count[0].Args)
+	}
+	if !reflect.DeepEqual(page[0].Args, []any{from, int64(2), int64(4)}) {
+		t.Errorf("page args = %v, want the bound then limit and offset", page[0].Args)
+	}
+}
+
+func TestListScenariosEmpty(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.Return(") scenarios", dbtest.Result{Rows: [][]any{{0}}})
+
+	scenarios, total, err := store.ListScenarios(context.Background(), models.TimeRange{}, 50, 0)
+	if err != nil {
+		t.Fatalf("ListScenarios: %v", err)
+	}
+	if total != 0 || scenarios == nil || len(scenarios) != 0 {
+		t.Errorf("scenarios = %#v (total %d), want an empty, non-nil page", scenarios, total)
+	}
+	if q := fake.Matching(") scenarios")[0].Query; strings.Contains(q, "created_at >=") {
+		t.Errorf("unbounded count query filters on created_at: %q", q)
+	}
+}
+
+func TestListScenarioImprovements(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.Return("COUNT(*) FROM pairs", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("ORDER BY ga_on_time_rate - baseline_on_time_rate DESC", dbtest.Result{Rows: [][]any{
+		{7, "large", 4, 20, "b-7", "g-7", 0.5, 0.9},
+		{42, "demo", nil, nil, "b-42", "g-42", 0.8, 0.85},
+	}})
+
+	scenarios, total, err := store.ListScenarioImprovements(context.Background(), 2, 2, 1)
+	if err != nil {
+		t.Fatalf("ListScenarioImprovements: %v", err)
+	}
+	robots, jobs := 4, 20
+	want := []models.ScenarioImprovement{
+		{Seed: 7, Scale: "large", Robots: &robots, Jobs: &jobs, BaselineRunID: "b-7", GARunID: "g-7", BaselineOnTimeRate: 0.5, GAOnTimeRate: 0.9},
+		{Seed: 42, Scale: "demo", BaselineRunID: "b-42", GARunID: "g-42", BaselineOnTimeRate: 0.8, GAOnTimeRate: 0.85},
+	}
+	if total != 3 || !reflect.DeepEqual(scenarios, want) {
+		t.Errorf("scenarios = %+v (total %d), want %+v (total 3)", scenarios, total, want)
+	}
+
+	count := fake.Matching("COUNT(*) FROM pairs")
+	page := fake.Matching("ORDER BY ga_on_time_rate - baseline_on_time_rate DESC")
+	if len(count) != 1 || len(page) != 1 {
+		t.Fatalf("count queries = %d, page queries = %d, want 1 each", len(count), len(page))
+	}
+	if !reflect.DeepEqual(count[0].Args, []any{int64(2)}) || !reflect.DeepEqual(page[0].Args, []any{int64(2), int64(2), int64(1)}) {
+		t.Errorf("args = %v / %v, want the hash version, then limit and offset", count[0].Args, page[0].Args)
+	}
+	for _, frag := range []string{
+		// Only the latest completed run per scenario and mode is paired.
+		"ROW_NUMBER() OVER", "r.status = 'completed'", "r.scenario_hash_version = ?",
+		// An inner join drops scenarios that lack either mode.
+		"JOIN latest g ON", "g.mode = 'ga' AND g.rn = 1", "b.mode = 'baseline' AND b.rn = 1",
+	} {
+		for _, q := range []string{count[0].Query, page[0].Query} {
+			if !strings.Contains(q, frag) {
+				t.Errorf("query lacks %q", frag)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package db
+
+// File: internal/db/audit.go
+// Purpose: Persistence for write audit entries (audit_log table).
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// InsertAuditEntry appends one entry to audit_log.
+func (s *Store) InsertAuditEntry(ctx context.Context, e models.AuditEntry) error {
+	if _, err := s.q.ExecContext(ctx, `
+		INSERT INTO audit_log (action, run_id, outcome, error_message, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, e.Action, e.RunID, e.Outcome, e.ErrorMessage, e.CreatedAt); err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
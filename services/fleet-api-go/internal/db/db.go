@@ -9,7 +9,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -17,9 +16,11 @@ import (
 	"fleet-api-go/internal/models"
 )
 
-// Store wraps a sql.DB and exposes run/metrics queries.
+// Store wraps a sql.DB and exposes run/metrics queries. Queries go through q,
+// which is the pool itself or, inside WithTx, the open transaction.
 type Store struct {
 	db *sql.DB
+	q  querier
 }
 
 // New opens a MySQL connection and verifies connectivity.
@@ -36,7 +37,13 @@ func New(dsn string) (*Store, error) {
 	if err := db.PingContext(ctx); err != nil {
 		return nil, fmt.Errorf("db ping: %w", err)
 	}
-	return &Store{db: db}, nil
+	return &Store{db: db, q: db}, nil
+}
+
+// Wrap returns a Store over an already-open pool, such as a test driver's.
+// Production code uses New, which also tunes and pings the pool.
+func Wrap(db *sql.DB) *Store {
+	return &Store{db: db, q: db}
 }
 
 // Close closes the underlying database connection.
@@ -49,13 +56,18 @@ func (s *Store) Health(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
-// CreateRun inserts a new run row.
+// CreateRun inserts a new run row. CreatedAt/StartedAt are written as given so
+// callers know the persisted timestamps without reading the row back.
 func (s *Store) CreateRun(ctx context.Context, run models.Run) error {
 	query := `
-		INSERT INTO runs (id, mode, seed, scale, robots_count, jobs_count, scenario_hash, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO runs (id, mode, seed, scale, robots_count, jobs_count, scenario_hash, scenario_hash_version, status, created_at, started_at, ga_params, retry_of, experiment_id, replan_interval_s)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := s.db.ExecContext(
+	gaParams, err := encodeGAParams(run.GAParams)
+	if err != nil {
+		return err
+	}
+	_, err = s.q.ExecContext(
 		ctx,
 		query,
 		run.ID,
@@ -65,7 +77,14 @@ func (s *Store) CreateRun(ctx context.Context, run models.Run) error {
 		run.RobotsCount,
 		run.JobsCount,
 		run.ScenarioHash,
+		run.ScenarioHashVersion,
 		run.Status,
+		run.CreatedAt,
+		run.StartedAt,
+		gaParams,
+		run.RetryOf,
+		run.ExperimentID,
+		run.ReplanIntervalS,
 	)
 	if err != nil {
 		return fmt.Errorf("insert run: %w", err)
@@ -73,101 +92,203 @@ func (s *Store) CreateRun(ctx context.Context, run models.Run) error {
 	return nil
 }
 
+// CountActiveRuns returns the number of runs that have not reached a terminal status.
+func (s *Store) CountActiveRuns(ctx context.Context) (int, error) {
+	var n int
+	if err := s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM runs WHERE status = 'started'`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count active runs: %w", err)
+	}
+	return n, nil
+}
+
 // GetRun returns run metadata by ID.
 func (s *Store) GetRun(ctx context.Context, runID string) (*models.Run, error) {
-	query := `
-		SELECT id, mode, seed, scale, robots_count, jobs_count, scenario_hash, status, error_message, created_at, started_at, completed_at
-		FROM runs WHERE id = ?
-	`
-	var run models.Run
-	if err := s.db.QueryRowContext(ctx, query, runID).Scan(
-		&run.ID,
-		&run.Mode,
-		&run.Seed,
-		&run.Scale,
-		&run.RobotsCount,
-		&run.JobsCount,
-		&run.ScenarioHash,
-		&run.Status,
-		&run.ErrorMessage,
-		&run.CreatedAt,
-		&run.StartedAt,
-		&run.CompletedAt,
-	); err != nil {
+	run, err := scanRun(s.q.QueryRowContext(ctx, `SELECT `+runColumns+` FROM runs WHERE id = ?`, runID))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select run: %w", err)
 	}
-	return &run, nil
+	return run, nil
+}
+
+// GetRunStatus returns only a run's id, status and error message, for cheap polling.
+// It returns nil when the run does not exist.
+func (s *Store) GetRunStatus(ctx context.Context, runID string) (*models.RunStatusResponse, error) {
+	var st models.RunStatusResponse
+	if err := s.q.QueryRowContext(ctx,
+		`SELECT id, status, error_message, progress_pct FROM runs WHERE id = ?`, runID,
+	).Scan(&st.ID, &st.Status, &st.ErrorMessage, &st.ProgressPct); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select run status: %w", err)
+	}
+	return &st, nil
 }
 
 // GetRunMetrics returns metrics for a run ID.
 func (s *Store) GetRunMetrics(ctx context.Context, runID string) (*models.RunMetrics, error) {
 	query := `
-		SELECT run_id, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs
-		FROM run_metrics WHERE run_id = ?
+		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs, rm.created_at, r.status
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+		WHERE rm.run_id = ?
 	`
-	var m models.RunMetrics
-	if err := s.db.QueryRowContext(ctx, query, runID).Scan(
-		&m.RunID,
-		&m.OnTimeRate,
-		&m.TotalDistance,
-		&m.AvgCompletionTime,
-		&m.MaxLateness,
-		&m.CompletedJobs,
-		&m.FailedJobs,
-		&m.TotalJobs,
-	); err != nil {
+	m, err := scanRunMetrics(s.q.QueryRowContext(ctx, query, runID))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select run metrics: %w", err)
 	}
-	return &m, nil
+	return m, nil
 }
 
-// GetLatestRunMetricsByMode returns the most recent completed run metrics for a scenario and mode.
+// GetLatestRunMetricsByMode returns the most recent completed run metrics for a scenario and mode,
+// restricted to runs recorded with the given scenario-hash algorithm version.
 func (s *Store) GetLatestRunMetricsByMode(
 	ctx context.Context,
 	seed int,
 	scale string,
 	mode string,
+	hashVersion int,
 	robots *int,
 	jobs *int,
 ) (*models.RunMetrics, error) {
-	var b strings.Builder
-	b.WriteString(`
-		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs
+	where, args := completedScenarioFilter(seed, scale, mode, hashVersion, robots, jobs)
+	query := `
+		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs, rm.created_at, r.status
 		FROM run_metrics rm
 		JOIN runs r ON r.id = rm.run_id
-		WHERE r.seed = ? AND r.scale = ? AND r.mode = ? AND r.status = 'completed'
-	`)
-	args := []any{seed, scale, mode}
-	if robots != nil && jobs != nil {
-		b.WriteString(" AND r.robots_count = ? AND r.jobs_count = ?")
-		args = append(args, *robots, *jobs)
-	}
-	b.WriteString(`
+	` + where + `
 		ORDER BY r.completed_at DESC, r.created_at DESC
 		LIMIT 1
-	`)
+	`
 
-	var m models.RunMetrics
-	if err := s.db.QueryRowContext(ctx, b.String(), args...).Scan(
-		&m.RunID,
-		&m.OnTimeRate,
-		&m.TotalDistance,
-		&m.AvgCompletionTime,
-		&m.MaxLateness,
-		&m.CompletedJobs,
-		&m.FailedJobs,
-		&m.TotalJobs,
-	); err != nil {
+	m, err := scanRunMetrics(s.q.QueryRowContext(ctx, query, args...))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("select latest metrics: %w", err)
 	}
+	return m, nil
+}
+
+// GetRecentRunMetricsByMode returns up to limit completed run metrics for a scenario
+// and mode, newest first, chosen and ordered as in GetLatestRunMetricsByMode.
+func (s *Store) GetRecentRunMetricsByMode(
+	ctx context.Context,
+	seed int,
+	scale string,
+	mode string,
+	hashVersion int,
+	robots *int,
+	jobs *int,
+	limit int,
+) ([]models.RunMetrics, error) {
+	where, args := completedScenarioFilter(seed, scale, mode, hashVersion, robots, jobs)
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs, rm.created_at, r.status
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+	`+where+`
+		ORDER BY r.completed_at DESC, r.created_at DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("select recent metrics: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.RunMetrics{}
+	for rows.Next() {
+		m, err := scanRunMetrics(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan recent metrics: %w", err)
+		}
+		out = append(out, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recent metrics: %w", err)
+	}
+	return out, nil
+}
+
+// CountCompletedRunsByMode counts the completed runs with metrics that
+// GetLatestRunMetricsByMode chooses from for the same arguments.
+func (s *Store) CountCompletedRunsByMode(
+	ctx context.Context,
+	seed int,
+	scale string,
+	mode string,
+	hashVersion int,
+	robots *int,
+	jobs *int,
+) (int, error) {
+	where, args := completedScenarioFilter(seed, scale, mode, hashVersion, robots, jobs)
+	var n int
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+	`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count completed runs: %w", err)
+	}
+	return n, nil
+}
+
+// completedScenarioFilter is the WHERE clause (over runs r) matching completed runs of
+// one mode for a scenario; the robots/jobs filter applies only when both are set.
+func completedScenarioFilter(seed int, scale, mode string, hashVersion int, robots, jobs *int) (string, []any) {
+	where := ` WHERE r.seed = ? AND r.scale = ? AND r.mode = ? AND r.scenario_hash_version = ? AND r.status = 'completed'`
+	args := []any{seed, scale, mode, hashVersion}
+	if robots != nil && jobs != nil {
+		where += " AND r.robots_count = ? AND r.jobs_count = ?"
+		args = append(args, *robots, *jobs)
+	}
+	return where, args
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRunMetrics scans the standard metrics column list (run_id, on_time_rate,
+// total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs,
+// total_jobs, created_at, status). A partially computed row may hold NULLs; those
+// map to zero values instead of failing the scan.
+func scanRunMetrics(row rowScanner) (*models.RunMetrics, error) {
+	var (
+		m                                         models.RunMetrics
+		onTime, distance, avgCompletion, lateness sql.NullFloat64
+		completedJobs, failedJobs, totalJobs      sql.NullInt64
+		computedAt                                sql.NullTime
+	)
+	if err := row.Scan(
+		&m.RunID,
+		&onTime,
+		&distance,
+		&avgCompletion,
+		&lateness,
+		&completedJobs,
+		&failedJobs,
+		&totalJobs,
+		&computedAt,
+		&m.RunStatus,
+	); err != nil {
+		return nil, err
+	}
+	m.OnTimeRate = onTime.Float64
+	m.TotalDistance = distance.Float64
+	m.AvgCompletionTime = avgCompletion.Float64
+	m.MaxLateness = lateness.Float64
+	m.CompletedJobs = int(completedJobs.Int64)
+	m.FailedJobs = int(failedJobs.Int64)
+	m.TotalJobs = int(totalJobs.Int64)
+	m.ComputedAt = computedAt.Time
 	return &m, nil
 }
@@ -0,0 +1,206 @@
+// Package dbtest provides an in-memory database/sql driver for tests of code
+// built on db.Store, so store-backed paths run without a MySQL server.
+package dbtest
+
+// File: internal/db/dbtest/dbtest.go
+// Purpose: Scripted fake SQL driver: statements are answered by the first registered
+// rule whose fragment appears in the query, and every statement is recorded.
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"fleet-api-go/internal/db"
+)
+
+// Result is what a matched statement returns. Queries read Columns and Rows;
+// Exec reads RowsAffected (and LastInsertID). A non-nil Err fails the statement.
+type Result struct {
+	Columns      []string
+	Rows         [][]any
+	RowsAffected int64
+	LastInsertID int64
+	Err          error
+}
+
+// Statement is one query or exec the fake received. Transaction boundaries are
+// recorded as "BEGIN", "COMMIT" and "ROLLBACK"; a BEGIN's Args are its
+// sql.IsolationLevel and read-only flag.
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// Rule answers statements containing a query fragment.
+type Rule func(args []any) Result
+
+type rule struct {
+	fragment string
+	fn       Rule
+}
+
+// Fake is the scripted database behind a Store returned by Open. It is safe for
+// concurrent use; rules run one at a time under its lock.
+type Fake struct {
+	mu    sync.Mutex
+	rules []rule
+	log   []Statement
+}
+
+// Open returns a Store backed by a new Fake, closed when t finishes.
+// Unmatched execs affect one row; unmatched queries return no rows.
+func Open(t testing.TB) (*db.Store, *Fake) {
+	t.Helper()
+	f := &Fake{}
+	sqlDB := sql.OpenDB(connector{f})
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db.Wrap(sqlDB), f
+}
+
+// On registers fn for statements containing fragment. Earlier rules win, so
+// register specific fragments before general ones.
+func (f *Fake) On(fragment string, fn Rule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule{fragment: fragment, fn: fn})
+}
+
+// Return registers a fixed result for statements containing fragment.
+func (f *Fake) Return(fragment string, r Result) {
+	f.On(fragment, func([]any) Result { return r })
+}
+
+// Statements returns a copy of every statement received so far, in order.
+func (f *Fake) Statements() []Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Statement(nil), f.log...)
+}
+
+// Matching returns the recorded statements containing fragment.
+func (f *Fake) Matching(fragment string) []Statement {
+	var out []Statement
+	for _, s := range f.Statements() {
+		if strings.Contains(s.Query, fragment) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// answer records a statement and runs the first matching rule.
+func (f *Fake) answer(query string, named []driver.NamedValue) (Result, bool) {
+	args := make([]any, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, Statement{Query: query, Args: args})
+	for _, r := range f.rules {
+		if strings.Contains(query, r.fragment) {
+			return r.fn(args), true
+		}
+	}
+	return Result{}, false
+}
+
+func (f *Fake) record(query string, args ...any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, Statement{Query: query, Args: args})
+}
+
+type connector struct{ f *Fake }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &conn{f: c.f}, nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("dbtest: use dbtest.Open")
+}
+
+type conn struct{ f *Fake }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("dbtest: prepared statements are not supported: %s", query)
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.f.record("BEGIN", sql.IsolationLevel(opts.Isolation), opts.ReadOnly)
+	return tx{c.f}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	r, ok := c.f.answer(query, args)
+	if !ok {
+		r.RowsAffected = 1
+	}
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return result{r}, nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	r, _ := c.f.answer(query, args)
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	columns := r.Columns
+	if len(columns) == 0 && len(r.Rows) > 0 {
+		for i := range r.Rows[0] {
+			columns = append(columns, fmt.Sprintf("c%d", i))
+		}
+	}
+	return &rows{columns: columns, values: r.Rows}, nil
+}
+
+type tx struct{ f *Fake }
+
+func (t tx) Commit() error   { t.f.record("COMMIT"); return nil }
+func (t tx) Rollback() error { t.f.record("ROLLBACK"); return nil }
+
+type result struct{ r Result }
+
+func (r result) LastInsertId() (int64, error) { return r.r.LastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.r.RowsAffected, nil }
+
+type rows struct {
+	columns []string
+	values  [][]any
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	row := r.values[r.next]
+	r.next++
+	for i := range dest {
+		v, err := driver.DefaultParameterConverter.ConvertValue(row[i])
+		if err != nil {
+			return fmt.Errorf("dbtest: column %d: %w", i, err)
+		}
+		dest[i] = v
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package db
+
+// File: internal/db/events.go
+// Purpose: The per-run event history (run_events table): appends and paginated reads.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// InsertRunEvent appends one event to a run's history, storing payload as JSON.
+func (s *Store) InsertRunEvent(ctx context.Context, runID, eventType, routingKey string, payload map[string]any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal run event: %w", err)
+	}
+	if _, err := s.q.ExecContext(ctx, `
+		INSERT INTO run_events (run_id, event_type, routing_key, payload_json) VALUES (?, ?, ?, ?)
+	`, runID, eventType, routingKey, body); err != nil {
+		return fmt.Errorf("insert run event: %w", err)
+	}
+	return nil
+}
+
+// CountRunEvents returns how many history events are stored for a run.
+func (s *Store) CountRunEvents(ctx context.Context, runID string) (int, error) {
+	var total int
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM run_events WHERE run_id = ?
+	`, runID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("count run events: %w", err)
+	}
+	return total, nil
+}
+
+// ListRunEvents returns a slice of a run's event history in chronological order.
+func (s *Store) ListRunEvents(ctx context.Context, runID string, limit, offset int) ([]models.RunEventRecord, error) {
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT id, run_id, event_type, routing_key, payload_json, created_at
+		FROM run_events
+		WHERE run_id = ?
+		ORDER BY created_at ASC, id ASC
+		LIMIT ?, ?
+	`, runID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select run events: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.RunEventRecord{}
+	for rows.Next() {
+		var (
+			e       models.RunEventRecord
+			payload []byte
+		)
+		if err := rows.Scan(&e.ID, &e.RunID, &e.EventType, &e.RoutingKey, &payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan run events: %w", err)
+		}
+		e.Payload = json.RawMessage(payload)
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate run events: %w", err)
+	}
+	return out, nil
+}
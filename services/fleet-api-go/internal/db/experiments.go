@@ -0,0 +1,111 @@
+package db
+
+// File: internal/db/experiments.go
+// Purpose: Persistence for experiments (experiments table) and their runs' aggregates.
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// CreateExperiment inserts an experiment row with the caller-generated ID.
+func (s *Store) CreateExperiment(ctx context.Context, e models.Experiment) error {
+	if _, err := s.q.ExecContext(ctx, `
+		INSERT INTO experiments (id, name, description, created_at)
+		VALUES (?, ?, ?, ?)
+	`, e.ID, e.Name, e.Description, e.CreatedAt); err != nil {
+		return fmt.Errorf("insert experiment: %w", err)
+	}
+	return nil
+}
+
+// GetExperiment returns an experiment by ID, or nil when it does not exist.
+func (s *Store) GetExperiment(ctx context.Context, id string) (*models.Experiment, error) {
+	var e models.Experiment
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT id, name, description, created_at FROM experiments WHERE id = ?
+	`, id).Scan(&e.ID, &e.Name, &e.Description, &e.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select experiment: %w", err)
+	}
+	return &e, nil
+}
+
+// ListExperimentRuns returns every run attached to an experiment, oldest first.
+func (s *Store) ListExperimentRuns(ctx context.Context, experimentID string) ([]models.Run, error) {
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT `+runColumns+`
+		FROM runs
+		WHERE experiment_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("select experiment runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan experiment runs: %w", err)
+		}
+		out = append(out, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate experiment runs: %w", err)
+	}
+	return out, nil
+}
+
+// GetExperimentAggregates returns per-mode run counts and metric averages for an
+// experiment, ordered by mode. Averages skip runs without metrics (AVG ignores NULLs).
+func (s *Store) GetExperimentAggregates(ctx context.Context, experimentID string) ([]models.ExperimentAggregate, error) {
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT r.mode,
+			COUNT(*),
+			SUM(r.status = 'completed'),
+			COUNT(rm.run_id),
+			AVG(rm.on_time_rate),
+			AVG(rm.total_distance),
+			AVG(rm.avg_completion_time),
+			AVG(rm.max_lateness)
+		FROM runs r
+		LEFT JOIN run_metrics rm ON rm.run_id = r.id
+		WHERE r.experiment_id = ?
+		GROUP BY r.mode
+		ORDER BY r.mode
+	`, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("select experiment aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ExperimentAggregate{}
+	for rows.Next() {
+		var a models.ExperimentAggregate
+		if err := rows.Scan(
+			&a.Mode,
+			&a.Runs,
+			&a.Completed,
+			&a.WithMetrics,
+			&a.AvgOnTimeRate,
+			&a.AvgTotalDistance,
+			&a.AvgAvgCompletionTime,
+			&a.AvgMaxLateness,
+		); err != nil {
+			return nil, fmt.Errorf("scan experiment aggregates: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate experiment aggregates: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+// File: internal/db/history.go
+// Purpose: Paginated reads of the append-only run_metrics_history table.
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// ListRunMetricsHistory returns a page of a run's metrics snapshots in the order
+// they were written, plus the total count.
+func (s *Store) ListRunMetricsHistory(ctx context.Context, runID string, limit, offset int) ([]models.RunMetricsSnapshot, int, error) {
+	var total int
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM run_metrics_history WHERE run_id = ?
+	`, runID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count run metrics history: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT sim_time_s, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs, recorded_at
+		FROM run_metrics_history
+		WHERE run_id = ?
+		ORDER BY id ASC
+		LIMIT ? OFFSET ?
+	`, runID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select run metrics history: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.RunMetricsSnapshot{}
+	for rows.Next() {
+		var m models.RunMetricsSnapshot
+		if err := rows.Scan(
+			&m.SimTimeS,
+			&m.OnTimeRate,
+			&m.TotalDistance,
+			&m.AvgCompletionTime,
+			&m.MaxLateness,
+			&m.CompletedJobs,
+			&m.FailedJobs,
+			&m.TotalJobs,
+			&m.RecordedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan run metrics history: %w", err)
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate run metrics history: %w", err)
+	}
+	return out, total, nil
+}
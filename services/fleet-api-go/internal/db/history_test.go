@@ -0,0 +1,35 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestListRunMetricsHistoryPages(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	recorded := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("FROM run_metrics_history", dbtest.Result{Rows: [][]any{
+		{60, 1.0, 40.0, 20.0, 0.0, 3, 0, 10, recorded},
+		{nil, 0.8, 80.0, 25.0, 2.0, 6, 1, 10, recorded.Add(time.Minute)},
+	}})
+
+	got, total, err := store.ListRunMetricsHistory(context.Background(), "run-1", 2, 1)
+	if err != nil {
+		t.Fatalf("ListRunMetricsHistory: %v", err)
+	}
+	if total != 3 || len(got) != 2 {
+		t.Fatalf("total = %d, rows = %d, want 3 and 2", total, len(got))
+	}
+	if got[0].SimTimeS == nil || *got[0].SimTimeS != 60 || got[1].SimTimeS != nil || got[1].OnTimeRate != 0.8 {
+		t.Errorf("history = %+v", got)
+	}
+	sel := fake.Matching("ORDER BY id ASC")
+	if len(sel) != 1 || !slices.Equal(sel[0].Args, []any{"run-1", int64(2), int64(1)}) {
+		t.Errorf("select = %+v, want run-1 with LIMIT 2 OFFSET 1", sel)
+	}
+}
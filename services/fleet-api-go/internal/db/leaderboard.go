@@ -0,0 +1,107 @@
+package db
+
+// File: internal/db/leaderboard.go
+// Purpose: Completed runs ranked by one metric, with optional quality-gate predicates.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// leaderboardColumns maps rankable metric names to run_metrics columns. Only these
+// names are ever interpolated into the query.
+var leaderboardColumns = map[string]string{
+	"on_time_rate":        "rm.on_time_rate",
+	"total_distance":      "rm.total_distance",
+	"avg_completion_time": "rm.avg_completion_time",
+	"max_lateness":        "rm.max_lateness",
+	"completed_jobs":      "rm.completed_jobs",
+	"failed_jobs":         "rm.failed_jobs",
+	"total_jobs":          "rm.total_jobs",
+}
+
+// ListLeaderboard returns up to q.Limit completed runs ordered by q.Metric. The Min/Max
+// bounds are applied as SQL predicates (inclusive), so the limit counts only runs that
+// clear every bound. Ties are broken by run ID for a stable order.
+func (s *Store) ListLeaderboard(ctx context.Context, q models.LeaderboardQuery) ([]models.LeaderboardEntry, error) {
+	orderCol, ok := leaderboardColumns[q.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown leaderboard metric %q", q.Metric)
+	}
+	var where strings.Builder
+	where.WriteString(" WHERE r.status = 'completed'")
+	var args []any
+	if q.Mode != "" {
+		where.WriteString(" AND r.mode = ?")
+		args = append(args, q.Mode)
+	}
+	if q.Scale != "" {
+		where.WriteString(" AND r.scale = ?")
+		args = append(args, q.Scale)
+	}
+	for _, b := range []struct {
+		bounds map[string]float64
+		op     string
+	}{{q.Min, ">="}, {q.Max, "<="}} {
+		names := make([]string, 0, len(b.bounds))
+		for name := range b.bounds {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			col, ok := leaderboardColumns[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown leaderboard metric %q", name)
+			}
+			where.WriteString(" AND " + col + " " + b.op + " ?")
+			args = append(args, b.bounds[name])
+		}
+	}
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs, rm.created_at, r.status,
+			r.mode, r.seed, r.scale
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id`+where.String()+`
+		ORDER BY `+orderCol+` `+direction+`, rm.run_id ASC
+		LIMIT ?
+	`, append(args, q.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("select leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var e models.LeaderboardEntry
+		m, err := scanRunMetrics(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &e.Mode, &e.Seed, &e.Scale)...)
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("scan leaderboard: %w", err)
+		}
+		e.RunID = m.RunID
+		e.Metrics = *m
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate leaderboard: %w", err)
+	}
+	return out, nil
+}
+
+// scanFunc adapts a function to rowScanner, e.g. to scan extra trailing columns
+// alongside a shared scanner.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error {
+	return f(dest...)
+}
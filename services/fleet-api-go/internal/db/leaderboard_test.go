@@ -0,0 +1,70 @@
+package db_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestListLeaderboardPushesBoundsIntoQuery(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-2", 0.95, 120.0, 30.0, 2.0, 48, 2, 50, now, "completed", "ga", 42, "demo"},
+		{"run-1", 0.9, 150.0, 30.0, 4.0, 45, 5, 50, now, "completed", "baseline", 7, "demo"},
+	}})
+
+	entries, err := store.ListLeaderboard(context.Background(), models.LeaderboardQuery{
+		Metric:     "total_distance",
+		Scale:      "demo",
+		Min:        map[string]float64{"on_time_rate": 0.8, "completed_jobs": 40},
+		Max:        map[string]float64{"max_lateness": 10},
+		Limit:      5,
+		Descending: false,
+	})
+	if err != nil {
+		t.Fatalf("ListLeaderboard: %v", err)
+	}
+	if len(entries) != 2 || entries[0].RunID != "run-2" || entries[0].Mode != "ga" || entries[1].Seed != 7 {
+		t.Fatalf("entries = %+v", entries)
+	}
+
+	stmts := fake.Matching("FROM run_metrics rm")
+	if len(stmts) != 1 {
+		t.Fatalf("leaderboard queries = %d, want 1", len(stmts))
+	}
+	query := strings.Join(strings.Fields(stmts[0].Query), " ")
+	for _, want := range []string{
+		"WHERE r.status = 'completed' AND r.scale = ?",
+		// Bounds are emitted in sorted name order, mins before maxes.
+		"AND rm.completed_jobs >= ? AND rm.on_time_rate >= ? AND rm.max_lateness <= ?",
+		"ORDER BY rm.total_distance ASC, rm.run_id ASC LIMIT ?",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %q does not contain %q", query, want)
+		}
+	}
+	if want := []any{"demo", 40.0, 0.8, 10.0, int64(5)}; !reflect.DeepEqual(stmts[0].Args, want) {
+		t.Errorf("args = %v, want %v", stmts[0].Args, want)
+	}
+}
+
+func TestListLeaderboardRejectsUnknownMetric(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	for _, q := range []models.LeaderboardQuery{
+		{Metric: "rm.id; DROP TABLE runs", Limit: 1},
+		{Metric: "on_time_rate", Min: map[string]float64{"1=1 OR rm.on_time_rate": 0}, Limit: 1},
+	} {
+		if _, err := store.ListLeaderboard(context.Background(), q); err == nil || !strings.Contains(err.Error(), "unknown leaderboard metric") {
+			t.Errorf("ListLeaderboard(%+v) error = %v, want unknown leaderboard metric", q, err)
+		}
+	}
+	if got := len(fake.Statements()); got != 0 {
+		t.Errorf("statements = %d, want none for unknown metrics", got)
+	}
+}
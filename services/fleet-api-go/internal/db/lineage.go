@@ -0,0 +1,71 @@
+package db
+
+// File: internal/db/lineage.go
+// Purpose: Retry lineage traversal over runs.retry_of.
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// maxLineageDepth bounds both recursive walks so a corrupt retry_of cycle cannot
+// recurse without end.
+const maxLineageDepth = 100
+
+// GetRunLineage returns every run in runID's retry chain: the root (the first run,
+// found by following retry_of upward) and all of its direct and indirect retries,
+// oldest first. It returns nil when runID does not exist.
+func (s *Store) GetRunLineage(ctx context.Context, runID string) ([]models.Run, error) {
+	var rootID string
+	err := s.q.QueryRowContext(ctx, `
+		WITH RECURSIVE up (id, retry_of, depth) AS (
+			SELECT id, retry_of, 0 FROM runs WHERE id = ?
+			UNION ALL
+			SELECT r.id, r.retry_of, up.depth + 1
+			FROM runs r JOIN up ON r.id = up.retry_of
+			WHERE up.depth < ?
+		)
+		SELECT id FROM up ORDER BY depth DESC LIMIT 1
+	`, runID, maxLineageDepth).Scan(&rootID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select lineage root: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		WITH RECURSIVE down (id, depth) AS (
+			SELECT id, 0 FROM runs WHERE id = ?
+			UNION ALL
+			SELECT r.id, down.depth + 1
+			FROM runs r JOIN down ON r.retry_of = down.id
+			WHERE down.depth < ?
+		)
+		SELECT `+runColumns+`
+		FROM runs
+		WHERE id IN (SELECT id FROM down)
+		ORDER BY created_at ASC, id ASC
+	`, rootID, maxLineageDepth)
+	if err != nil {
+		return nil, fmt.Errorf("select lineage: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan lineage: %w", err)
+		}
+		out = append(out, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate lineage: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestGetRunLineage(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("WITH RECURSIVE up", dbtest.Result{Rows: [][]any{{"run-a"}}})
+	fake.Return("WITH RECURSIVE down", dbtest.Result{Rows: [][]any{
+		{"run-a", "ga", 7, "demo", nil, nil, "hash", 2, "failed", nil, now, now, now, nil, nil, nil, nil, nil},
+		{"run-b", "ga", 7, "demo", nil, nil, "hash", 2, "failed", nil, now, now, now, nil, "run-a", nil, nil, nil},
+		{"run-c", "ga", 7, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, "run-b", nil, nil, nil},
+	}})
+
+	lineage, err := store.GetRunLineage(context.Background(), "run-c")
+	if err != nil {
+		t.Fatalf("GetRunLineage: %v", err)
+	}
+	if len(lineage) != 3 || lineage[0].ID != "run-a" || lineage[2].RetryOf == nil || *lineage[2].RetryOf != "run-b" {
+		t.Fatalf("lineage = %+v, want run-a, run-b, run-c with retry_of links", lineage)
+	}
+
+	up, down := fake.Matching("WITH RECURSIVE up"), fake.Matching("WITH RECURSIVE down")
+	if len(up) != 1 || up[0].Args[0] != "run-c" {
+		t.Errorf("root walk = %+v, want one starting from run-c", up)
+	}
+	// The descendant walk starts from the root, so siblings of run-c are included too.
+	if len(down) != 1 || down[0].Args[0] != "run-a" {
+		t.Errorf("descendant walk = %+v, want one starting from the root", down)
+	}
+	for _, st := range append(up, down...) {
+		if st.Args[1] != int64(100) {
+			t.Errorf("depth bound = %v, want 100", st.Args[1])
+		}
+	}
+}
+
+func TestGetRunLineageMissingRun(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	lineage, err := store.GetRunLineage(context.Background(), "missing")
+	if err != nil || lineage != nil {
+		t.Errorf("GetRunLineage = %v, %v, want nil, nil", lineage, err)
+	}
+	if got := len(fake.Matching("WITH RECURSIVE down")); got != 0 {
+		t.Errorf("descendant walks = %d, want none without a root", got)
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+// File: internal/db/maintenance.go
+// Purpose: Persistence for scheduled maintenance windows (maintenance_windows table).
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+const maintenanceColumns = `id, starts_at, ends_at, reason, created_at`
+
+// InsertMaintenanceWindow stores a window and fills in its generated ID.
+func (s *Store) InsertMaintenanceWindow(ctx context.Context, w *models.MaintenanceWindow) error {
+	res, err := s.q.ExecContext(ctx, `
+		INSERT INTO maintenance_windows (starts_at, ends_at, reason, created_at)
+		VALUES (?, ?, ?, ?)
+	`, w.StartsAt, w.EndsAt, w.Reason, w.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert maintenance window: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("maintenance window id: %w", err)
+	}
+	w.ID = id
+	return nil
+}
+
+// GetActiveMaintenanceWindow returns the window covering now, or nil. When windows
+// overlap, the one ending last is returned so callers report the real end time.
+func (s *Store) GetActiveMaintenanceWindow(ctx context.Context, now time.Time) (*models.MaintenanceWindow, error) {
+	return s.selectMaintenanceWindow(ctx, `
+		SELECT `+maintenanceColumns+` FROM maintenance_windows
+		WHERE starts_at <= ? AND ends_at > ?
+		ORDER BY ends_at DESC, id DESC
+		LIMIT 1
+	`, now, now)
+}
+
+// GetNextMaintenanceWindow returns the earliest window that has not started yet, or nil.
+func (s *Store) GetNextMaintenanceWindow(ctx context.Context, now time.Time) (*models.MaintenanceWindow, error) {
+	return s.selectMaintenanceWindow(ctx, `
+		SELECT `+maintenanceColumns+` FROM maintenance_windows
+		WHERE starts_at > ?
+		ORDER BY starts_at ASC, id ASC
+		LIMIT 1
+	`, now)
+}
+
+func (s *Store) selectMaintenanceWindow(ctx context.Context, query string, args ...any) (*models.MaintenanceWindow, error) {
+	var w models.MaintenanceWindow
+	err := s.q.QueryRowContext(ctx, query, args...).Scan(&w.ID, &w.StartsAt, &w.EndsAt, &w.Reason, &w.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("select maintenance window: %w", err)
+	}
+	return &w, nil
+}
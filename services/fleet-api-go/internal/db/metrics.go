@@ -0,0 +1,81 @@
+package db
+
+// File: internal/db/metrics.go
+// Purpose: Bulk run_metrics reads split into bounded IN-clause chunks.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// GetRunMetricsByIDs returns metrics for the given run IDs. Duplicate IDs are
+// queried once, and the IN clause is split into chunks of at most chunkSize
+// placeholders so large requests stay well below MySQL's placeholder limit.
+// Runs without a metrics row are simply absent from the result, which follows
+// the order of first appearance in runIDs.
+func (s *Store) GetRunMetricsByIDs(ctx context.Context, runIDs []string, chunkSize int) ([]models.RunMetrics, error) {
+	ids := dedupeIDs(runIDs)
+	if chunkSize <= 0 {
+		chunkSize = len(ids)
+	}
+	found := make(map[string]models.RunMetrics, len(ids))
+	for start := 0; start < len(ids); start += chunkSize {
+		end := min(start+chunkSize, len(ids))
+		if err := s.selectRunMetricsChunk(ctx, ids[start:end], found); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]models.RunMetrics, 0, len(found))
+	for _, id := range ids {
+		if m, ok := found[id]; ok {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) selectRunMetricsChunk(ctx context.Context, ids []string, into map[string]models.RunMetrics) error {
+	query := `
+		SELECT rm.run_id, rm.on_time_rate, rm.total_distance, rm.avg_completion_time, rm.max_lateness, rm.completed_jobs, rm.failed_jobs, rm.total_jobs, rm.created_at, r.status
+		FROM run_metrics rm
+		JOIN runs r ON r.id = rm.run_id
+		WHERE rm.run_id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + `)`
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("select run metrics by ids: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		m, err := scanRunMetrics(rows)
+		if err != nil {
+			return fmt.Errorf("scan run metrics: %w", err)
+		}
+		into[m.RunID] = *m
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate run metrics: %w", err)
+	}
+	return nil
+}
+
+// dedupeIDs drops repeated IDs, keeping the first occurrence of each.
+func dedupeIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
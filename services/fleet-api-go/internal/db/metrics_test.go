@@ -0,0 +1,89 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+// metricsRows answers a chunked run_metrics select with a row for every bound ID
+// in have, in reverse order so callers cannot rely on the database's ordering.
+func metricsRows(have map[string]bool) dbtest.Rule {
+	computed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func(args []any) dbtest.Result {
+		var rows [][]any
+		for i := len(args) - 1; i >= 0; i-- {
+			if id := args[i].(string); have[id] {
+				rows = append(rows, []any{id, 0.9, 100.0, 30.0, 5.0, 9, 1, 10, computed, "completed"})
+			}
+		}
+		return dbtest.Result{Rows: rows}
+	}
+}
+
+func TestGetRunMetricsByIDsChunksTheInClause(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	have := map[string]bool{"r1": true, "r2": true, "r3": true, "r5": true, "r6": true, "r7": true}
+	fake.On("WHERE rm.run_id IN", metricsRows(have))
+
+	ids := []string{"r7", "r1", "r2", "r1", "r3", "r4", "r5", "r6", "r7"}
+	got, err := store.GetRunMetricsByIDs(context.Background(), ids, 3)
+	if err != nil {
+		t.Fatalf("GetRunMetricsByIDs: %v", err)
+	}
+
+	var chunks [][]any
+	for _, st := range fake.Matching("WHERE rm.run_id IN") {
+		chunks = append(chunks, st.Args)
+	}
+	want := [][]any{{"r7", "r1", "r2"}, {"r3", "r4", "r5"}, {"r6"}}
+	if !slices.EqualFunc(chunks, want, slices.Equal[[]any]) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+
+	var order []string
+	for _, m := range got {
+		order = append(order, m.RunID)
+	}
+	if want := []string{"r7", "r1", "r2", "r3", "r5", "r6"}; !slices.Equal(order, want) {
+		t.Errorf("metrics order = %v, want first-appearance order %v", order, want)
+	}
+}
+
+func TestGetRunMetricsByIDsUnchunked(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("WHERE rm.run_id IN", metricsRows(map[string]bool{"r1": true}))
+
+	if _, err := store.GetRunMetricsByIDs(context.Background(), []string{"r1", "r2", "r3"}, 0); err != nil {
+		t.Fatalf("GetRunMetricsByIDs: %v", err)
+	}
+	if sel := fake.Matching("WHERE rm.run_id IN"); len(sel) != 1 || len(sel[0].Args) != 3 {
+		t.Errorf("selects = %+v, want one over all 3 ids", sel)
+	}
+}
+
+func TestGetRunMetricsNullColumns(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	// A metrics row sim-runner wrote before computing anything: every value column is NULL.
+	fake.Return("WHERE rm.run_id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", nil, nil, nil, nil, nil, nil, nil, nil, "failed"},
+	}})
+
+	m, err := store.GetRunMetrics(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("GetRunMetrics: %v", err)
+	}
+	if m == nil {
+		t.Fatal("metrics = nil, want a zero-valued row")
+	}
+	if m.RunID != "run-1" || m.RunStatus != "failed" {
+		t.Errorf("run = %q/%q, want run-1/failed", m.RunID, m.RunStatus)
+	}
+	if m.OnTimeRate != 0 || m.TotalDistance != 0 || m.AvgCompletionTime != 0 || m.MaxLateness != 0 ||
+		m.CompletedJobs != 0 || m.FailedJobs != 0 || m.TotalJobs != 0 || !m.ComputedAt.IsZero() {
+		t.Errorf("metrics = %+v, want NULLs read as zero values", m)
+	}
+}
@@ -0,0 +1,63 @@
+package db
+
+// File: internal/db/notes.go
+// Purpose: Persistence for free-text run notes (run_notes table).
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// InsertRunNote stores a note and fills in its generated ID.
+func (s *Store) InsertRunNote(ctx context.Context, note *models.RunNote) error {
+	res, err := s.q.ExecContext(ctx, `
+		INSERT INTO run_notes (run_id, author, text, created_at)
+		VALUES (?, ?, ?, ?)
+	`, note.RunID, note.Author, note.Text, note.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert run note: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("run note id: %w", err)
+	}
+	note.ID = id
+	return nil
+}
+
+// ListRunNotes returns a page of notes for a run, oldest first, plus the total count.
+func (s *Store) ListRunNotes(ctx context.Context, runID string, limit, offset int) ([]models.RunNote, int, error) {
+	var total int
+	if err := s.q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM run_notes WHERE run_id = ?
+	`, runID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count run notes: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT id, run_id, author, text, created_at
+		FROM run_notes
+		WHERE run_id = ?
+		ORDER BY created_at ASC, id ASC
+		LIMIT ? OFFSET ?
+	`, runID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select run notes: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.RunNote{}
+	for rows.Next() {
+		var n models.RunNote
+		if err := rows.Scan(&n.ID, &n.RunID, &n.Author, &n.Text, &n.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("scan run notes: %w", err)
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate run notes: %w", err)
+	}
+	return out, total, nil
+}
@@ -0,0 +1,171 @@
+package db
+
+// File: internal/db/runs.go
+// Purpose: Filtered, paginated listing of runs.
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// runColumns is the column list scanned by scanRun.
+const runColumns = `id, mode, seed, scale, robots_count, jobs_count, scenario_hash, scenario_hash_version, status, error_message, created_at, started_at, completed_at, ga_params, retry_of, experiment_id, progress_pct, replan_interval_s`
+
+// ListRuns returns runs matching f, newest first, plus the total number of matches.
+func (s *Store) ListRuns(ctx context.Context, f models.RunFilter, limit, offset int) ([]models.Run, int, error) {
+	var where strings.Builder
+	where.WriteString(" WHERE 1=1")
+	var args []any
+	if f.Status != "" {
+		where.WriteString(" AND status = ?")
+		args = append(args, f.Status)
+	}
+	if f.HasError != nil {
+		if *f.HasError {
+			where.WriteString(" AND error_message IS NOT NULL AND error_message != ''")
+		} else {
+			where.WriteString(" AND (error_message IS NULL OR error_message = '')")
+		}
+	}
+	if f.ExperimentID != "" {
+		where.WriteString(" AND experiment_id = ?")
+		args = append(args, f.ExperimentID)
+	}
+	args = appendTimeRange(&where, args, "created_at", f.Created)
+	// One EXISTS per tag, so a run must carry every requested tag.
+	for _, tag := range f.Tags {
+		where.WriteString(" AND EXISTS (SELECT 1 FROM run_tags t WHERE t.run_id = runs.id AND t.tag = ?)")
+		args = append(args, tag)
+	}
+
+	var total int
+	if err := s.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM runs`+where.String(), args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count runs: %w", err)
+	}
+
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT `+runColumns+`
+		FROM runs`+where.String()+`
+		ORDER BY created_at DESC, id ASC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("select runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("scan runs: %w", err)
+		}
+		out = append(out, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterate runs: %w", err)
+	}
+	return out, total, nil
+}
+
+// GetRunsByIDs returns the given runs in order of first appearance in runIDs,
+// chunking the IN clause like GetRunMetricsByIDs. Unknown IDs are absent.
+func (s *Store) GetRunsByIDs(ctx context.Context, runIDs []string, chunkSize int) ([]models.Run, error) {
+	ids := dedupeIDs(runIDs)
+	if chunkSize <= 0 {
+		chunkSize = len(ids)
+	}
+	found := make(map[string]models.Run, len(ids))
+	for start := 0; start < len(ids); start += chunkSize {
+		end := min(start+chunkSize, len(ids))
+		if err := s.selectRunsChunk(ctx, ids[start:end], found); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]models.Run, 0, len(found))
+	for _, id := range ids {
+		if run, ok := found[id]; ok {
+			out = append(out, run)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) selectRunsChunk(ctx context.Context, ids []string, into map[string]models.Run) error {
+	query := `SELECT ` + runColumns + ` FROM runs WHERE id IN (` + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + `)`
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("select runs by ids: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return fmt.Errorf("scan runs: %w", err)
+		}
+		into[run.ID] = *run
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate runs by ids: %w", err)
+	}
+	return nil
+}
+
+// scanRun scans one row selected with runColumns.
+func scanRun(row rowScanner) (*models.Run, error) {
+	var (
+		run      models.Run
+		gaParams sql.NullString
+	)
+	if err := row.Scan(
+		&run.ID,
+		&run.Mode,
+		&run.Seed,
+		&run.Scale,
+		&run.RobotsCount,
+		&run.JobsCount,
+		&run.ScenarioHash,
+		&run.ScenarioHashVersion,
+		&run.Status,
+		&run.ErrorMessage,
+		&run.CreatedAt,
+		&run.StartedAt,
+		&run.CompletedAt,
+		&gaParams,
+		&run.RetryOf,
+		&run.ExperimentID,
+		&run.ProgressPct,
+		&run.ReplanIntervalS,
+	); err != nil {
+		return nil, err
+	}
+	if gaParams.Valid {
+		run.GAParams = &models.GAParams{}
+		if err := json.Unmarshal([]byte(gaParams.String), run.GAParams); err != nil {
+			return nil, fmt.Errorf("decode ga_params for run %s: %w", run.ID, err)
+		}
+	}
+	return &run, nil
+}
+
+// encodeGAParams returns the JSON stored in runs.ga_params, or nil (SQL NULL) when unset.
+func encodeGAParams(p *models.GAParams) (any, error) {
+	if p == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("encode ga_params: %w", err)
+	}
+	return string(b), nil
+}
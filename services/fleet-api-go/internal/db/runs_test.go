@@ -0,0 +1,237 @@
+package db_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// runRow is a runs row in runColumns order.
+func runRow(id string) []any {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []any{id, "ga", 42, "small", nil, nil, "hash", 2, "completed", nil, created, created, nil, nil, nil, nil, nil, nil}
+}
+
+// taggedRuns scripts ListRuns over runs carrying tags. With no other filter set,
+// the bound arguments are the tags (one per EXISTS clause), then LIMIT and OFFSET.
+func taggedRuns(fake *dbtest.Fake, tags map[string][]string, order []string) {
+	matching := func(want []any) []string {
+		var ids []string
+		for _, id := range order {
+			if !slices.ContainsFunc(want, func(tag any) bool { return !slices.Contains(tags[id], tag.(string)) }) {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	fake.On("SELECT COUNT(*) FROM runs", func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{len(matching(args))}}}
+	})
+	fake.On("ORDER BY created_at DESC", func(args []any) dbtest.Result {
+		var rows [][]any
+		for _, id := range matching(args[:len(args)-2]) {
+			rows = append(rows, runRow(id))
+		}
+		return dbtest.Result{Rows: rows}
+	})
+}
+
+func TestListRunsRequiresEveryTag(t *testing.T) {
+	tags := map[string][]string{
+		"run-both":  {"exp:q3", "owner:alice"},
+		"run-exp":   {"exp:q3"},
+		"run-owner": {"owner:alice"},
+	}
+	order := []string{"run-both", "run-exp", "run-owner"}
+	for _, tc := range []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"one tag", []string{"exp:q3"}, []string{"run-both", "run-exp"}},
+		{"two tags", []string{"exp:q3", "owner:alice"}, []string{"run-both"}},
+		{"no match", []string{"exp:q3", "owner:bob"}, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, fake := dbtest.Open(t)
+			taggedRuns(fake, tags, order)
+
+			runs, total, err := store.ListRuns(context.Background(), models.RunFilter{Tags: tc.tags}, 50, 0)
+			if err != nil {
+				t.Fatalf("ListRuns: %v", err)
+			}
+			if runs == nil {
+				t.Fatal("runs is nil, want an empty list")
+			}
+			var ids []string
+			for _, run := range runs {
+				ids = append(ids, run.ID)
+			}
+			if !slices.Equal(ids, tc.want) || total != len(tc.want) {
+				t.Errorf("runs = %v (total %d), want %v", ids, total, tc.want)
+			}
+
+			sel := fake.Matching("ORDER BY created_at DESC")
+			if len(sel) != 1 {
+				t.Fatalf("selects = %d, want 1", len(sel))
+			}
+			if got := strings.Count(sel[0].Query, "EXISTS (SELECT 1 FROM run_tags"); got != len(tc.tags) {
+				t.Errorf("EXISTS clauses = %d, want one per tag (%d)", got, len(tc.tags))
+			}
+			wantArgs := []any{}
+			for _, tag := range tc.tags {
+				wantArgs = append(wantArgs, tag)
+			}
+			wantArgs = append(wantArgs, int64(50), int64(0))
+			if !slices.Equal(sel[0].Args, wantArgs) {
+				t.Errorf("args = %v, want %v", sel[0].Args, wantArgs)
+			}
+		})
+	}
+}
+
+func TestAttachRunTagsGroupsByRun(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.Return("FROM run_tags", dbtest.Result{Rows: [][]any{
+		{"run-1", "exp:q3"}, {"run-1", "owner:alice"}, {"run-3", "exp:q4"},
+	}})
+
+	runs := []models.Run{{ID: "run-1"}, {ID: "run-2"}, {ID: "run-3"}}
+	if err := store.AttachRunTags(context.Background(), runs); err != nil {
+		t.Fatalf("AttachRunTags: %v", err)
+	}
+	if !slices.Equal(runs[0].Tags, []string{"exp:q3", "owner:alice"}) || runs[1].Tags != nil || !slices.Equal(runs[2].Tags, []string{"exp:q4"}) {
+		t.Errorf("tags = %v / %v / %v", runs[0].Tags, runs[1].Tags, runs[2].Tags)
+	}
+	if q := fake.Matching("FROM run_tags"); len(q) != 1 || len(q[0].Args) != 3 {
+		t.Errorf("tag queries = %+v, want one over 3 ids", q)
+	}
+}
+
+func TestListRunsBoundsCreatedAt(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	if _, _, err := store.ListRuns(context.Background(), models.RunFilter{
+		Status:  "completed",
+		Created: models.TimeRange{From: &from, To: &to},
+		Tags:    []string{"exp:q3"},
+	}, 10, 20); err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	sel := fake.Matching("ORDER BY created_at DESC")
+	if len(sel) != 1 {
+		t.Fatalf("selects = %d, want 1", len(sel))
+	}
+	if !strings.Contains(sel[0].Query, "AND created_at >= ? AND created_at <= ?") {
+		t.Errorf("query lacks the created_at bounds: %s", sel[0].Query)
+	}
+	want := []any{"completed", from.UTC(), to, "exp:q3", int64(10), int64(20)}
+	if !slices.EqualFunc(sel[0].Args, want, func(a, b any) bool {
+		if ta, ok := a.(time.Time); ok {
+			return ta.Equal(b.(time.Time))
+		}
+		return a == b
+	}) {
+		t.Errorf("args = %v, want %v", sel[0].Args, want)
+	}
+}
+
+func TestListRunsHasError(t *testing.T) {
+	yes, no := true, false
+	for _, tc := range []struct {
+		name     string
+		filter   models.RunFilter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"has_error=true", models.RunFilter{HasError: &yes}, " AND error_message IS NOT NULL AND error_message != ''", []any{int64(50), int64(0)}},
+		{"has_error=false", models.RunFilter{HasError: &no}, " AND (error_message IS NULL OR error_message = '')", []any{int64(50), int64(0)}},
+		{"with status", models.RunFilter{Status: "failed", HasError: &yes}, " WHERE 1=1 AND status = ? AND error_message IS NOT NULL AND error_message != ''", []any{"failed", int64(50), int64(0)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, fake := dbtest.Open(t)
+			fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+			if _, _, err := store.ListRuns(context.Background(), tc.filter, 50, 0); err != nil {
+				t.Fatalf("ListRuns: %v", err)
+			}
+			count, sel := fake.Matching("SELECT COUNT(*) FROM runs"), fake.Matching("ORDER BY created_at DESC")
+			if len(count) != 1 || len(sel) != 1 {
+				t.Fatalf("counts = %d, selects = %d, want 1 each", len(count), len(sel))
+			}
+			for _, q := range []string{count[0].Query, sel[0].Query} {
+				if !strings.Contains(q, tc.wantSQL) {
+					t.Errorf("query lacks %q: %s", tc.wantSQL, q)
+				}
+			}
+			if !slices.Equal(sel[0].Args, tc.wantArgs) {
+				t.Errorf("args = %v, want %v", sel[0].Args, tc.wantArgs)
+			}
+		})
+	}
+
+	store, fake := dbtest.Open(t)
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+	if _, _, err := store.ListRuns(context.Background(), models.RunFilter{}, 50, 0); err != nil {
+		t.Fatalf("ListRuns: %v", err)
+	}
+	if q := fake.Matching("ORDER BY created_at DESC")[0].Query; strings.Contains(q, "error_message IS") {
+		t.Errorf("unfiltered query tests error_message: %s", q)
+	}
+}
+
+func TestGetRunsByIDsChunksAndKeepsRequestOrder(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("FROM runs WHERE id IN", func(args []any) dbtest.Result {
+		var rows [][]any
+		for i := len(args) - 1; i >= 0; i-- {
+			if id := args[i].(string); id != "r4" {
+				rows = append(rows, runRow(id))
+			}
+		}
+		return dbtest.Result{Rows: rows}
+	})
+
+	got, err := store.GetRunsByIDs(context.Background(), []string{"r5", "r1", "r5", "r4", "r2", "r3"}, 2)
+	if err != nil {
+		t.Fatalf("GetRunsByIDs: %v", err)
+	}
+	var chunks [][]any
+	for _, st := range fake.Matching("FROM runs WHERE id IN") {
+		chunks = append(chunks, st.Args)
+	}
+	if want := [][]any{{"r5", "r1"}, {"r4", "r2"}, {"r3"}}; !slices.EqualFunc(chunks, want, slices.Equal[[]any]) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+	var order []string
+	for _, run := range got {
+		order = append(order, run.ID)
+	}
+	if want := []string{"r5", "r1", "r2", "r3"}; !slices.Equal(order, want) {
+		t.Errorf("runs = %v, want request order %v", order, want)
+	}
+}
+
+func TestGetRunNullTimes(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	row := runRow("run-1")
+	row[8] = "started"
+	row[11] = nil // started_at not yet set
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{row}})
+
+	run, err := store.GetRun(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("GetRun: %v", err)
+	}
+	if run == nil || run.StartedAt != nil || run.CompletedAt != nil || run.CreatedAt.IsZero() {
+		t.Errorf("run = %+v, want nil started_at and completed_at", run)
+	}
+}
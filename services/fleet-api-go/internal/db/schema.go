@@ -0,0 +1,89 @@
+package db
+
+// File: internal/db/schema.go
+// Purpose: Startup self-check that the runs and run_metrics tables have the columns the queries rely on.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// runMetricsColumns are the run_metrics columns read and written by this package.
+const runMetricsColumns = `run_id, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs, created_at`
+
+// expectedSchema maps each checked table to the columns it must have.
+var expectedSchema = map[string][]string{
+	"runs":        splitColumns(runColumns),
+	"run_metrics": splitColumns(runMetricsColumns),
+}
+
+// CheckSchema verifies that the connected database has the runs and run_metrics
+// tables with every expected column, so a missing migration fails startup instead
+// of the first request touching the column. Extra columns are allowed.
+func (s *Store) CheckSchema(ctx context.Context) error {
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name IN ('runs', 'run_metrics')
+	`)
+	if err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+	defer rows.Close()
+	found := map[string]map[string]bool{}
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return fmt.Errorf("schema check: scan: %w", err)
+		}
+		if found[table] == nil {
+			found[table] = map[string]bool{}
+		}
+		found[table][strings.ToLower(column)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+	return compareSchema(expectedSchema, found)
+}
+
+// compareSchema reports every missing table and column in one error (tables in
+// name order, columns in expected order), or nil when found covers expected.
+func compareSchema(expected map[string][]string, found map[string]map[string]bool) error {
+	tables := make([]string, 0, len(expected))
+	for table := range expected {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var problems []string
+	for _, table := range tables {
+		columns, ok := found[table]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("table %s is missing", table))
+			continue
+		}
+		var missing []string
+		for _, column := range expected[table] {
+			if !columns[column] {
+				missing = append(missing, column)
+			}
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("table %s is missing columns %s", table, strings.Join(missing, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("schema check failed (apply infra/db/migrations): %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func splitColumns(list string) []string {
+	parts := strings.Split(list, ",")
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
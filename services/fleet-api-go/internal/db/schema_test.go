@@ -0,0 +1,75 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+// schemaColumns is a complete schema as information_schema.columns reports it.
+var schemaColumns = map[string][]string{
+	"runs": {
+		"id", "mode", "seed", "scale", "robots_count", "jobs_count", "scenario_hash", "scenario_hash_version",
+		"status", "error_message", "created_at", "started_at", "completed_at", "ga_params", "retry_of",
+		"experiment_id", "progress_pct", "replan_interval_s",
+	},
+	"run_metrics": {
+		"run_id", "on_time_rate", "total_distance", "avg_completion_time", "max_lateness",
+		"completed_jobs", "failed_jobs", "total_jobs", "created_at",
+	},
+}
+
+// schemaRows answers CheckSchema with schemaColumns, minus the columns in drop
+// ("table.column") and every column of a table in drop.
+func schemaRows(fake *dbtest.Fake, drop ...string) {
+	var rows [][]any
+	for table, columns := range schemaColumns {
+		for _, column := range columns {
+			if !slices.Contains(drop, table) && !slices.Contains(drop, table+"."+column) {
+				rows = append(rows, []any{table, strings.ToUpper(column)})
+			}
+		}
+	}
+	fake.Return("information_schema.columns", dbtest.Result{Columns: []string{"table_name", "column_name"}, Rows: rows})
+}
+
+func TestCheckSchemaPasses(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	schemaRows(fake)
+	if err := store.CheckSchema(context.Background()); err != nil {
+		t.Fatalf("CheckSchema: %v", err)
+	}
+}
+
+func TestCheckSchemaReportsMissingColumns(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	schemaRows(fake, "runs.progress_pct", "runs.experiment_id", "run_metrics.total_jobs")
+
+	err := store.CheckSchema(context.Background())
+	want := "schema check failed (apply infra/db/migrations): " +
+		"table run_metrics is missing columns total_jobs; table runs is missing columns experiment_id, progress_pct"
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v, want %q", err, want)
+	}
+}
+
+func TestCheckSchemaReportsMissingTable(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	schemaRows(fake, "run_metrics")
+	if err := store.CheckSchema(context.Background()); err == nil || !strings.Contains(err.Error(), "table run_metrics is missing") {
+		t.Fatalf("err = %v, want run_metrics reported missing", err)
+	}
+}
+
+func TestCheckSchemaQueryError(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	errDown := errors.New("connection refused")
+	fake.Return("information_schema.columns", dbtest.Result{Err: errDown})
+	if err := store.CheckSchema(context.Background()); !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want the query error wrapped", err)
+	}
+}
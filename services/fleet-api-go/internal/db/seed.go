@@ -0,0 +1,50 @@
+package db
+
+// File: internal/db/seed.go
+// Purpose: Bulk insert of synthetic completed runs for local development.
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// InsertCompletedRuns inserts runs that are already completed together with their
+// metrics, in one transaction. metrics[i] belongs to runs[i].
+func (s *Store) InsertCompletedRuns(ctx context.Context, runs []models.Run, metrics []models.RunMetrics) error {
+	if len(runs) != len(metrics) {
+		return fmt.Errorf("insert completed runs: %d runs but %d metrics", len(runs), len(metrics))
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, run := range runs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO runs (id, mode, seed, scale, robots_count, jobs_count, scenario_hash, scenario_hash_version, status, completed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'completed', UTC_TIMESTAMP())
+		`,
+			run.ID,
+			run.Mode,
+			run.Seed,
+			run.Scale,
+			run.RobotsCount,
+			run.JobsCount,
+			run.ScenarioHash,
+			run.ScenarioHashVersion,
+		); err != nil {
+			return fmt.Errorf("insert run: %w", err)
+		}
+		if err := upsertRunMetrics(ctx, tx, run.ID, metrics[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
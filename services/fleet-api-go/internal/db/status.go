@@ -0,0 +1,179 @@
+package db
+
+// File: internal/db/status.go
+// Purpose: Transactional run status updates (terminal transitions + metrics upsert).
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+// ErrNotFound is returned when a referenced row does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrRunNotStarted is returned (wrapped with the current status) when a status
+// update targets a run that already reached a terminal status.
+var ErrRunNotStarted = errors.New("run is not started")
+
+// ErrCompletedBeforeStart is returned when a status update's completed_at precedes the run's started_at.
+var ErrCompletedBeforeStart = errors.New("completed_at is before started_at")
+
+// ItemError identifies the batch item that caused a transaction to roll back.
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BulkUpdateRunStatus applies all updates in a single transaction. Only started
+// runs can be moved to a terminal status. If any item fails, nothing is committed
+// and an *ItemError names the offending item.
+// The returned runs carry the identifying fields needed to publish events.
+func (s *Store) BulkUpdateRunStatus(ctx context.Context, updates []models.RunStatusUpdate) ([]models.Run, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	runs := make([]models.Run, 0, len(updates))
+	for i, u := range updates {
+		var run models.Run
+		err := tx.QueryRowContext(ctx,
+			`SELECT id, mode, seed, scale, robots_count, jobs_count, scenario_hash, status, started_at FROM runs WHERE id = ? FOR UPDATE`,
+			u.ID,
+		).Scan(&run.ID, &run.Mode, &run.Seed, &run.Scale, &run.RobotsCount, &run.JobsCount, &run.ScenarioHash, &run.Status, &run.StartedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, &ItemError{Index: i, Err: ErrNotFound}
+			}
+			return nil, &ItemError{Index: i, Err: fmt.Errorf("select run: %w", err)}
+		}
+		// The row is locked, so a concurrent writer cannot finish the run between
+		// this check and the update.
+		if run.Status != "started" {
+			return nil, &ItemError{Index: i, Err: fmt.Errorf("%w (status %s)", ErrRunNotStarted, run.Status)}
+		}
+		// TIMESTAMP columns hold whole seconds, so compare at that precision. Runs
+		// without a started_at have nothing to compare against.
+		if u.CompletedAt != nil && run.StartedAt != nil && u.CompletedAt.Truncate(time.Second).Before(*run.StartedAt) {
+			return nil, &ItemError{Index: i, Err: ErrCompletedBeforeStart}
+		}
+
+		// Completed runs report 100% progress; other terminal statuses keep the last report.
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE runs SET status = ?, error_message = ?, completed_at = COALESCE(?, UTC_TIMESTAMP()),
+				progress_pct = IF(? = 'completed', 100, progress_pct)
+			WHERE id = ?`,
+			u.Status, u.ErrorMessage, u.CompletedAt, u.Status, u.ID,
+		); err != nil {
+			return nil, &ItemError{Index: i, Err: fmt.Errorf("update run status: %w", err)}
+		}
+
+		if u.Metrics != nil {
+			if err := upsertRunMetrics(ctx, tx, u.ID, *u.Metrics); err != nil {
+				return nil, &ItemError{Index: i, Err: err}
+			}
+		}
+
+		run.Status = u.Status
+		run.ErrorMessage = u.ErrorMessage
+		runs = append(runs, run)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return runs, nil
+}
+
+// CancelRun marks a started run as cancelled. The conditional update is atomic and
+// committed on return; it reports false when the run was not in started (already
+// terminal, or missing).
+func (s *Store) CancelRun(ctx context.Context, runID string, reason *string) (bool, error) {
+	res, err := s.q.ExecContext(ctx, `
+		UPDATE runs SET status = 'cancelled', error_message = ?, completed_at = UTC_TIMESTAMP()
+		WHERE id = ? AND status = 'started'
+	`, reason, runID)
+	if err != nil {
+		return false, fmt.Errorf("cancel run: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cancel run rows affected: %w", err)
+	}
+	return n == 1, nil
+}
+
+// UpdateRunProgress sets a started run's progress percentage. It reports false
+// when the run is missing or already terminal.
+func (s *Store) UpdateRunProgress(ctx context.Context, runID string, pct float64) (bool, error) {
+	res, err := s.q.ExecContext(ctx, `
+		UPDATE runs SET progress_pct = ? WHERE id = ? AND status = 'started'
+	`, pct, runID)
+	if err != nil {
+		return false, fmt.Errorf("update run progress: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("update run progress rows affected: %w", err)
+	}
+	return n == 1, nil
+}
+
+// upsertRunMetrics mirrors sim-runner's insert_metrics so either writer can own the row.
+// Like it, every write also appends a run_metrics_history row.
+func upsertRunMetrics(ctx context.Context, tx *sql.Tx, runID string, m models.RunMetrics) error {
+	query := `
+		INSERT INTO run_metrics (run_id, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			on_time_rate = VALUES(on_time_rate),
+			total_distance = VALUES(total_distance),
+			avg_completion_time = VALUES(avg_completion_time),
+			max_lateness = VALUES(max_lateness),
+			completed_jobs = VALUES(completed_jobs),
+			failed_jobs = VALUES(failed_jobs),
+			total_jobs = VALUES(total_jobs)
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		runID,
+		m.OnTimeRate,
+		m.TotalDistance,
+		m.AvgCompletionTime,
+		m.MaxLateness,
+		m.CompletedJobs,
+		m.FailedJobs,
+		m.TotalJobs,
+	); err != nil {
+		return fmt.Errorf("upsert run metrics: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO run_metrics_history (run_id, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		runID,
+		m.OnTimeRate,
+		m.TotalDistance,
+		m.AvgCompletionTime,
+		m.MaxLateness,
+		m.CompletedJobs,
+		m.FailedJobs,
+		m.TotalJobs,
+	); err != nil {
+		return fmt.Errorf("insert run metrics history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// lockedRun answers the FOR UPDATE select with a run in status.
+func lockedRun(status string) dbtest.Rule {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{args[0], "ga", 42, "small", nil, nil, "hash", status, started}}}
+	}
+}
+
+func TestBulkUpdateRunStatusAppliesStartedRuns(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("FOR UPDATE", lockedRun("started"))
+
+	runs, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "failed"},
+	})
+	if err != nil {
+		t.Fatalf("BulkUpdateRunStatus: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Status != "completed" || runs[1].Status != "failed" {
+		t.Fatalf("runs = %+v", runs)
+	}
+	if got := len(fake.Matching("UPDATE runs SET status")); got != 2 {
+		t.Errorf("status updates = %d, want 2", got)
+	}
+	if len(fake.Matching("COMMIT")) != 1 {
+		t.Error("transaction was not committed")
+	}
+}
+
+func TestBulkUpdateRunStatusRejectsRunsNotStarted(t *testing.T) {
+	for _, status := range []string{"completed", "failed", "stopped", "cancelled"} {
+		t.Run(status, func(t *testing.T) {
+			store, fake := dbtest.Open(t)
+			fake.On("FOR UPDATE", func(args []any) dbtest.Result {
+				if args[0] == "run-2" {
+					return lockedRun(status)(args)
+				}
+				return lockedRun("started")(args)
+			})
+
+			_, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{
+				{ID: "run-1", Status: "completed"},
+				{ID: "run-2", Status: "failed"},
+			})
+			var itemErr *db.ItemError
+			if !errors.As(err, &itemErr) || itemErr.Index != 1 {
+				t.Fatalf("err = %v, want an ItemError for item 1", err)
+			}
+			if !errors.Is(err, db.ErrRunNotStarted) || !strings.Contains(err.Error(), "status "+status) {
+				t.Errorf("err = %v, want ErrRunNotStarted naming status %s", err, status)
+			}
+			if got := len(fake.Matching("UPDATE runs SET status")); got != 1 {
+				t.Errorf("status updates = %d, want only item 0's", got)
+			}
+			if len(fake.Matching("COMMIT")) != 0 || len(fake.Matching("ROLLBACK")) != 1 {
+				t.Error("transaction was not rolled back")
+			}
+		})
+	}
+}
+
+func TestBulkUpdateRunStatusRejectsCompletedBeforeStart(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("FOR UPDATE", lockedRun("started"))
+
+	skewed := time.Date(2026, 1, 2, 3, 4, 4, 0, time.UTC) // a second before lockedRun's started_at
+	_, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "completed", CompletedAt: &skewed},
+	})
+	var itemErr *db.ItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, db.ErrCompletedBeforeStart) {
+		t.Fatalf("err = %v, want an ItemError for item 1 wrapping ErrCompletedBeforeStart", err)
+	}
+	if got := len(fake.Matching("UPDATE runs SET status")); got != 1 {
+		t.Errorf("status updates = %d, want only item 0's", got)
+	}
+	if len(fake.Matching("COMMIT")) != 0 || len(fake.Matching("ROLLBACK")) != 1 {
+		t.Error("transaction was not rolled back")
+	}
+}
+
+func TestBulkUpdateRunStatusAllowsSubSecondCompletion(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("FOR UPDATE", lockedRun("started"))
+
+	// started_at is stored in whole seconds, so a completion within the same second is not skew.
+	sameSecond := time.Date(2026, 1, 2, 3, 4, 5, 400_000_000, time.UTC)
+	if _, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed", CompletedAt: &sameSecond},
+	}); err != nil {
+		t.Fatalf("BulkUpdateRunStatus: %v", err)
+	}
+	if len(fake.Matching("COMMIT")) != 1 {
+		t.Error("transaction was not committed")
+	}
+}
+
+func TestBulkUpdateRunStatusNullStartedAt(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	fake.On("FOR UPDATE", func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{args[0], "ga", 42, "small", nil, nil, "hash", "started", nil}}}
+	})
+
+	// Without a started_at there is nothing to compare completed_at against.
+	completed := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed", CompletedAt: &completed},
+	})
+	if err != nil {
+		t.Fatalf("BulkUpdateRunStatus: %v", err)
+	}
+	if len(runs) != 1 || runs[0].StartedAt != nil {
+		t.Errorf("runs = %+v, want one with a nil started_at", runs)
+	}
+}
+
+func TestBulkUpdateRunStatusMissingRun(t *testing.T) {
+	store, _ := dbtest.Open(t)
+	_, err := store.BulkUpdateRunStatus(context.Background(), []models.RunStatusUpdate{{ID: "missing", Status: "completed"}})
+	if !errors.Is(err, db.ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+// File: internal/db/tags.go
+// Purpose: Persistence for run tags (run_tags table).
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// InsertRunTags attaches tags to a run. Call it in the transaction that inserts
+// the run so a run is never visible without its tags.
+func (s *Store) InsertRunTags(ctx context.Context, runID string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 2*len(tags))
+	for _, tag := range tags {
+		args = append(args, runID, tag)
+	}
+	query := `INSERT INTO run_tags (run_id, tag) VALUES ` + strings.TrimSuffix(strings.Repeat("(?, ?),", len(tags)), ",")
+	if _, err := s.q.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("insert run tags: %w", err)
+	}
+	return nil
+}
+
+// AttachRunTags fills Tags on each run from run_tags, sorted by tag. Runs without
+// tags get an empty (nil) list.
+func (s *Store) AttachRunTags(ctx context.Context, runs []models.Run) error {
+	if len(runs) == 0 {
+		return nil
+	}
+	args := make([]any, len(runs))
+	for i, run := range runs {
+		args[i] = run.ID
+	}
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT run_id, tag FROM run_tags
+		WHERE run_id IN (`+strings.TrimSuffix(strings.Repeat("?,", len(runs)), ",")+`)
+		ORDER BY run_id, tag
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("select run tags: %w", err)
+	}
+	defer rows.Close()
+
+	byRun := make(map[string][]string, len(runs))
+	for rows.Next() {
+		var runID, tag string
+		if err := rows.Scan(&runID, &tag); err != nil {
+			return fmt.Errorf("scan run tags: %w", err)
+		}
+		byRun[runID] = append(byRun[runID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate run tags: %w", err)
+	}
+	for i := range runs {
+		runs[i].Tags = byRun[runs[i].ID]
+	}
+	return nil
+}
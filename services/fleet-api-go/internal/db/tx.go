@@ -0,0 +1,37 @@
+package db
+
+// File: internal/db/tx.go
+// Purpose: Running several Store reads/writes in one transaction with caller-chosen isolation.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// querier is the query surface shared by *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn against a Store bound to a single transaction opened with opts
+// (isolation level, read-only). The transaction commits when fn returns nil and
+// rolls back otherwise. Methods that manage their own transaction
+// (BulkUpdateRunStatus, InsertCompletedRuns) must not be called from fn.
+func (s *Store) WithTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *Store) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(&Store{db: s.db, q: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"fleet-api-go/internal/db"
+	"fleet-api-go/internal/db/dbtest"
+)
+
+// txLog returns the recorded statements as BEGIN/COMMIT/ROLLBACK markers and
+// the queries in between.
+func txLog(fake *dbtest.Fake) []string {
+	var out []string
+	for _, st := range fake.Statements() {
+		out = append(out, st.Query)
+	}
+	return out
+}
+
+func TestWithTxCommits(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+	err := store.WithTx(context.Background(), opts, func(tx *db.Store) error {
+		_, err := tx.GetRun(context.Background(), "run-1")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	log := fake.Statements()
+	if len(log) != 3 || log[0].Query != "BEGIN" || log[2].Query != "COMMIT" {
+		t.Fatalf("statements = %v, want BEGIN, the read, COMMIT", txLog(fake))
+	}
+	if want := []any{sql.LevelRepeatableRead, true}; !reflect.DeepEqual(log[0].Args, want) {
+		t.Errorf("BEGIN options = %v, want %v", log[0].Args, want)
+	}
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	store, fake := dbtest.Open(t)
+	errStop := errors.New("stop")
+
+	err := store.WithTx(context.Background(), nil, func(tx *db.Store) error {
+		if _, err := tx.GetRun(context.Background(), "run-1"); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("err = %v, want fn's error unwrapped", err)
+	}
+	log := txLog(fake)
+	if len(log) != 3 || log[0] != "BEGIN" || log[2] != "ROLLBACK" {
+		t.Errorf("statements = %v, want BEGIN, the read, ROLLBACK", log)
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	store, fake := dbtest.Open(t)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want the panic to propagate", r)
+			}
+		}()
+		_ = store.WithTx(context.Background(), nil, func(*db.Store) error { panic("boom") })
+	}()
+	if log := txLog(fake); !reflect.DeepEqual(log, []string{"BEGIN", "ROLLBACK"}) {
+		t.Errorf("statements = %v, want BEGIN then ROLLBACK", log)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+// File: internal/handlers/admin.go
+// Purpose: HTTP handlers for operator/developer endpoints under /admin (seed, maintenance windows).
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/models"
+	"fleet-api-go/internal/services"
+)
+
+// adminRoutes lists operational endpoints enabled by feature flags. They are not versioned.
+func (h *Handler) adminRoutes() []route {
+	var routes []route
+	if h.opts.Features.Enabled(config.FeatureAdmin) {
+		routes = append(routes,
+			route{http.MethodGet, "/admin/maintenance", h.getMaintenance},
+			route{http.MethodPost, "/admin/maintenance", h.scheduleMaintenance},
+		)
+		if h.opts.Features.Enabled(config.FeatureDevSeed) {
+			routes = append(routes, route{http.MethodPost, "/admin/seed", h.seedDevData})
+		}
+	}
+	if h.opts.Features.Enabled(config.FeaturePprof) {
+		routes = append(routes, pprofRoutes()...)
+	}
+	return routes
+}
+
+// pprofRoutes exposes the net/http/pprof handlers. Index also serves the named
+// profiles (heap, goroutine, block, ...) under /debug/pprof/{name}.
+func pprofRoutes() []route {
+	return []route{
+		{http.MethodGet, "/debug/pprof/", pprof.Index},
+		{http.MethodGet, "/debug/pprof/cmdline", pprof.Cmdline},
+		{http.MethodGet, "/debug/pprof/profile", pprof.Profile},
+		{http.MethodGet, "/debug/pprof/symbol", pprof.Symbol},
+		{http.MethodPost, "/debug/pprof/symbol", pprof.Symbol},
+		{http.MethodGet, "/debug/pprof/trace", pprof.Trace},
+	}
+}
+
+func (h *Handler) seedDevData(w http.ResponseWriter, r *http.Request) {
+	count := 0
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid count"})
+			return
+		}
+		count = v
+	}
+	resp, err := h.runs.SeedDevData(r.Context(), count)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrDevModeDisabled):
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "dev mode is disabled; set DEV_MODE=true to seed data"})
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) getMaintenance(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.MaintenanceStatus(r.Context())
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) scheduleMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateMaintenanceWindowRequest
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	window, err := h.runs.ScheduleMaintenance(r.Context(), req)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, window)
+}
+
+// writeMaintenanceUnavailable answers a create rejected by a maintenance window with
+// 503, the window's end in the body and a Retry-After header in seconds.
+func writeMaintenanceUnavailable(w http.ResponseWriter, err error) {
+	var maint *services.MaintenanceError
+	if !errors.As(err, &maint) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": err.Error()})
+		return
+	}
+	retryAfter := int(math.Ceil(time.Until(maint.Window.EndsAt).Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+		"error":   err.Error(),
+		"ends_at": maint.Window.EndsAt,
+	})
+}
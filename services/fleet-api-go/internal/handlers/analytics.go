@@ -0,0 +1,166 @@
+package handlers
+
+// File: internal/handlers/analytics.go
+// Purpose: HTTP handlers for aggregate views (/runs/trends, /runs/leaderboard, /scenarios, /scenarios/improvements, /scenarios/matrix).
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fleet-api-go/internal/models"
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) runTrends(w http.ResponseWriter, r *http.Request) {
+	var seed *int
+	if seedRaw := r.URL.Query().Get("seed"); seedRaw != "" {
+		v, err := strconv.Atoi(seedRaw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid seed"})
+			return
+		}
+		seed = &v
+	}
+	tr, err := parseTimeRange(r, time.Now().UTC())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	resp, err := h.runs.Trends(r.Context(), seed, r.URL.Query().Get("scale"), tr)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) listScenarios(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	tr, err := parseTimeRange(r, time.Now().UTC())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	resp, err := h.runs.ListScenarios(r.Context(), tr, limit, offset)
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) listScenarioImprovements(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	resp, err := h.runs.ListScenarioImprovements(r.Context(), limit, offset)
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) scenarioMatrix(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	seed, err := strconv.Atoi(q.Get("seed"))
+	if err != nil || q.Get("scale") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "seed and scale query params are required"})
+		return
+	}
+	var robots, jobs *int
+	if raw := q.Get("robots"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid robots"})
+			return
+		}
+		robots = &v
+	}
+	if raw := q.Get("jobs"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid jobs"})
+			return
+		}
+		jobs = &v
+	}
+	resp, err := h.runs.ScenarioMatrix(r.Context(), seed, q.Get("scale"), robots, jobs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrScaleNotAllowed):
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error()})
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// runLeaderboard serves GET /runs/leaderboard. Any min_<metric> / max_<metric> query
+// param becomes an inclusive bound on that metric.
+func (h *Handler) runLeaderboard(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	q := models.LeaderboardQuery{
+		Metric: params.Get("metric"),
+		Mode:   params.Get("mode"),
+		Scale:  params.Get("scale"),
+	}
+	if raw := params.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid limit"})
+			return
+		}
+		q.Limit = v
+	}
+	for key := range params {
+		var name string
+		var bounds *map[string]float64
+		switch {
+		case strings.HasPrefix(key, "min_"):
+			name, bounds = strings.TrimPrefix(key, "min_"), &q.Min
+		case strings.HasPrefix(key, "max_"):
+			name, bounds = strings.TrimPrefix(key, "max_"), &q.Max
+		default:
+			continue
+		}
+		v, err := strconv.ParseFloat(params.Get(key), 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid " + key})
+			return
+		}
+		if *bounds == nil {
+			*bounds = map[string]float64{}
+		}
+		(*bounds)[name] = v
+	}
+	resp, err := h.runs.Leaderboard(r.Context(), q, params.Get("direction"))
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
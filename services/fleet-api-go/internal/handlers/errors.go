@@ -0,0 +1,32 @@
+package handlers
+
+// File: internal/handlers/errors.go
+// Purpose: Internal-error responses that log details server-side instead of returning them.
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// internalErrorBody is returned for unexpected failures unless DebugErrors is set.
+// "error" is kept alongside code/message for clients that read the usual error shape.
+var internalErrorBody = map[string]any{
+	"error":   "internal error",
+	"code":    "INTERNAL",
+	"message": "internal error",
+}
+
+// writeInternalError logs err with request context and writes a 500. The raw error
+// reaches the client only when DebugErrors is enabled.
+func (h *Handler) writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.Error("request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	if h.opts.DebugErrors {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error":   err.Error(),
+			"code":    "INTERNAL",
+			"message": err.Error(),
+		})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, internalErrorBody)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+// File: internal/handlers/events.go
+// Purpose: HTTP handlers for a run's event history (/runs/{id}/events) and lifecycle timeline.
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) listRunEvents(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	tail := 0
+	if raw := r.URL.Query().Get("tail"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 || v > maxPageLimit {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid tail"})
+			return
+		}
+		if r.URL.Query().Has("offset") {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "tail and offset are mutually exclusive"})
+			return
+		}
+		tail = v
+	}
+	resp, err := h.runs.ListRunEvents(r.Context(), r.PathValue("id"), limit, offset, tail)
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) getRunTimeline(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.Timeline(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+// File: internal/handlers/experiments.go
+// Purpose: HTTP handlers for experiments (/experiments).
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-api-go/internal/models"
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) createExperiment(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateExperimentRequest
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	exp, err := h.runs.CreateExperiment(r.Context(), req)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, exp)
+}
+
+func (h *Handler) getExperiment(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.GetExperiment(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrExperimentNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
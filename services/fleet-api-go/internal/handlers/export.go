@@ -0,0 +1,30 @@
+package handlers
+
+// File: internal/handlers/export.go
+// Purpose: HTTP handler for exporting a run's full record (/runs/{id}/export).
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) exportRun(w http.ResponseWriter, r *http.Request) {
+	if format := r.URL.Query().Get("format"); format != "" && format != "json" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "format must be json"})
+		return
+	}
+	runID := r.PathValue("id")
+	export, err := h.runs.ExportRun(r.Context(), runID)
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="run-`+runID+`.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
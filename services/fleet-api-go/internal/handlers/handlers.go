@@ -2,62 +2,321 @@
 package handlers
 
 // File: internal/handlers/handlers.go
-// Purpose: HTTP handlers for /runs, /metrics, /compare, /health.
+// Purpose: HTTP handlers for /runs, /metrics, /compare, /republish, /health, /version.
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"fleet-api-go/internal/config"
 	"fleet-api-go/internal/models"
 	"fleet-api-go/internal/services"
 )
 
+// Options configures optional route groups.
+type Options struct {
+	// Features gates optional route groups (admin, dev_seed, pprof).
+	Features config.Features
+	// DebugErrors returns raw internal error messages to clients instead of a generic body.
+	DebugErrors bool
+	// SeparateAdmin keeps admin routes off Register; they are served through
+	// RegisterAdmin on a dedicated listener instead.
+	SeparateAdmin bool
+}
+
 // Handler groups HTTP handlers for run operations.
 type Handler struct {
 	runs *services.RunService
+	opts Options
 }
 
 // New returns a Handler wired to a RunService.
-func New(runService *services.RunService) *Handler {
-	return &Handler{runs: runService}
+func New(runService *services.RunService, opts Options) *Handler {
+	return &Handler{runs: runService, opts: opts}
+}
+
+// route is one method+path binding within an API version.
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
 }
 
-// Register attaches routes to the provided ServeMux.
+// Register attaches routes to the provided ServeMux. API routes are mounted under
+// their version prefix (/v1/...); unversioned paths remain as deprecated aliases of v1.
+// Operational endpoints such as /health, /version and /status are unversioned.
 func (h *Handler) Register(mux *http.ServeMux) {
 	mux.HandleFunc("GET /health", h.health)
-	mux.HandleFunc("POST /runs", h.createRun)
-	mux.HandleFunc("GET /runs/{id}", h.getRun)
-	mux.HandleFunc("GET /runs/{id}/metrics", h.getMetrics)
-	mux.HandleFunc("GET /runs/compare", h.compareRuns)
+	mux.HandleFunc("GET /version", h.version)
+	mux.HandleFunc("GET /status", h.status)
+	if !h.opts.SeparateAdmin {
+		registerRoutes(mux, "", h.adminRoutes())
+	}
+
+	v1 := h.v1Routes()
+	registerRoutes(mux, "/v1", v1)
+	registerDeprecatedAliases(mux, "v1", v1)
+}
+
+// RegisterAdmin attaches only the admin routes, for a listener separate from the public API.
+func (h *Handler) RegisterAdmin(mux *http.ServeMux) {
+	registerRoutes(mux, "", h.adminRoutes())
+}
+
+// v1Routes lists the routes of API version 1. A future /v2 gets its own list.
+func (h *Handler) v1Routes() []route {
+	return []route{
+		{http.MethodPost, "/runs", h.createRun},
+		{http.MethodPost, "/runs/wait", h.createRunAndWait},
+		{http.MethodGet, "/runs", h.listRuns},
+		{http.MethodPatch, "/runs/status", h.bulkUpdateStatus},
+		{http.MethodGet, "/runs/metrics", h.getBulkMetrics},
+		{http.MethodGet, "/runs/{id}", h.getRun},
+		{http.MethodGet, "/runs/{id}/status", h.getRunStatus},
+		{http.MethodPost, "/runs/{id}/republish", h.republishRun},
+		{http.MethodPost, "/runs/{id}/clone", h.cloneRun},
+		{http.MethodPost, "/runs/{id}/retry", h.retryRun},
+		{http.MethodGet, "/runs/{id}/retries", h.getRunRetries},
+		{http.MethodPost, "/runs/{id}/cancel", h.cancelRun},
+		{http.MethodPatch, "/runs/{id}/progress", h.updateRunProgress},
+		{http.MethodGet, "/runs/{id}/metrics", h.getMetrics},
+		{http.MethodGet, "/runs/{id}/metrics/history", h.getRunMetricsHistory},
+		{http.MethodGet, "/runs/{id}/metrics/normalized", h.getNormalizedMetrics},
+		{http.MethodGet, "/runs/{id}/peer", h.getPeer},
+		{http.MethodGet, "/runs/{id}/delta", h.getRunDelta},
+		{http.MethodGet, "/runs/{id}/repro", h.getRunRepro},
+		{http.MethodGet, "/runs/{id}/export", h.exportRun},
+		{http.MethodGet, "/runs/{id}/events", h.listRunEvents},
+		{http.MethodGet, "/runs/{id}/timeline", h.getRunTimeline},
+		{http.MethodPost, "/runs/{id}/notes", h.addRunNote},
+		{http.MethodGet, "/runs/{id}/notes", h.listRunNotes},
+		{http.MethodGet, "/runs/compare", h.compareRuns},
+		{http.MethodGet, "/runs/trends", h.runTrends},
+		{http.MethodGet, "/runs/leaderboard", h.runLeaderboard},
+		{http.MethodGet, "/scenarios", h.listScenarios},
+		{http.MethodGet, "/scenarios/improvements", h.listScenarioImprovements},
+		{http.MethodGet, "/scenarios/matrix", h.scenarioMatrix},
+		{http.MethodPost, "/experiments", h.createExperiment},
+		{http.MethodGet, "/experiments/{id}", h.getExperiment},
+	}
+}
+
+// registerRoutes mounts routes under prefix (e.g. "/v1"); an empty prefix mounts them at the root.
+func registerRoutes(mux *http.ServeMux, prefix string, routes []route) {
+	for _, rt := range routes {
+		mux.HandleFunc(rt.method+" "+prefix+rt.path, rt.handler)
+	}
+}
+
+// registerDeprecatedAliases serves routes at their unversioned path, marking responses
+// with Deprecation and a successor-version Link so clients can migrate.
+func registerDeprecatedAliases(mux *http.ServeMux, version string, routes []route) {
+	for _, rt := range routes {
+		next := rt.handler
+		mux.HandleFunc(rt.method+" "+rt.path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			// r.URL.Path has had API_BASE_PATH stripped; the successor keeps it in front.
+			base := strings.TrimSuffix(originalPath(r), r.URL.Path)
+			w.Header().Set("Link", "<"+base+"/"+version+r.URL.Path+">; rel=\"successor-version\"")
+			next(w, r)
+		})
+	}
 }
 
 func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
 	if err := h.runs.Health(r.Context()); err != nil {
-		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "unhealthy", "error": err.Error()})
+		slog.Error("health check failed", "error", err)
+		msg := "database unavailable"
+		if h.opts.DebugErrors {
+			msg = err.Error()
+		}
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "unhealthy", "error": msg})
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
 }
 
+func (h *Handler) version(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.runs.Version())
+}
+
+func (h *Handler) status(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.Status(r.Context())
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *Handler) createRun(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateRunRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid JSON body"})
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
 		return
 	}
 	resp, err := h.runs.CreateRun(r.Context(), req)
 	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		h.writeCreateRunError(w, r, err)
 		return
 	}
 	writeJSON(w, http.StatusCreated, resp)
 }
 
+// createRunAndWait creates a run and holds the request until it finishes or ?timeout
+// (seconds, capped at RUN_WAIT_MAX_S) elapses: 200 with the final run and metrics,
+// or 202 with the run ID while it is still running or when reading it failed.
+func (h *Handler) createRunAndWait(w http.ResponseWriter, r *http.Request) {
+	var requested time.Duration
+	if raw := r.URL.Query().Get("timeout"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid timeout: expected a positive number of seconds"})
+			return
+		}
+		requested = time.Duration(v) * time.Second
+	}
+	var req models.CreateRunRequest
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	timeout := h.runs.RunWaitTimeout(requested)
+	// The server's write timeout is shorter than most waits; extend it for this response.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 10*time.Second))
+
+	resp, err := h.runs.CreateRunAndWait(r.Context(), req, timeout)
+	if err != nil {
+		if r.Context().Err() != nil {
+			return // client went away; nobody to answer
+		}
+		if resp == nil {
+			h.writeCreateRunError(w, r, err)
+			return
+		}
+		// The run was created; hand out its ID instead of failing the whole request.
+		slog.Error("run wait read failed", "run_id", resp.RunID, "error", err)
+		resp.Error = "could not read the run's outcome; poll GET /runs/{id}/status"
+		if h.opts.DebugErrors {
+			resp.Error = err.Error()
+		}
+		writeJSON(w, http.StatusAccepted, resp)
+		return
+	}
+	if resp.TimedOut {
+		writeJSON(w, http.StatusAccepted, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// writeCreateRunError maps run-creation errors to their status codes.
+func (h *Handler) writeCreateRunError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrMaintenanceWindow):
+		writeMaintenanceUnavailable(w, err)
+	case errors.Is(err, services.ErrTooManyActiveRuns):
+		writeJSON(w, http.StatusTooManyRequests, map[string]any{"error": err.Error()})
+	case errors.Is(err, services.ErrFleetTooManyRobots), errors.Is(err, services.ErrScaleNotAllowed),
+		errors.Is(err, services.ErrGAParamsRequireGA), errors.Is(err, services.ErrReplanIntervalRequiresGA),
+		errors.Is(err, services.ErrExperimentNotFound):
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error()})
+	case services.IsValidation(err):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+	default:
+		h.writeInternalError(w, r, err)
+	}
+}
+
+func (h *Handler) bulkUpdateStatus(w http.ResponseWriter, r *http.Request) {
+	var updates []models.RunStatusUpdate
+	if !decodeJSONBody(w, r, &updates, "invalid JSON body: expected an array of status updates") {
+		return
+	}
+	resp, err := h.runs.BulkUpdateStatus(r.Context(), updates)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotStarted):
+			writeJSON(w, http.StatusConflict, resp)
+		case errors.Is(err, services.ErrBatchRejected):
+			writeJSON(w, http.StatusUnprocessableEntity, resp)
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("ids") {
+		h.getRunsByIDs(w, r)
+		return
+	}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	filter := models.RunFilter{
+		Status:       r.URL.Query().Get("status"),
+		ExperimentID: r.URL.Query().Get("experiment_id"),
+		Tags:         r.URL.Query()["tag"],
+	}
+	filter.Created, err = parseTimeRange(r, time.Now().UTC())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	if raw := r.URL.Query().Get("has_error"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid has_error"})
+			return
+		}
+		filter.HasError = &v
+	}
+	resp, err := h.runs.ListRuns(r.Context(), filter, limit, offset)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// getRunsByIDs serves GET /runs?ids=...; filters and pagination do not apply.
+func (h *Handler) getRunsByIDs(w http.ResponseWriter, r *http.Request) {
+	ids := strings.FieldsFunc(r.URL.Query().Get("ids"), func(c rune) bool { return c == ',' || c == ' ' })
+	resp, err := h.runs.GetRunsByIDs(r.Context(), ids)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) {
 	run, err := h.runs.GetRun(r.Context(), r.PathValue("id"))
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		h.writeInternalError(w, r, err)
 		return
 	}
 	if run == nil {
@@ -67,20 +326,320 @@ func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, run)
 }
 
+func (h *Handler) getRunStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := h.runs.GetRunStatus(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	if st == nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "run not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (h *Handler) republishRun(w http.ResponseWriter, r *http.Request) {
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid force"})
+			return
+		}
+		force = v
+	}
+	run, err := h.runs.RepublishRun(r.Context(), r.PathValue("id"), force)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		case errors.Is(err, services.ErrRunTerminal):
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusAccepted, models.RepublishRunResponse{
+		RunID:      run.ID,
+		Status:     run.Status,
+		RoutingKey: "run.created",
+		Forced:     force,
+	})
+}
+
+func (h *Handler) cloneRun(w http.ResponseWriter, r *http.Request) {
+	var req models.CloneRunRequest
+	// The body is optional; without one the clone keeps every original parameter.
+	if r.ContentLength != 0 && !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	resp, err := h.runs.CloneRun(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		default:
+			h.writeCreateRunError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) retryRun(w http.ResponseWriter, r *http.Request) {
+	force := false
+	if raw := r.URL.Query().Get("force"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid force"})
+			return
+		}
+		force = v
+	}
+	resp, err := h.runs.RetryRun(r.Context(), r.PathValue("id"), force)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		case errors.Is(err, services.ErrRunNotFailed):
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		default:
+			h.writeCreateRunError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (h *Handler) getRunRetries(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.Retries(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) cancelRun(w http.ResponseWriter, r *http.Request) {
+	var req models.CancelRunRequest
+	if r.ContentLength != 0 && !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	resp, err := h.runs.CancelRun(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		case errors.Is(err, services.ErrRunTerminal):
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) updateRunProgress(w http.ResponseWriter, r *http.Request) {
+	var req models.RunProgressUpdate
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	st, err := h.runs.UpdateRunProgress(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		case errors.Is(err, services.ErrRunTerminal):
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error()})
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// metricsNegotiation serves JSON by default and the Prometheus text format for
+// ?format=prometheus or Accept: text/plain (what Prometheus scrapers send).
+var metricsNegotiation = negotiation{
+	Default: "json",
+	Formats: []string{"json", "prometheus"},
+	Media:   map[string]string{"application/json": "json", "text/plain": "prometheus"},
+}
+
 func (h *Handler) getMetrics(w http.ResponseWriter, r *http.Request) {
-	metrics, err := h.runs.GetMetrics(r.Context(), r.PathValue("id"))
+	w.Header().Add("Vary", "Accept")
+	format, err := metricsNegotiation.negotiate(r)
 	if err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+		writeNegotiationError(w, err)
+		return
+	}
+	metrics, err := h.runs.GetMetrics(r.Context(), r.PathValue("id"), r.URL.Query().Get("thresholds"))
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
 		return
 	}
 	if metrics == nil {
 		writeJSON(w, http.StatusNotFound, map[string]any{"error": "metrics not found"})
 		return
 	}
+	// Completed-run metrics are immutable, so they can be served conditionally.
+	// The ETag covers the whole response, threshold verdict included; Last-Modified
+	// only dates the metrics, so it is not trusted once thresholds were evaluated.
+	if metrics.RunStatus == "completed" && !metrics.ComputedAt.IsZero() {
+		etag, err := metricsETag(format, metrics)
+		if err != nil {
+			h.writeInternalError(w, r, err)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", metrics.ComputedAt.UTC().Format(http.TimeFormat))
+		if metricsNotModified(r, etag, metrics) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	if format == "prometheus" {
+		writePrometheusMetrics(w, metrics)
+		return
+	}
 	writeJSON(w, http.StatusOK, metrics)
 }
 
+// getBulkMetrics serves GET /runs/metrics?ids=a,b,c.
+func (h *Handler) getBulkMetrics(w http.ResponseWriter, r *http.Request) {
+	ids := strings.FieldsFunc(r.URL.Query().Get("ids"), func(c rune) bool { return c == ',' || c == ' ' })
+	resp, err := h.runs.GetMetricsByIDs(r.Context(), ids)
+	if err != nil {
+		if services.IsValidation(err) {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// metricsETag is a weak validator over everything GET /runs/{id}/metrics returns
+// for m in format, so a different threshold spec or verdict gets a different tag.
+func metricsETag(format string, m *models.RunMetrics) (string, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("encode metrics etag: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(format+"\n"), body...))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// metricsNotModified applies If-None-Match against etag and, when the request
+// has none, If-Modified-Since against computed_at. The date alone cannot tell
+// threshold verdicts apart, so it is ignored for responses that carry one.
+func metricsNotModified(r *http.Request, etag string, m *models.RunMetrics) bool {
+	if raw := r.Header.Get("If-None-Match"); raw != "" {
+		return etagMatches(raw, etag)
+	}
+	return m.Passed == nil && notModifiedSince(r, m.ComputedAt)
+}
+
+// etagMatches reports whether an If-None-Match list names etag, comparing weakly.
+func etagMatches(header, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedSince reports whether the request's If-Modified-Since covers t.
+// HTTP dates have second precision, so t is truncated before comparing.
+func notModifiedSince(r *http.Request, t time.Time) bool {
+	raw := r.Header.Get("If-Modified-Since")
+	if raw == "" {
+		return false
+	}
+	since, err := http.ParseTime(raw)
+	if err != nil {
+		return false
+	}
+	return !t.Truncate(time.Second).After(since)
+}
+
+func (h *Handler) getPeer(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.Peer(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) || errors.Is(err, services.ErrPeerNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) getRunRepro(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.Repro(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeInternalError(w, r, err)
+		return
+	}
+	if resp == nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "run not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) getRunDelta(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.RunDelta(r.Context(), r.PathValue("id"), r.URL.Query().Get("against"))
+	if err != nil {
+		switch {
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		case errors.Is(err, services.ErrMetricsNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// compareNegotiation serves nested JSON by default, the flat rows as JSON (?format=flat
+// only) or CSV (Accept: text/csv or ?format=csv).
+var compareNegotiation = negotiation{
+	Default: "nested",
+	Formats: []string{"nested", "flat", "csv"},
+	Media:   map[string]string{"application/json": "nested", "text/csv": "csv"},
+}
+
 func (h *Handler) compareRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+	format, err := compareNegotiation.negotiate(r)
+	if err != nil {
+		writeNegotiationError(w, err)
+		return
+	}
 	seedRaw := r.URL.Query().Get("seed")
 	scale := r.URL.Query().Get("scale")
 	if seedRaw == "" || scale == "" {
@@ -113,16 +672,83 @@ func (h *Handler) compareRuns(w http.ResponseWriter, r *http.Request) {
 		jobs = &v
 	}
 
-	resp, err := h.runs.Compare(r.Context(), seed, scale, robots, jobs)
+	minRuns := 0
+	if raw := r.URL.Query().Get("min_runs"); raw != "" {
+		v, parseErr := strconv.Atoi(raw)
+		if parseErr != nil || v < 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid min_runs: must be >= 1"})
+			return
+		}
+		minRuns = v
+	}
+
+	baselineAvg, err := parseBaselineAvg(r)
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
 		return
 	}
-	writeJSON(w, http.StatusOK, resp)
+
+	resp, err := h.runs.Compare(r.Context(), seed, scale, robots, jobs, r.URL.Query().Get("weights"), minRuns, baselineAvg)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrScaleNotAllowed):
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": err.Error()})
+		case services.IsValidation(err):
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	switch format {
+	case "flat":
+		writeJSON(w, http.StatusOK, services.FlattenCompare(resp))
+	case "csv":
+		rows := services.FlattenCompare(resp)
+		records := make([][]string, 0, len(rows))
+		for _, row := range rows {
+			records = append(records, []string{
+				row.Metric,
+				formatOptionalFloat(row.Baseline),
+				formatOptionalFloat(row.GA),
+				formatOptionalFloat(row.DeltaPct),
+			})
+		}
+		writeCSV(w, http.StatusOK, []string{"metric", "baseline", "ga", "delta_pct"}, records)
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
 }
 
+// decodeJSONBody decodes the request body into dst. On failure it writes 413 for
+// bodies over the size limit, 400 for corrupt gzip, and 400 with invalidMsg otherwise.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst any, invalidMsg string) bool {
+	err := json.NewDecoder(r.Body).Decode(dst)
+	if err == nil {
+		return true
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{"error": fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)})
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid gzip body"})
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": invalidMsg})
+	}
+	return false
+}
+
+// writeJSON marshals payload before writing anything, so an encoding failure becomes
+// a 500 rather than a truncated body under the intended status.
 func writeJSON(w http.ResponseWriter, status int, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("encode response", "status", status, "error", err)
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(internalErrorBody)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(payload)
+	_, _ = w.Write(append(body, '\n'))
 }
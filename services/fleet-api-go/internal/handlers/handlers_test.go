@@ -0,0 +1,1665 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	httpx "fleet-api-go/internal/http"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+	"fleet-api-go/internal/mq"
+	"fleet-api-go/internal/services"
+)
+
+// discardPublisher accepts every event without sending it.
+type discardPublisher struct{}
+
+func (discardPublisher) PublishContext(context.Context, string, map[string]any) error { return nil }
+func (discardPublisher) RecordRetry()                                                 {}
+func (discardPublisher) Stats() mq.Stats                                              { return mq.Stats{} }
+
+// newTestAPI serves the v1 routes over a RunService backed by the dbtest fake.
+func newTestAPI(t *testing.T, opts Options) (http.Handler, *dbtest.Fake) {
+	t.Helper()
+	h, fake := newTestHandler(t, opts)
+	return httpx.NewRouter(h.Register, httpx.Options{}), fake
+}
+
+// newTestHandler builds a Handler over a fake store, for tests that mount its
+// public and admin routes on separate routers.
+func newTestHandler(t *testing.T, opts Options) (*Handler, *dbtest.Fake) {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	cfg.AuditLog = "off"
+	store, fake := dbtest.Open(t)
+	return New(services.NewRunService(cfg, store, discardPublisher{}), opts), fake
+}
+
+// serve sends a request with an optional JSON body and returns the recorded response.
+func serve(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeprecatedAliasSuccessorLinkKeepsBasePath(t *testing.T) {
+	routes := []route{{http.MethodGet, "/runs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{})
+	}}}
+	for _, tc := range []struct {
+		basePath, path, want string
+	}{
+		{"", "/runs", `</v1/runs>; rel="successor-version"`},
+		{"/api", "/api/runs", `</api/v1/runs>; rel="successor-version"`},
+	} {
+		router := httpx.NewRouter(func(mux *http.ServeMux) {
+			registerDeprecatedAliases(mux, "v1", routes)
+		}, httpx.Options{BasePath: tc.basePath})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", tc.path, rec.Code)
+		}
+		if got := rec.Header().Get("Link"); got != tc.want {
+			t.Errorf("%s: Link = %q, want %q", tc.path, got, tc.want)
+		}
+		if got := rec.Header().Get("Deprecation"); got != "true" {
+			t.Errorf("%s: Deprecation = %q, want true", tc.path, got)
+		}
+	}
+}
+
+func TestBulkUpdateStatusFinishedRunIsConflict(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.On("FOR UPDATE", func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{args[0], "baseline", 42, "small", nil, nil, "hash", "completed", time.Now()}}}
+	})
+
+	rec := serve(t, api, http.MethodPatch, "/v1/runs/status", `[{"id":"run-1","status":"failed"}]`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status %d, want 409: %s", rec.Code, rec.Body)
+	}
+	var resp models.BulkStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Applied || resp.Results[0].Result != "rejected" {
+		t.Errorf("resp = %+v, want the item rejected", resp)
+	}
+}
+
+func TestBulkUpdateStatusCompletedBeforeStartIs422(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FOR UPDATE", func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{args[0], "baseline", 42, "small", nil, nil, "hash", "started", started}}}
+	})
+
+	rec := serve(t, api, http.MethodPatch, "/v1/runs/status",
+		`[{"id":"run-1","status":"completed","completed_at":"2026-01-02T03:00:00Z"}]`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status %d, want 422: %s", rec.Code, rec.Body)
+	}
+	var resp models.BulkStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Applied || resp.Results[0].Result != "rejected" || resp.Results[0].Error != "completed_at is before started_at" {
+		t.Errorf("resp = %+v, want the item rejected for skew", resp)
+	}
+}
+
+func TestCreateRunAndWaitReadFailureKeepsRunID(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      Options
+		wantError string
+	}{
+		{"generic", Options{}, "could not read the run's outcome; poll GET /runs/{id}/status"},
+		{"debug", Options{DebugErrors: true}, "select run status: connection reset"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, fake := newTestAPI(t, tc.opts)
+			fake.Return("SELECT id, status, error_message, progress_pct FROM runs", dbtest.Result{Err: errors.New("connection reset")})
+
+			rec := serve(t, api, http.MethodPost, "/v1/runs/wait?timeout=5", `{"mode":"baseline"}`)
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("status %d, want 202: %s", rec.Code, rec.Body)
+			}
+			var resp models.RunWaitResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.RunID == "" || resp.Status != "started" || resp.TimedOut {
+				t.Errorf("resp = %+v, want the created run still started", resp)
+			}
+			if resp.Error != tc.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tc.wantError)
+			}
+			if got := len(fake.Matching("INSERT INTO runs")); got != 1 {
+				t.Errorf("run inserts = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestListRunsRepeatedTagNoMatchIsEmptyList(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs?tag=owner:alice&tag=exp:q3", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"runs":[]`) || !strings.Contains(body, `"total":0`) {
+		t.Errorf("body = %s, want an empty page", body)
+	}
+	count := fake.Matching("SELECT COUNT(*) FROM runs")
+	if len(count) != 1 || strings.Count(count[0].Query, "EXISTS") != 2 {
+		t.Fatalf("count queries = %+v, want one with an EXISTS per tag", count)
+	}
+	if args := count[0].Args; len(args) != 2 || args[0] != "exp:q3" || args[1] != "owner:alice" {
+		t.Errorf("tag args = %v, want both tags", args)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs?tag=exp%20q3", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid tag: status %d, want 400", rec.Code)
+	}
+}
+
+func TestListRunsTimeRange(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+	before := time.Now().UTC().Add(-7 * 24 * time.Hour)
+	rec := serve(t, api, http.MethodGet, "/v1/runs?last=7d", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	count := fake.Matching("SELECT COUNT(*) FROM runs")
+	if len(count) != 1 || !strings.Contains(count[0].Query, "created_at >= ?") || len(count[0].Args) != 1 {
+		t.Fatalf("count queries = %+v, want one bounded on created_at", count)
+	}
+	if from, ok := count[0].Args[0].(time.Time); !ok || from.Before(before) || from.After(before.Add(time.Minute)) {
+		t.Errorf("from = %v, want about %v", count[0].Args[0], before)
+	}
+
+	for _, query := range []string{
+		"last=7d&from=2026-01-01T00:00:00Z",
+		"to=yesterday",
+		"from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+		"last=7m",
+	} {
+		if rec := serve(t, api, http.MethodGet, "/v1/runs?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, rec.Code)
+		}
+	}
+}
+
+func TestGetMetricsConditionalCoversThresholds(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	computed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.8, 200.0, 30.0, 5.0, 16, 4, 20, computed, "completed"},
+	}})
+	get := func(query string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/runs/run-1/metrics"+query, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+
+	pass := get("?thresholds=on_time_rate>=0.5", nil)
+	fail := get("?thresholds=on_time_rate>=0.9", nil)
+	if pass.Code != http.StatusOK || fail.Code != http.StatusOK {
+		t.Fatalf("status %d/%d, want 200", pass.Code, fail.Code)
+	}
+	passTag, failTag := pass.Header().Get("ETag"), fail.Header().Get("ETag")
+	if passTag == "" || passTag == failTag {
+		t.Fatalf("ETags %q / %q, want distinct tags per verdict", passTag, failTag)
+	}
+
+	since := computed.Add(time.Hour).Format(http.TimeFormat)
+	for _, tc := range []struct {
+		name, query string
+		header      http.Header
+		want        int
+	}{
+		{"matching etag", "?thresholds=on_time_rate>=0.9", http.Header{"If-None-Match": {failTag}}, http.StatusNotModified},
+		{"etag from another spec", "?thresholds=on_time_rate>=0.9", http.Header{"If-None-Match": {passTag}}, http.StatusOK},
+		{"date with thresholds", "?thresholds=on_time_rate>=0.9", http.Header{"If-Modified-Since": {since}}, http.StatusOK},
+		{"date without thresholds", "", http.Header{"If-Modified-Since": {since}}, http.StatusNotModified},
+		{"etag wins over date", "", http.Header{"If-None-Match": {passTag}, "If-Modified-Since": {since}}, http.StatusOK},
+	} {
+		if rec := get(tc.query, tc.header); rec.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestCreateRunActiveRunLimitIs429(t *testing.T) {
+	t.Setenv("MAX_ACTIVE_RUNS", "2")
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("WHERE status = 'started'", dbtest.Result{Rows: [][]any{{2}}})
+
+	rec := serve(t, api, http.MethodPost, "/v1/runs", `{"mode":"baseline"}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429: %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "too many active runs: 2 active (max 2)") {
+		t.Errorf("body = %s", rec.Body)
+	}
+}
+
+func TestCreateRunReportsEffectiveFleet(t *testing.T) {
+	api, _ := newTestAPI(t, Options{})
+	for _, tc := range []struct {
+		body string
+		want string
+	}{
+		{`{"mode":"baseline","scale":"small"}`, `"effective":{"robots":5,"jobs":25,"source":"preset"}`},
+		{`{"mode":"baseline","robots":3,"jobs":9}`, `"effective":{"robots":3,"jobs":9,"source":"override"}`},
+	} {
+		rec := serve(t, api, http.MethodPost, "/v1/runs", tc.body)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("%s: status %d, want 201: %s", tc.body, rec.Code, rec.Body)
+		}
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Errorf("%s: body %s, want %s", tc.body, rec.Body, tc.want)
+		}
+	}
+}
+
+func TestCreateRunGAParamsStatusCodes(t *testing.T) {
+	api, _ := newTestAPI(t, Options{})
+	for _, tc := range []struct {
+		body     string
+		wantCode int
+	}{
+		{`{"mode":"ga","ga_params":{"population_size":32,"mutation_rate":0.2}}`, http.StatusCreated},
+		{`{"mode":"baseline","ga_params":{"population_size":32}}`, http.StatusUnprocessableEntity},
+		{`{"mode":"ga","ga_params":{"mutation_rate":2}}`, http.StatusBadRequest},
+	} {
+		rec := serve(t, api, http.MethodPost, "/v1/runs", tc.body)
+		if rec.Code != tc.wantCode {
+			t.Errorf("%s: status %d, want %d: %s", tc.body, rec.Code, tc.wantCode, rec.Body)
+		}
+	}
+}
+
+func TestRetryRunStatusCodes(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM runs WHERE id = ?", func(args []any) dbtest.Result {
+		status := map[string]string{"run-failed": "failed", "run-done": "completed"}[args[0].(string)]
+		if status == "" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{args[0], "baseline", 7, "demo", nil, nil, "hash", 2, status, nil, now, now, now, nil, nil, nil, nil, nil}}}
+	})
+
+	for _, tc := range []struct {
+		path     string
+		wantCode int
+	}{
+		{"/v1/runs/run-failed/retry", http.StatusCreated},
+		{"/v1/runs/run-done/retry", http.StatusConflict},
+		{"/v1/runs/run-done/retry?force=true", http.StatusCreated},
+		{"/v1/runs/run-done/retry?force=maybe", http.StatusBadRequest},
+		{"/v1/runs/missing/retry", http.StatusNotFound},
+	} {
+		rec := serve(t, api, http.MethodPost, tc.path, "")
+		if rec.Code != tc.wantCode {
+			t.Errorf("POST %s: status %d, want %d: %s", tc.path, rec.Code, tc.wantCode, rec.Body)
+			continue
+		}
+		if tc.wantCode == http.StatusCreated && !strings.Contains(rec.Body.String(), `"retry_of":"run-`) {
+			t.Errorf("POST %s: body %s, want retry_of", tc.path, rec.Body)
+		}
+	}
+}
+
+func TestGetRunRetries(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("WITH RECURSIVE up", func(args []any) dbtest.Result {
+		if args[0] == "missing" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{"run-a"}}}
+	})
+	fake.Return("WITH RECURSIVE down", dbtest.Result{Rows: [][]any{
+		{"run-a", "ga", 7, "demo", nil, nil, "hash", 2, "failed", nil, now, now, now, nil, nil, nil, nil, nil},
+		{"run-b", "ga", 7, "demo", nil, nil, "hash", 2, "failed", nil, now, now, now, nil, "run-a", nil, nil, nil},
+		{"run-c", "ga", 7, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, "run-b", nil, nil, nil},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-a/retries", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.RunRetriesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.RootID != "run-a" || len(resp.Retries) != 2 || len(resp.Lineage) != 3 || resp.Retries[1].RetryOf == nil || *resp.Retries[1].RetryOf != "run-b" {
+		t.Errorf("resp = %+v, want run-b and run-c as retries of run-a", resp)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/missing/retries", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("missing run: status %d, want 404", rec.Code)
+	}
+}
+
+func TestExperimentEndpoints(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	rec := serve(t, api, http.MethodPost, "/v1/experiments", `{"name":"q3 sweep","description":"grid over seeds"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /experiments: status %d, want 201: %s", rec.Code, rec.Body)
+	}
+	var exp models.Experiment
+	if err := json.Unmarshal(rec.Body.Bytes(), &exp); err != nil || exp.ID == "" || exp.Name != "q3 sweep" {
+		t.Fatalf("experiment = %+v (%v)", exp, err)
+	}
+	if rec := serve(t, api, http.MethodPost, "/v1/experiments", `{"name":""}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /experiments without a name: status %d, want 400", rec.Code)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM experiments WHERE id = ?", func(args []any) dbtest.Result {
+		if args[0] != exp.ID {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{exp.ID, "q3 sweep", "grid over seeds", now}}}
+	})
+	fake.Return("WHERE experiment_id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "baseline", 7, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, exp.ID, 100.0, nil},
+	}})
+	fake.Return("GROUP BY r.mode", dbtest.Result{Rows: [][]any{{"baseline", 1, 1, 1, 0.8, 100.0, 30.0, 5.0}}})
+
+	rec = serve(t, api, http.MethodGet, "/v1/experiments/"+exp.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /experiments/{id}: status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.ExperimentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ID != exp.ID || len(resp.Runs) != 1 || len(resp.Aggregates) != 1 || resp.Aggregates[0].Runs != 1 {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	if rec := serve(t, api, http.MethodPost, "/v1/runs", `{"mode":"baseline","experiment_id":"exp-9"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("run with an unknown experiment: status %d, want 422", rec.Code)
+	}
+	if rec := serve(t, api, http.MethodGet, "/v1/experiments/exp-9", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown experiment: status %d, want 404", rec.Code)
+	}
+}
+
+func TestUpdateRunProgressStatusCodes(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.On("UPDATE runs SET progress_pct", func(args []any) dbtest.Result {
+		if args[1] == "run-1" {
+			return dbtest.Result{RowsAffected: 1}
+		}
+		return dbtest.Result{}
+	})
+	fake.On("SELECT id, status, error_message, progress_pct FROM runs", func(args []any) dbtest.Result {
+		switch args[0] {
+		case "run-1":
+			return dbtest.Result{Rows: [][]any{{"run-1", "started", nil, 25.0}}}
+		case "run-done":
+			return dbtest.Result{Rows: [][]any{{"run-done", "completed", nil, 100.0}}}
+		}
+		return dbtest.Result{}
+	})
+
+	for _, tc := range []struct {
+		path, body string
+		wantCode   int
+	}{
+		{"/v1/runs/run-1/progress", `{"progress_pct":25}`, http.StatusOK},
+		{"/v1/runs/run-1/progress", `{"progress_pct":101}`, http.StatusBadRequest},
+		{"/v1/runs/run-1/progress", `{}`, http.StatusBadRequest},
+		{"/v1/runs/run-done/progress", `{"progress_pct":25}`, http.StatusConflict},
+		{"/v1/runs/missing/progress", `{"progress_pct":25}`, http.StatusNotFound},
+	} {
+		rec := serve(t, api, http.MethodPatch, tc.path, tc.body)
+		if rec.Code != tc.wantCode {
+			t.Errorf("PATCH %s %s: status %d, want %d: %s", tc.path, tc.body, rec.Code, tc.wantCode, rec.Body)
+		}
+		if tc.wantCode == http.StatusOK && !strings.Contains(rec.Body.String(), `"progress_pct":25`) {
+			t.Errorf("body %s, want the recorded progress", rec.Body)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		name, query, accept, want string
+		wantErr                   error // errNotAcceptable or nil; an unknown ?format is checked below
+	}{
+		{"no Accept uses the default", "", "", "nested", nil},
+		{"exact media type", "", "text/csv", "csv", nil},
+		{"highest q wins over header order", "", "application/json;q=0.5, text/csv;q=0.9", "csv", nil},
+		{"header order breaks q ties", "", "application/json, text/csv", "nested", nil},
+		{"q=0 rules a type out", "", "text/csv;q=0, application/json;q=0.1", "nested", nil},
+		{"unserved types are skipped", "", "application/xml, text/csv;q=0.2", "csv", nil},
+		{"wildcard means the default", "", "application/xml, */*;q=0.1", "nested", nil},
+		{"major wildcard picks a served subtype", "", "text/*", "csv", nil},
+		{"malformed q is dropped", "", "text/csv;q=high, application/json;q=0.3", "nested", nil},
+		{"format overrides Accept", "format=flat", "text/csv", "flat", nil},
+		{"nothing acceptable", "", "application/xml, image/*", "", errNotAcceptable},
+		{"everything ruled out", "", "text/csv;q=0, application/json;q=0", "", errNotAcceptable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/compare?"+tc.query, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			got, err := compareNegotiation.negotiate(r)
+			if !errors.Is(err, tc.wantErr) || got != tc.want {
+				t.Errorf("negotiate = %q, %v, want %q, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/compare?format=xml", nil)
+	if _, err := compareNegotiation.negotiate(r); err == nil || errors.Is(err, errNotAcceptable) || !strings.Contains(err.Error(), "format must be one of nested, flat, csv") {
+		t.Errorf("unknown format: err = %v, want a plain format error", err)
+	}
+}
+
+func TestCompareRunsNegotiationErrors(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	compareMetrics(fake, map[string][4]float64{"baseline": {0.8, 100, 45, 5}, "ga": {0.9, 90, 48, 2}})
+
+	get := func(query, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/runs/compare?seed=42&scale=demo"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := get("", "application/xml"); rec.Code != http.StatusNotAcceptable || !strings.Contains(rec.Body.String(), "no acceptable representation") {
+		t.Errorf("Accept: application/xml: status %d, body %s, want 406", rec.Code, rec.Body)
+	}
+	if rec := get("&format=xml", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("format=xml: status %d, want 400", rec.Code)
+	}
+	if rec := get("", "application/json;q=0.4, text/csv;q=0.8"); rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("q-ordered Accept: status %d, Content-Type %q, want CSV", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestGetRunNullStartedAt(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "started", nil, now, nil, nil, nil, nil, nil, nil, nil},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if v, ok := body["started_at"]; !ok || v != nil {
+		t.Errorf("started_at = %v (present %v), want null", v, ok)
+	}
+	if _, ok := body["duration_seconds"]; ok {
+		t.Errorf("duration_seconds = %v, want it omitted for an unstarted run", body["duration_seconds"])
+	}
+}
+
+func TestWriteJSONUnmarshalablePayloadIs500(t *testing.T) {
+	for _, payload := range []any{
+		map[string]any{"ch": make(chan int)},
+		map[string]any{"nan": math.NaN()},
+		func() {},
+	} {
+		rec := httptest.NewRecorder()
+		writeJSON(rec, http.StatusOK, payload)
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("%T: status %d, want 500", payload, rec.Code)
+		}
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%T: body %q is not JSON: %v", payload, rec.Body, err)
+		}
+		if body["code"] != "INTERNAL" || body["error"] != "internal error" {
+			t.Errorf("%T: body = %v, want the internal error shape", payload, body)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%T: Content-Type = %q", payload, got)
+		}
+	}
+}
+
+func TestWriteJSONKeepsStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeJSON(rec, http.StatusCreated, map[string]any{"run_id": "run-1"})
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"run_id":"run-1"}`+"\n" {
+		t.Errorf("status %d, body %q", rec.Code, rec.Body)
+	}
+}
+
+func TestCreateRunDuringMaintenanceIs503(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Now().UTC().Truncate(time.Second)
+	ends := now.Add(90 * time.Second)
+	fake.Return("WHERE starts_at <= ? AND ends_at > ?", dbtest.Result{Rows: [][]any{{int64(1), now, ends, nil, now}}})
+
+	rec := serve(t, api, http.MethodPost, "/v1/runs", `{"mode":"baseline"}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status %d, want 503: %s", rec.Code, rec.Body)
+	}
+	if ra, err := strconv.Atoi(rec.Header().Get("Retry-After")); err != nil || ra < 80 || ra > 90 {
+		t.Errorf("Retry-After = %q, want about 90", rec.Header().Get("Retry-After"))
+	}
+	var body struct {
+		EndsAt time.Time `json:"ends_at"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || !body.EndsAt.Equal(ends) {
+		t.Errorf("ends_at = %v (%v), want %v", body.EndsAt, err, ends)
+	}
+}
+
+func TestCompareRunsMinRunsParam(t *testing.T) {
+	for _, raw := range []string{"0", "-2", "two"} {
+		api, fake := newTestAPI(t, Options{})
+		rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&min_runs="+raw, "")
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid min_runs") {
+			t.Errorf("min_runs=%s: status %d %s, want 400", raw, rec.Code, rec.Body)
+		}
+		if len(fake.Statements()) != 0 {
+			t.Errorf("min_runs=%s: queried the store", raw)
+		}
+	}
+
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("COUNT(*)", dbtest.Result{Rows: [][]any{{1}}})
+	rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&min_runs=4", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		MinRuns   int            `json:"min_runs"`
+		RunCounts map[string]int `json:"run_counts"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.MinRuns != 4 || body.RunCounts["baseline"] != 1 {
+		t.Errorf("body = %+v, want min_runs 4 with counts", body)
+	}
+}
+
+func TestListRunsByIDs(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id IN", dbtest.Result{Rows: [][]any{
+		{"r1", "ga", 42, "demo", nil, nil, "hash", 2, "started", nil, created, created, nil, nil, nil, nil, nil, nil},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs?ids=r1,r2&status=completed", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body models.RunBatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Runs) != 1 || body.Runs[0].ID != "r1" || len(body.NotFound) != 1 || body.NotFound[0] != "r2" {
+		t.Errorf("body = %+v, want r1 found and r2 not_found regardless of status", body)
+	}
+	if n := len(fake.Matching("COUNT(*)")); n != 0 {
+		t.Errorf("ran %d list count queries for an ids lookup", n)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs?ids=", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty ids: status %d, want 400", rec.Code)
+	}
+}
+
+func TestParseBaselineAvg(t *testing.T) {
+	for _, tc := range []struct {
+		query   string
+		want    int
+		wantErr string
+	}{
+		{"", 0, ""},
+		{"baseline=latest", 0, ""},
+		{"baseline=avg", 5, ""},
+		{"baseline=avg&n=3", 3, ""},
+		{"baseline=avg&n=0", 0, "invalid n"},
+		{"baseline=latest&n=3", 0, "n requires baseline=avg"},
+		{"baseline=median", 0, "invalid baseline"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/v1/runs/compare?"+tc.query, nil)
+		got, err := parseBaselineAvg(r)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%q: err = %v, want %q", tc.query, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("%q: n = %d, err = %v, want %d", tc.query, got, err, tc.want)
+		}
+	}
+}
+
+func TestCompareRunsBaselineAvgTooLargeIs400(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&baseline=avg&n=51", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "n must be between 1 and 50") {
+		t.Errorf("status %d %s, want 400", rec.Code, rec.Body)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Error("queried the store for an invalid n")
+	}
+}
+
+func TestGetRunMetricsHistory(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/missing/metrics/history", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown run: status %d, want 404", rec.Code)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+	}})
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("FROM run_metrics_history", dbtest.Result{Rows: [][]any{
+		{60, 1.0, 40.0, 20.0, 0.0, 3, 0, 10, now},
+		{120, 0.8, 80.0, 25.0, 2.0, 6, 1, 10, now.Add(time.Minute)},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics/history?limit=2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("X-Total-Count") != "3" || !strings.Contains(rec.Header().Get("Link"), `rel="next"`) {
+		t.Errorf("pagination headers = %v", rec.Header())
+	}
+	var body models.RunMetricsHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.History) != 2 || body.History[1].OnTimeRate != 0.8 || body.Total != 3 {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestExportRun(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/export?format=csv", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("format=csv: status %d, want 400", rec.Code)
+	}
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/missing/export", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown run: status %d, want 404", rec.Code)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "started", nil, now, now, nil, nil, nil, nil, nil, nil},
+	}})
+	fake.Return("SELECT COUNT(*)", dbtest.Result{Rows: [][]any{{0}}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/export?format=json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="run-run-1.json"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	for _, section := range []string{"format_version", "exported_at", "run", "metrics", "metrics_history", "notes", "events"} {
+		if _, ok := body[section]; !ok {
+			t.Errorf("export is missing %q: %s", section, rec.Body)
+		}
+	}
+	if string(body["metrics"]) != "null" || string(body["notes"]) != "[]" {
+		t.Errorf("metrics = %s, notes = %s, want null and []", body["metrics"], body["notes"])
+	}
+}
+
+func TestRepublishRun(t *testing.T) {
+	for _, tc := range []struct {
+		name, status, query string
+		wantCode            int
+		wantForced          bool
+	}{
+		{"started", "started", "", http.StatusAccepted, false},
+		{"terminal", "completed", "", http.StatusConflict, false},
+		{"terminal forced", "completed", "?force=true", http.StatusAccepted, true},
+		{"bad force", "started", "?force=maybe", http.StatusBadRequest, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, fake := newTestAPI(t, Options{})
+			created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+				{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, tc.status, nil, created, created, nil, nil, nil, nil, nil, nil},
+			}})
+
+			rec := serve(t, api, http.MethodPost, "/v1/runs/run-1/republish"+tc.query, "")
+			if rec.Code != tc.wantCode {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.wantCode, rec.Body)
+			}
+			if tc.wantCode != http.StatusAccepted {
+				return
+			}
+			var resp models.RepublishRunResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.RunID != "run-1" || resp.Status != tc.status || resp.RoutingKey != "run.created" || resp.Forced != tc.wantForced {
+				t.Errorf("resp = %+v", resp)
+			}
+		})
+	}
+}
+
+func TestRepublishMissingRunIs404(t *testing.T) {
+	api, _ := newTestAPI(t, Options{})
+	if rec := serve(t, api, http.MethodPost, "/v1/runs/missing/republish", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRunTrends(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	day1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	fake.Return("GROUP BY DATE(r.completed_at)", dbtest.Result{Rows: [][]any{
+		{day1, "baseline", 0.8, 3},
+		{day1, "ga", 0.9, 2},
+		{day2, "ga", 0.95, 1},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/trends?seed=42&scale=Demo", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.RunTrendsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Seed == nil || *resp.Seed != 42 || resp.Scale != "demo" {
+		t.Errorf("seed/scale = %v/%q, want 42/demo", resp.Seed, resp.Scale)
+	}
+	if len(resp.Points) != 2 {
+		t.Fatalf("points = %+v, want one per day", resp.Points)
+	}
+	first, second := resp.Points[0], resp.Points[1]
+	if first.Date != "2026-01-01" || first.Baseline == nil || first.Baseline.Runs != 3 || first.GA == nil || first.GA.AvgOnTimeRate != 0.9 {
+		t.Errorf("first point = %+v", first)
+	}
+	if second.Date != "2026-01-02" || second.Baseline != nil || second.GA == nil || second.GA.Runs != 1 {
+		t.Errorf("second point = %+v, want ga only", second)
+	}
+	stmt := fake.Matching("GROUP BY DATE(r.completed_at)")[0]
+	if len(stmt.Args) != 2 || stmt.Args[0] != int64(42) || stmt.Args[1] != "demo" {
+		t.Errorf("args = %v, want seed 42 and the canonical scale", stmt.Args)
+	}
+}
+
+func TestRunTrendsInvalidParamsAre400(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	for _, query := range []string{"seed=abc", "scale=huge", "last=1y"} {
+		if rec := serve(t, api, http.MethodGet, "/v1/runs/trends?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400: %s", query, rec.Code, rec.Body)
+		}
+	}
+	if n := len(fake.Matching("GROUP BY DATE(r.completed_at)")); n != 0 {
+		t.Errorf("trend queries = %d, want none for invalid params", n)
+	}
+}
+
+func TestVersionReportsScenarioHashVersion(t *testing.T) {
+	api, _ := newTestAPI(t, Options{})
+	rec := serve(t, api, http.MethodGet, "/version", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var got models.VersionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.ScenarioHashVersion != config.ScenarioHashVersion {
+		t.Errorf("scenario_hash_version = %d, want %d", got.ScenarioHashVersion, config.ScenarioHashVersion)
+	}
+}
+
+func TestScaleRestrictionsAre422(t *testing.T) {
+	t.Setenv("SCALES_CREATE_ONLY", "demo")
+	t.Setenv("SCALES_COMPARE_ONLY", "mini")
+	api, _ := newTestAPI(t, Options{})
+
+	for _, tc := range []struct {
+		method, path, body, want string
+	}{
+		{http.MethodPost, "/v1/runs", `{"mode":"baseline","scale":"mini"}`, "mini is compare-only"},
+		{http.MethodGet, "/v1/runs/compare?seed=42&scale=demo", "", "demo is creation-only"},
+	} {
+		rec := serve(t, api, tc.method, tc.path, tc.body)
+		if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), tc.want) {
+			t.Errorf("%s %s: status %d, body %s, want 422 mentioning %q", tc.method, tc.path, rec.Code, rec.Body, tc.want)
+		}
+	}
+}
+
+func TestCloneRun(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		experiment any
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{"keeps the original parameters", nil, "", http.StatusCreated, `"seed":42`},
+		{"overrides mode and seed", nil, `{"mode":"baseline","seed":7}`, http.StatusCreated, `"seed":7`},
+		{"invalid mode", nil, `{"mode":"random"}`, http.StatusBadRequest, "mode must be baseline or ga"},
+		{"deleted experiment", "exp-gone", "", http.StatusUnprocessableEntity, "experiment not found: exp-gone"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, fake := newTestAPI(t, Options{})
+			fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+				{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, tc.experiment, nil, nil},
+			}})
+			fake.Return("SELECT COUNT(*)", dbtest.Result{Rows: [][]any{{0}}})
+
+			rec := serve(t, api, http.MethodPost, "/v1/runs/run-1/clone", tc.body)
+			if rec.Code != tc.wantStatus || !strings.Contains(rec.Body.String(), tc.want) {
+				t.Errorf("status %d, body %s, want %d containing %q", rec.Code, rec.Body, tc.wantStatus, tc.want)
+			}
+		})
+	}
+}
+
+func TestCloneMissingRunIs404(t *testing.T) {
+	api, _ := newTestAPI(t, Options{})
+	if rec := serve(t, api, http.MethodPost, "/v1/runs/missing/clone", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404: %s", rec.Code, rec.Body)
+	}
+}
+
+// loadFeatures resolves FEATURES=raw the way the server does.
+func loadFeatures(t *testing.T, raw string) config.Features {
+	t.Helper()
+	t.Setenv("FEATURES", raw)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	return cfg.Features
+}
+
+func TestAdminRoutesFeatureGating(t *testing.T) {
+	for _, tc := range []struct {
+		features             string
+		maintenance, seeding bool
+	}{
+		{"", false, false},
+		{"admin", true, false},
+		{"admin,dev_seed", true, true},
+		{`{"admin":false,"dev_seed":true}`, false, false},
+	} {
+		t.Run(tc.features, func(t *testing.T) {
+			api, _ := newTestAPI(t, Options{Features: loadFeatures(t, tc.features)})
+			if rec := serve(t, api, http.MethodGet, "/admin/maintenance", ""); (rec.Code != http.StatusNotFound) != tc.maintenance {
+				t.Errorf("GET /admin/maintenance: status %d, want mounted %v", rec.Code, tc.maintenance)
+			}
+			if rec := serve(t, api, http.MethodPost, "/admin/seed?count=abc", ""); (rec.Code != http.StatusNotFound) != tc.seeding {
+				t.Errorf("POST /admin/seed: status %d, want mounted %v", rec.Code, tc.seeding)
+			}
+		})
+	}
+}
+
+func TestPprofRoutesFeatureGating(t *testing.T) {
+	for _, tc := range []struct {
+		name, features string
+		separate       bool
+		public, admin  bool
+	}{
+		{"off", "", false, false, false},
+		{"on, shared listener", "pprof", false, true, false},
+		{"on, admin listener", "pprof", true, false, true},
+		{"disabled, admin listener", `{"pprof":false}`, true, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, _ := newTestHandler(t, Options{Features: loadFeatures(t, tc.features), SeparateAdmin: tc.separate})
+			public := httpx.NewRouter(h.Register, httpx.Options{})
+			admin := httpx.NewRouter(h.RegisterAdmin, httpx.Options{})
+			for _, path := range []string{"/debug/pprof/", "/debug/pprof/cmdline", "/debug/pprof/goroutine?debug=1"} {
+				if rec := serve(t, public, http.MethodGet, path, ""); (rec.Code == http.StatusOK) != tc.public {
+					t.Errorf("public GET %s: status %d, want mounted %v", path, rec.Code, tc.public)
+				}
+				if !tc.separate {
+					continue // the admin listener only runs alongside SeparateAdmin
+				}
+				if rec := serve(t, admin, http.MethodGet, path, ""); (rec.Code == http.StatusOK) != tc.admin {
+					t.Errorf("admin GET %s: status %d, want mounted %v", path, rec.Code, tc.admin)
+				}
+			}
+		})
+	}
+}
+
+func TestSeparateAdminKeepsAdminRoutesOffThePublicMux(t *testing.T) {
+	h, _ := newTestHandler(t, Options{Features: loadFeatures(t, "admin,dev_seed,pprof"), SeparateAdmin: true})
+	public := httpx.NewRouter(h.Register, httpx.Options{})
+	admin := httpx.NewRouter(h.RegisterAdmin, httpx.Options{})
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/admin/maintenance"},
+		{http.MethodPost, "/admin/maintenance"},
+		{http.MethodPost, "/admin/seed?count=abc"},
+		{http.MethodGet, "/debug/pprof/"},
+	} {
+		if rec := serve(t, public, tc.method, tc.path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("public %s %s: status %d, want 404", tc.method, tc.path, rec.Code)
+		}
+		if rec := serve(t, admin, tc.method, tc.path, ""); rec.Code == http.StatusNotFound {
+			t.Errorf("admin %s %s: 404, want it mounted", tc.method, tc.path)
+		}
+	}
+	// The admin listener serves nothing but admin routes.
+	for _, path := range []string{"/health", "/v1/runs", "/runs"} {
+		if rec := serve(t, admin, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("admin GET %s: status %d, want 404", path, rec.Code)
+		}
+	}
+	if rec := serve(t, public, http.MethodGet, "/health", ""); rec.Code == http.StatusNotFound {
+		t.Errorf("public GET /health: 404, want it mounted")
+	}
+}
+
+func TestSeedDevDataWithoutDevModeIs403(t *testing.T) {
+	t.Setenv("DEV_MODE", "false")
+	api, fake := newTestAPI(t, Options{Features: loadFeatures(t, "admin,dev_seed")})
+	rec := serve(t, api, http.MethodPost, "/admin/seed", "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "set DEV_MODE=true") {
+		t.Errorf("status %d, body %s, want 403 naming DEV_MODE", rec.Code, rec.Body)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+}
+
+// compareMetrics answers the compare reads with one completed run per mode: on-time
+// rate, distance, completed and failed jobs from metrics[mode].
+func compareMetrics(fake *dbtest.Fake, metrics map[string][4]float64) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("COUNT(*)", func(args []any) dbtest.Result {
+		if _, ok := metrics[args[2].(string)]; !ok {
+			return dbtest.Result{Rows: [][]any{{0}}}
+		}
+		return dbtest.Result{Rows: [][]any{{1}}}
+	})
+	fake.On("ORDER BY r.completed_at DESC", func(args []any) dbtest.Result {
+		mode := args[2].(string)
+		m, ok := metrics[mode]
+		if !ok {
+			return dbtest.Result{}
+		}
+		completed, failed := int(m[2]), int(m[3])
+		return dbtest.Result{Rows: [][]any{{mode + "-run", m[0], m[1], 30.0, 5.0, completed, failed, completed + failed, now, "completed"}}}
+	})
+}
+
+func TestCompareRunsNormalizedDelta(t *testing.T) {
+	for _, tc := range []struct {
+		name, query    string
+		robots, jobs   int
+		source         string
+		perRobotDist   float64
+		perJobComplete float64
+	}{
+		{"preset fleet", "", 10, 50, "preset", -2, 0.1},
+		{"override fleet", "&robots=4&jobs=20", 4, 20, "override", -5, 0.25},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, fake := newTestAPI(t, Options{})
+			compareMetrics(fake, map[string][4]float64{"baseline": {0.8, 100, 40, 10}, "ga": {0.9, 80, 45, 5}})
+
+			rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo"+tc.query, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+			}
+			var body struct {
+				Delta models.CompareDelta `json:"delta"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			d := body.Delta
+			if d.Robots != tc.robots || d.Jobs != tc.jobs || d.FleetSource != tc.source {
+				t.Errorf("fleet = %d/%d %s, want %d/%d %s", d.Robots, d.Jobs, d.FleetSource, tc.robots, tc.jobs, tc.source)
+			}
+			if d.TotalDistance != -20 || d.CompletedJobs != 5 || d.FailedJobs != -5 {
+				t.Errorf("raw delta = %+v", d)
+			}
+			if math.Abs(d.PerRobot.TotalDistance-tc.perRobotDist) > 1e-9 || math.Abs(d.PerJob.CompletedJobs-tc.perJobComplete) > 1e-9 {
+				t.Errorf("per_robot = %+v, per_job = %+v", d.PerRobot, d.PerJob)
+			}
+			if math.Abs(d.PerRobot.FailedJobs+d.PerRobot.CompletedJobs) > 1e-9 || math.Abs(d.PerJob.TotalDistance-(-20.0/float64(tc.jobs))) > 1e-9 {
+				t.Errorf("per_robot = %+v, per_job = %+v", d.PerRobot, d.PerJob)
+			}
+		})
+	}
+}
+
+func TestListScenarios(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	latest := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return(") scenarios", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("SUM(mode = 'baseline')", dbtest.Result{Rows: [][]any{{42, "demo", nil, nil, 2, 1, 3, latest}}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/scenarios?limit=1&offset=1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.ScenarioListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Total != 3 || resp.Limit != 1 || resp.Offset != 1 || len(resp.Scenarios) != 1 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if sc := resp.Scenarios[0]; sc.Seed != 42 || sc.BaselineRuns != 2 || sc.GARuns != 1 || !sc.LatestRunAt.Equal(latest) {
+		t.Errorf("scenario = %+v", sc)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+	if link := rec.Header().Get("Link"); !strings.Contains(link, `offset=2>; rel="next"`) || !strings.Contains(link, `offset=0>; rel="prev"`) {
+		t.Errorf("Link = %q, want next and prev pages", link)
+	}
+}
+
+func TestListScenariosInvalidParamsAre400(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	for _, query := range []string{"limit=0", "offset=-1", "from=yesterday", "last=1y"} {
+		if rec := serve(t, api, http.MethodGet, "/v1/scenarios?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, rec.Code)
+		}
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("invalid params queried the store: %v", fake.Statements())
+	}
+}
+
+func TestCreateRunReturnsCreatedAt(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	before := time.Now().UTC().Truncate(time.Second)
+	rec := serve(t, api, http.MethodPost, "/v1/runs", `{"mode":"baseline"}`)
+	after := time.Now().UTC()
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.CreateRunResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.CreatedAt.Before(before) || resp.CreatedAt.After(after) || resp.CreatedAt.Nanosecond() != 0 {
+		t.Errorf("created_at = %s, want a whole second between %s and %s", resp.CreatedAt, before, after)
+	}
+	if resp.Status != "started" {
+		t.Errorf("status = %q, want started", resp.Status)
+	}
+	// The response carries the timestamp that was written, not a later read.
+	insert := fake.Matching("INSERT INTO runs")
+	if len(insert) != 1 || !insert[0].Args[9].(time.Time).Equal(resp.CreatedAt) {
+		t.Errorf("inserted created_at = %v, want %s", insert[0].Args[9], resp.CreatedAt)
+	}
+	if len(fake.Matching("FROM runs WHERE id = ?")) != 0 {
+		t.Error("create read the run back to learn created_at")
+	}
+}
+
+func TestCompareRunsFlatFormat(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	compareMetrics(fake, map[string][4]float64{"baseline": {0.8, 100, 40, 10}, "ga": {0.9, 80, 45, 5}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&format=flat", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	want := []string{"on_time_rate", "total_distance", "avg_completion_time", "max_lateness", "completed_jobs", "failed_jobs", "total_jobs"}
+	if len(rows) != len(want) {
+		t.Fatalf("rows = %d, want %d: %s", len(rows), len(want), rec.Body)
+	}
+	for i, row := range rows {
+		if row["metric"] != want[i] {
+			t.Errorf("row %d metric = %v, want %s", i, row["metric"], want[i])
+		}
+		for _, key := range []string{"baseline", "ga", "delta_pct"} {
+			if _, ok := row[key]; !ok {
+				t.Errorf("%s row lacks %q", want[i], key)
+			}
+		}
+	}
+	if rows[1]["baseline"] != 100.0 || rows[1]["ga"] != 80.0 || rows[1]["delta_pct"] != -20.0 {
+		t.Errorf("total_distance row = %v", rows[1])
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&format=csv", "")
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if rec.Code != http.StatusOK || len(lines) != len(want)+1 || lines[0] != "metric,baseline,ga,delta_pct" {
+		t.Errorf("csv: status %d, body %q", rec.Code, rec.Body)
+	}
+}
+
+func TestInternalErrorsHideDetailsUnlessDebug(t *testing.T) {
+	const cause = "dial tcp 10.0.0.5:3306: connection refused"
+	for _, tc := range []struct {
+		name    string
+		opts    Options
+		wantMsg string
+	}{
+		{"generic", Options{}, "internal error"},
+		{"debug", Options{DebugErrors: true}, "select run: " + cause},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			prev := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(prev) })
+
+			api, fake := newTestAPI(t, tc.opts)
+			fake.Return("FROM runs WHERE id = ?", dbtest.Result{Err: errors.New(cause)})
+
+			rec := serve(t, api, http.MethodGet, "/v1/runs/run-1", "")
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status %d, want 500: %s", rec.Code, rec.Body)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body["code"] != "INTERNAL" || body["error"] != tc.wantMsg || body["message"] != tc.wantMsg {
+				t.Errorf("body = %v, want code INTERNAL and message %q", body, tc.wantMsg)
+			}
+			if !tc.opts.DebugErrors && strings.Contains(rec.Body.String(), "10.0.0.5") {
+				t.Errorf("body leaks the raw error: %s", rec.Body)
+			}
+			// Either way the cause is logged server-side with the request.
+			if !strings.Contains(logs.String(), cause) || !strings.Contains(logs.String(), "path=/v1/runs/run-1") {
+				t.Errorf("log = %q, want the raw error and path", logs.String())
+			}
+		})
+	}
+}
+
+func TestRunNotesEndpoints(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM runs WHERE id = ?", func(args []any) dbtest.Result {
+		if args[0] != "run-1" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{
+			{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+		}}
+	})
+	fake.Return("INSERT INTO run_notes", dbtest.Result{RowsAffected: 1, LastInsertID: 7})
+	fake.Return("SELECT COUNT(*) FROM run_notes", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("FROM run_notes", dbtest.Result{Rows: [][]any{{int64(7), "run-1", "alice", "sensor glitch", now}}})
+
+	rec := serve(t, api, http.MethodPost, "/v1/runs/run-1/notes", `{"author":"alice","text":"sensor glitch"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST notes: status %d, want 201: %s", rec.Code, rec.Body)
+	}
+	var note models.RunNote
+	if err := json.Unmarshal(rec.Body.Bytes(), &note); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if note.ID != 7 || note.Text != "sensor glitch" {
+		t.Errorf("note = %+v", note)
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/notes?limit=1&offset=2", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET notes: status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+	var list models.RunNoteListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if list.Total != 3 || list.Limit != 1 || list.Offset != 2 || len(list.Notes) != 1 {
+		t.Errorf("list = %+v", list)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/v1/runs/run-1/notes", `{"text":""}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/runs/run-1/notes", `not json`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/runs/missing/notes", `{"text":"hi"}`, http.StatusNotFound},
+		{http.MethodGet, "/v1/runs/missing/notes", "", http.StatusNotFound},
+		{http.MethodGet, "/v1/runs/run-1/notes?limit=-1", "", http.StatusBadRequest},
+	} {
+		if rec := serve(t, api, tc.method, tc.path, tc.body); rec.Code != tc.want {
+			t.Errorf("%s %s %s: status %d, want %d", tc.method, tc.path, tc.body, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestGetMetricsThresholdVerdict(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.8, 200.0, 30.0, 5.0, 16, 4, 20, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), "completed"},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics?thresholds=on_time_rate>=0.9,failed_jobs<=4", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var m models.RunMetrics
+	if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if m.Passed == nil || *m.Passed || len(m.FailedCriteria) != 1 || m.FailedCriteria[0].Criterion != "on_time_rate>=0.9" {
+		t.Errorf("passed=%v failed=%+v, want failing on on_time_rate only", m.Passed, m.FailedCriteria)
+	}
+
+	// Without thresholds the verdict fields are omitted entirely.
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics", "")
+	if strings.Contains(rec.Body.String(), "passed") || strings.Contains(rec.Body.String(), "failed_criteria") {
+		t.Errorf("body %s, want no verdict fields", rec.Body)
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics?thresholds=speed>=1", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "unknown metric") {
+		t.Errorf("status %d, body %s, want 400 naming the metric", rec.Code, rec.Body)
+	}
+}
+
+func TestGetPeer(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM runs WHERE id = ?", func(args []any) dbtest.Result {
+		if args[0] == "missing" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{
+			{args[0], "ga", 42, "demo", nil, nil, "hash", int64(2), "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+		}}
+	})
+	fake.On("FROM run_metrics rm", func(args []any) dbtest.Result {
+		if args[0] != int64(42) {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{"run-2", 0.9, 100.0, 30.0, 5.0, 9, 1, 10, now, "completed"}}}
+	})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/peer", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.RunPeerResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.PeerMode != "baseline" || resp.Peer == nil || resp.Peer.RunID != "run-2" {
+		t.Errorf("resp = %+v, want run-2 as the baseline peer", resp)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/missing/peer", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown run: status %d, want 404", rec.Code)
+	}
+}
+
+func TestGetPeerWithoutCompletedPeerIs404(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+	}})
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/peer", "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "peer") {
+		t.Errorf("status %d, body %s, want 404 about the peer", rec.Code, rec.Body)
+	}
+}
+
+func TestGetRunDelta(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM run_metrics rm", func(args []any) dbtest.Result {
+		switch args[0] {
+		case "base":
+			return dbtest.Result{Rows: [][]any{{"base", 0.8, 200.0, 30.0, 5.0, 45, 5, 50, now, "completed"}}}
+		case "run-1":
+			return dbtest.Result{Rows: [][]any{{"run-1", 0.9, 150.0, 30.0, 5.0, 48, 2, 50, now, "completed"}}}
+		}
+		return dbtest.Result{}
+	})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/delta?against=base", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.RunDeltaResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.RunID != "run-1" || resp.Against != "base" || len(resp.Metrics) == 0 || resp.Metrics[0].Metric != "on_time_rate" {
+		t.Errorf("resp = %+v", resp)
+	}
+
+	for _, tc := range []struct {
+		path     string
+		wantCode int
+	}{
+		{"/v1/runs/run-1/delta", http.StatusBadRequest},
+		{"/v1/runs/missing/delta?against=base", http.StatusNotFound},
+		{"/v1/runs/run-1/delta?against=missing", http.StatusNotFound},
+	} {
+		if rec := serve(t, api, http.MethodGet, tc.path, ""); rec.Code != tc.wantCode {
+			t.Errorf("GET %s: status %d, want %d: %s", tc.path, rec.Code, tc.wantCode, rec.Body)
+		}
+	}
+}
+
+func TestRunLeaderboardQualityGate(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-2", 0.95, 120.0, 30.0, 2.0, 48, 2, 50, now, "completed", "ga", 42, "demo"},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/leaderboard?metric=total_distance&direction=asc&limit=3&min_on_time_rate=0.8&max_failed_jobs=3", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.LeaderboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Min["on_time_rate"] != 0.8 || resp.Max["failed_jobs"] != 3 || len(resp.Runs) != 1 || resp.Runs[0].Value != 120 {
+		t.Errorf("resp = %+v", resp)
+	}
+	stmt := fake.Matching("FROM run_metrics rm")[0]
+	if !strings.Contains(stmt.Query, "rm.on_time_rate >= ?") || !strings.Contains(stmt.Query, "rm.failed_jobs <= ?") {
+		t.Errorf("query %q, want both bounds as predicates", stmt.Query)
+	}
+
+	for _, query := range []string{"min_on_time_rate=high", "min_on_time_rate=1.5", "limit=0", "min_speed=1"} {
+		if rec := serve(t, api, http.MethodGet, "/v1/runs/leaderboard?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("?%s: status %d, want 400: %s", query, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestListScenarioImprovements(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("COUNT(*) FROM pairs", dbtest.Result{Rows: [][]any{{5}}})
+	fake.Return("FROM pairs", dbtest.Result{Rows: [][]any{
+		{7, "large", nil, nil, "b-7", "g-7", 0.5, 0.9},
+		{42, "demo", nil, nil, "b-42", "g-42", 0.8, 0.85},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/scenarios/improvements?limit=2&offset=0", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("X-Total-Count") != "5" || !strings.Contains(rec.Header().Get("Link"), `rel="next"`) {
+		t.Errorf("pagination headers = %v, want total 5 and a next link", rec.Header())
+	}
+	var resp models.ScenarioImprovementListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Scenarios) != 2 || resp.Scenarios[0].GARunID != "g-7" || math.Abs(resp.Scenarios[0].OnTimeRateDelta-0.4) > 1e-9 {
+		t.Errorf("scenarios = %+v, want g-7 ranked first with delta 0.4", resp.Scenarios)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/scenarios/improvements?limit=abc", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid limit: status %d, want 400", rec.Code)
+	}
+}
+
+func TestCompareRunsWeightedScore(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	compareMetrics(fake, map[string][4]float64{"baseline": {0.8, 100, 40, 10}, "ga": {0.9, 200, 45, 5}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&weights=on_time:0.5,distance:0.5", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Score *models.CompareScore `json:"score"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Score == nil || body.Score.Winner != "baseline" || len(body.Score.GA.Contributions) != 2 {
+		t.Errorf("score = %+v, want baseline winning on the halved GA distance score", body.Score)
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo&weights=speed:1", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "unknown metric") {
+		t.Errorf("status %d, body %s, want 400 naming the metric", rec.Code, rec.Body)
+	}
+}
+
+func TestGetRunTimeline(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM runs WHERE id = ?", func(args []any) dbtest.Result {
+		if args[0] != "run-1" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{
+			{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "started", nil, created, created.Add(time.Second), nil, nil, nil, nil, nil, nil},
+		}}
+	})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/timeline", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp models.RunTimelineResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Status != "started" || len(resp.Events) != 2 || resp.Events[0].Type != "created" || resp.Events[1].Type != "started" {
+		t.Errorf("timeline = %+v, want created then started", resp)
+	}
+	if !resp.Events[1].At.Equal(created.Add(time.Second)) {
+		t.Errorf("started at %v, want %v", resp.Events[1].At, created.Add(time.Second))
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs/missing/timeline", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown run: status %d, want 404", rec.Code)
+	}
+}
+
+func TestGetMetricsPrometheusFormat(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{`run-"1"`, 0.8, 200.5, 30.0, 5.0, 16, 4, 20, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), "started"},
+	}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics?format=prometheus&thresholds=on_time_rate>=0.9", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != prometheusContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, prometheusContentType)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE fleet_run_on_time_rate gauge",
+		`fleet_run_on_time_rate{run_id="run-\"1\""} 0.8`,
+		`fleet_run_total_distance{run_id="run-\"1\""} 200.5`,
+		`fleet_run_avg_completion_time{run_id="run-\"1\""} 30`,
+		`fleet_run_max_lateness{run_id="run-\"1\""} 5`,
+		`fleet_run_completed_jobs{run_id="run-\"1\""} 16`,
+		`fleet_run_failed_jobs{run_id="run-\"1\""} 4`,
+		`fleet_run_total_jobs{run_id="run-\"1\""} 20`,
+		`fleet_run_passed{run_id="run-\"1\""} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("exposition lacks %q:\n%s", line, body)
+		}
+	}
+
+	// JSON stays the default, and fleet_run_passed only appears with thresholds.
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics", "")
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("default Content-Type = %q, want JSON", rec.Header().Get("Content-Type"))
+	}
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics?format=prometheus", "")
+	if strings.Contains(rec.Body.String(), "fleet_run_passed") {
+		t.Errorf("fleet_run_passed exported without thresholds:\n%s", rec.Body)
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-1/metrics?format=xml", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "format must be one of json, prometheus") {
+		t.Errorf("unknown format: status %d, body %s, want 400", rec.Code, rec.Body)
+	}
+}
+
+func TestCompareRunsStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name                 string
+		metrics              map[string][4]float64
+		status, message      string
+		baselineNull, gaNull bool
+	}{
+		{"no data", map[string][4]float64{}, "no_data", "no completed baseline or ga run for this scenario yet", true, true},
+		{"partial", map[string][4]float64{"ga": {0.9, 80, 45, 5}}, "partial", "no completed baseline run for this scenario yet", true, false},
+		{"complete", map[string][4]float64{"baseline": {0.8, 100, 40, 10}, "ga": {0.9, 80, 45, 5}}, "complete", "both modes have a completed run", false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			api, fake := newTestAPI(t, Options{})
+			compareMetrics(fake, tc.metrics)
+
+			rec := serve(t, api, http.MethodGet, "/v1/runs/compare?seed=42&scale=demo", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d, want 200 even without data: %s", rec.Code, rec.Body)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body["status"] != tc.status || body["message"] != tc.message {
+				t.Errorf("status = %v, message = %v, want %q / %q", body["status"], body["message"], tc.status, tc.message)
+			}
+			if (body["baseline"] == nil) != tc.baselineNull || (body["ga"] == nil) != tc.gaNull {
+				t.Errorf("baseline = %v, ga = %v", body["baseline"], body["ga"])
+			}
+		})
+	}
+}
+
+func TestListRunsHasErrorFilter(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs?has_error=true&status=failed", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	count := fake.Matching("SELECT COUNT(*) FROM runs")
+	if len(count) != 1 || !strings.Contains(count[0].Query, "status = ? AND error_message IS NOT NULL AND error_message != ''") {
+		t.Errorf("count queries = %+v, want status and error predicates combined", count)
+	}
+
+	if rec := serve(t, api, http.MethodGet, "/v1/runs?has_error=maybe", ""); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid has_error") {
+		t.Errorf("status %d, body %s, want 400 invalid has_error", rec.Code, rec.Body)
+	}
+}
+
+func TestGetRunStatus(t *testing.T) {
+	api, fake := newTestAPI(t, Options{})
+	fake.On("SELECT id, status, error_message, progress_pct FROM runs", func(args []any) dbtest.Result {
+		switch args[0] {
+		case "run-1":
+			return dbtest.Result{Rows: [][]any{{"run-1", "failed", "robot 3 lost", 40.0}}}
+		case "run-2":
+			return dbtest.Result{Rows: [][]any{{"run-2", "started", nil, nil}}}
+		}
+		return dbtest.Result{}
+	})
+
+	rec := serve(t, api, http.MethodGet, "/v1/runs/run-1/status", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"id": "run-1", "status": "failed", "error_message": "robot 3 lost", "progress_pct": 40.0}
+	if len(body) != len(want) {
+		t.Errorf("body = %v, want only %v", body, want)
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v", k, body[k], v)
+		}
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/run-2/status", "")
+	if !strings.Contains(rec.Body.String(), `"error_message":null`) {
+		t.Errorf("body %s, want error_message null for a healthy run", rec.Body)
+	}
+
+	// Polling reads only the status columns, never the full run row.
+	if n := len(fake.Matching("id, mode, seed")); n != 0 {
+		t.Errorf("full run reads = %d, want 0", n)
+	}
+
+	rec = serve(t, api, http.MethodGet, "/v1/runs/missing/status", "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "run not found") {
+		t.Errorf("unknown run: status %d, body %s, want 404", rec.Code, rec.Body)
+	}
+}
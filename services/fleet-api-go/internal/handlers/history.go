@@ -0,0 +1,30 @@
+package handlers
+
+// File: internal/handlers/history.go
+// Purpose: HTTP handler for a run's metrics history (/runs/{id}/metrics/history).
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) getRunMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	resp, err := h.runs.RunMetricsHistory(r.Context(), r.PathValue("id"), limit, offset)
+	if err != nil {
+		if errors.Is(err, services.ErrRunNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+			return
+		}
+		h.writeInternalError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
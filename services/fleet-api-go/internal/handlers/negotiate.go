@@ -0,0 +1,127 @@
+package handlers
+
+// File: internal/handlers/negotiate.go
+// Purpose: Response format negotiation (Accept header + ?format override) and CSV output.
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errNotAcceptable is returned when no representation matches the Accept header.
+var errNotAcceptable = errors.New("not acceptable")
+
+// negotiation describes the representations an endpoint serves. Formats are the
+// accepted ?format values; Media maps Accept media types to one of them.
+type negotiation struct {
+	Default string
+	Formats []string
+	Media   map[string]string
+}
+
+// negotiate picks the response format. An explicit ?format wins; otherwise the
+// highest-q Accept entry the endpoint serves, with wildcards and a missing header
+// meaning Default. It returns a plain error for an unknown ?format and
+// errNotAcceptable when Accept rules out every representation.
+func (n negotiation) negotiate(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		if !slices.Contains(n.Formats, format) {
+			return "", fmt.Errorf("format must be one of %s", strings.Join(n.Formats, ", "))
+		}
+		return format, nil
+	}
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return n.Default, nil
+	}
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if format, ok := n.Media[mediaType]; ok {
+			return format, nil
+		}
+		if mediaType == "*/*" {
+			return n.Default, nil
+		}
+		if major, ok := strings.CutSuffix(mediaType, "/*"); ok {
+			for _, served := range sortedMediaTypes(n.Media) {
+				if strings.HasPrefix(served, major+"/") {
+					return n.Media[served], nil
+				}
+			}
+		}
+	}
+	return "", errNotAcceptable
+}
+
+// acceptedMediaTypes returns the media types of an Accept header ordered by q,
+// highest first (header order breaks ties). Entries with q=0 or that fail to
+// parse are dropped.
+func acceptedMediaTypes(accept string) []string {
+	type entry struct {
+		mediaType string
+		q         float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			entries = append(entries, entry{mediaType, q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.mediaType
+	}
+	return out
+}
+
+func sortedMediaTypes(media map[string]string) []string {
+	types := make([]string, 0, len(media))
+	for t := range media {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// writeNegotiationError answers a failed negotiate: 406 for errNotAcceptable, 400 otherwise.
+func writeNegotiationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotAcceptable) {
+		writeJSON(w, http.StatusNotAcceptable, map[string]any{"error": "no acceptable representation for the Accept header"})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+}
+
+// writeCSV writes a header row and records as text/csv.
+func writeCSV(w http.ResponseWriter, status int, header []string, records [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(status)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	_ = cw.WriteAll(records)
+}
+
+// formatOptionalFloat renders a nullable number for CSV; null becomes an empty cell.
+func formatOptionalFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+// File: internal/handlers/normalized.go
+// Purpose: HTTP handler for job-normalized run metrics (/runs/{id}/metrics/normalized).
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) getNormalizedMetrics(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.runs.NormalizedMetrics(r.Context(), r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRunNotFound), errors.Is(err, services.ErrMetricsNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+		default:
+			h.writeInternalError(w, r, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+// File: internal/handlers/notes.go
+// Purpose: HTTP handlers for run notes (/runs/{id}/notes).
+
+import (
+	"errors"
+	"net/http"
+
+	"fleet-api-go/internal/models"
+	"fleet-api-go/internal/services"
+)
+
+func (h *Handler) addRunNote(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRunNoteRequest
+	if !decodeJSONBody(w, r, &req, "invalid JSON body") {
+		return
+	}
+	note, err := h.runs.AddRunNote(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		h.writeNoteError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, note)
+}
+
+func (h *Handler) listRunNotes(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+		return
+	}
+	resp, err := h.runs.ListRunNotes(r.Context(), r.PathValue("id"), limit, offset)
+	if err != nil {
+		h.writeNoteError(w, r, err)
+		return
+	}
+	setPaginationHeaders(w, r, resp.Total, resp.Limit, resp.Offset)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) writeNoteError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, services.ErrRunNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": err.Error()})
+	case services.IsValidation(err):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+	default:
+		h.writeInternalError(w, r, err)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+// File: internal/handlers/params.go
+// Purpose: Shared query-parameter parsing for list endpoints (pagination, time ranges).
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parsePagination reads limit/offset query params, applying defaults and bounds.
+func parsePagination(r *http.Request) (int, int, error) {
+	limit := defaultPageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+		if v > maxPageLimit {
+			v = maxPageLimit
+		}
+		limit = v
+	}
+	offset := 0
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = v
+	}
+	return limit, offset, nil
+}
+
+// setPaginationHeaders sets X-Total-Count and an RFC 8288 Link header with next/prev
+// page URLs. Links keep the request's other query params and its original path
+// (including any API_BASE_PATH prefix); tail is dropped since the links page by offset.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, total, limit, offset int) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if limit <= 0 {
+		return
+	}
+	path := originalPath(r)
+	pageURL := func(off int) string {
+		q := r.URL.Query()
+		q.Del("tail")
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(off))
+		return path + "?" + q.Encode()
+	}
+	var links []string
+	if offset+limit < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(offset+limit)))
+	}
+	if offset > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(max(offset-limit, 0))))
+	}
+	if len(links) > 0 {
+		// Add, not Set: deprecated aliases already carry a successor-version Link.
+		w.Header().Add("Link", strings.Join(links, ", "))
+	}
+}
+
+// originalPath is the path the client requested, including any API_BASE_PATH prefix
+// that withBasePath stripped from r.URL.Path.
+func originalPath(r *http.Request) string {
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return u.Path
+	}
+	return r.URL.Path
+}
+
+// parseTimeRange reads the optional from/to (RFC3339) and last (relative duration)
+// query params. last=24h|7d|2w resolves to from = now - duration and cannot be
+// combined with from.
+func parseTimeRange(r *http.Request, now time.Time) (models.TimeRange, error) {
+	var tr models.TimeRange
+	q := r.URL.Query()
+	if raw := q.Get("from"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return tr, fmt.Errorf("invalid from: expected RFC3339 timestamp")
+		}
+		tr.From = &t
+	}
+	if raw := q.Get("to"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return tr, fmt.Errorf("invalid to: expected RFC3339 timestamp")
+		}
+		tr.To = &t
+	}
+	if raw := q.Get("last"); raw != "" {
+		if tr.From != nil {
+			return tr, fmt.Errorf("last and from are mutually exclusive")
+		}
+		d, err := parseRelativeDuration(raw)
+		if err != nil {
+			return tr, err
+		}
+		from := now.Add(-d)
+		tr.From = &from
+	}
+	if tr.From != nil && tr.To != nil && tr.To.Before(*tr.From) {
+		return tr, fmt.Errorf("to must not be before from")
+	}
+	return tr, nil
+}
+
+// parseRelativeDuration parses a positive whole number followed by h (hours),
+// d (days) or w (weeks), e.g. "24h", "7d", "2w".
+func parseRelativeDuration(raw string) (time.Duration, error) {
+	if len(raw) < 2 {
+		return 0, fmt.Errorf("invalid last: expected <n>h, <n>d or <n>w")
+	}
+	var unit time.Duration
+	switch raw[len(raw)-1] {
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid last: unit must be h, d or w")
+	}
+	n, err := strconv.Atoi(raw[:len(raw)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid last: expected a positive whole number before the unit")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// defaultBaselineAvgRuns is the compare window for baseline=avg without n.
+const defaultBaselineAvgRuns = 5
+
+// parseBaselineAvg reads compare's baseline (latest|avg) and n params. It returns
+// the rolling-average window, or 0 for the latest single baseline run.
+func parseBaselineAvg(r *http.Request) (int, error) {
+	q := r.URL.Query()
+	switch q.Get("baseline") {
+	case "", "latest":
+		if q.Has("n") {
+			return 0, fmt.Errorf("n requires baseline=avg")
+		}
+		return 0, nil
+	case "avg":
+	default:
+		return 0, fmt.Errorf("invalid baseline: must be latest or avg")
+	}
+	raw := q.Get("n")
+	if raw == "" {
+		return defaultBaselineAvgRuns, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid n: must be >= 1")
+	}
+	return n, nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+// File: internal/handlers/prometheus.go
+// Purpose: Prometheus text exposition of a single run's metrics (?format=prometheus).
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// prometheusContentType is the text exposition format understood by Prometheus and Pushgateway.
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// promGauge is one exported gauge derived from RunMetrics.
+type promGauge struct {
+	name  string
+	help  string
+	value func(m *models.RunMetrics) float64
+}
+
+var runMetricGauges = []promGauge{
+	{"fleet_run_on_time_rate", "Fraction of jobs completed on time.", func(m *models.RunMetrics) float64 { return m.OnTimeRate }},
+	{"fleet_run_total_distance", "Total distance travelled by the fleet.", func(m *models.RunMetrics) float64 { return m.TotalDistance }},
+	{"fleet_run_avg_completion_time", "Average job completion time.", func(m *models.RunMetrics) float64 { return m.AvgCompletionTime }},
+	{"fleet_run_max_lateness", "Maximum job lateness.", func(m *models.RunMetrics) float64 { return m.MaxLateness }},
+	{"fleet_run_completed_jobs", "Jobs completed.", func(m *models.RunMetrics) float64 { return float64(m.CompletedJobs) }},
+	{"fleet_run_failed_jobs", "Jobs failed.", func(m *models.RunMetrics) float64 { return float64(m.FailedJobs) }},
+	{"fleet_run_total_jobs", "Jobs in the scenario.", func(m *models.RunMetrics) float64 { return float64(m.TotalJobs) }},
+}
+
+// writePrometheusMetrics writes m as gauges labelled with run_id. When thresholds
+// were evaluated, fleet_run_passed is 1 or 0.
+func writePrometheusMetrics(w http.ResponseWriter, m *models.RunMetrics) {
+	label := `{run_id="` + escapeLabelValue(m.RunID) + `"}`
+	var b strings.Builder
+	for _, g := range runMetricGauges {
+		writeGauge(&b, g.name, g.help, label, g.value(m))
+	}
+	if m.Passed != nil {
+		passed := 0.0
+		if *m.Passed {
+			passed = 1
+		}
+		writeGauge(&b, "fleet_run_passed", "Whether the run met its metric thresholds (1) or not (0).", label, passed)
+	}
+	w.Header().Set("Content-Type", prometheusContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(b.String()))
+}
+
+func writeGauge(b *strings.Builder, name, help, label string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s%s %s\n",
+		name, help, name, name, label, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// escapeLabelValue escapes backslash, double quote and newline per the exposition format.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
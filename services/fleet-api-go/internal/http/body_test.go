@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoBody replies with the request body it read, or 413 when the cap tripped.
+var echoBody = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	_, _ = w.Write(body)
+})
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func post(handler http.Handler, body []byte, encoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/runs", bytes.NewReader(body))
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestBodyGzip(t *testing.T) {
+	const payload = `{"mode":"baseline","seed":42}`
+	for _, encoding := range []string{"gzip", "GZIP", " gzip "} {
+		rec := post(withRequestBody(echoBody, 1024), gzipped(t, payload), encoding)
+		if rec.Code != http.StatusOK || rec.Body.String() != payload {
+			t.Errorf("encoding %q: status %d, body %q, want %q", encoding, rec.Code, rec.Body, payload)
+		}
+	}
+
+	rec := post(withRequestBody(echoBody, 1024), []byte(payload), "")
+	if rec.Code != http.StatusOK || rec.Body.String() != payload {
+		t.Errorf("plain body: status %d, body %q, want %q", rec.Code, rec.Body, payload)
+	}
+}
+
+func TestRequestBodyCorruptGzip(t *testing.T) {
+	rec := post(withRequestBody(echoBody, 1024), []byte("not gzip at all"), "gzip")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "invalid gzip body") {
+		t.Errorf("body %q, want the invalid gzip error", rec.Body)
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	big := strings.Repeat("x", 2048)
+	for _, tc := range []struct {
+		name     string
+		body     []byte
+		encoding string
+		max      int64
+		wantCode int
+	}{
+		{"plain body over the limit", []byte(big), "", 1024, http.StatusRequestEntityTooLarge},
+		// 2 KiB of x compresses to a few dozen bytes; the cap applies after decoding.
+		{"gzip body expanding past the limit", gzipped(t, big), "gzip", 1024, http.StatusRequestEntityTooLarge},
+		{"body at the limit", []byte(big[:1024]), "", 1024, http.StatusOK},
+		{"limit disabled", []byte(big), "", 0, http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := post(withRequestBody(echoBody, tc.max), tc.body, tc.encoding)
+			if rec.Code != tc.wantCode {
+				t.Errorf("status %d, want %d", rec.Code, tc.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+// File: internal/http/contenttype.go
+// Purpose: Content-Type enforcement for write requests.
+
+import (
+	"mime"
+	"net/http"
+)
+
+// withJSONContentType rejects POST/PUT/PATCH requests with a body whose Content-Type is
+// not application/json (parameters such as charset are allowed) with 415, so form data
+// fails clearly instead of as a JSON parse error. Bodyless writes (clone, cancel) pass.
+func withJSONContentType(next http.Handler, enabled bool) http.Handler {
+	if !enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONContentType(t *testing.T) {
+	handler := withJSONContentType(echoBody, true)
+	for _, tc := range []struct {
+		name, method, contentType string
+		body                      string
+		wantCode                  int
+	}{
+		{"json", http.MethodPost, "application/json", `{}`, http.StatusOK},
+		{"json with charset", http.MethodPost, "application/json; charset=utf-8", `{}`, http.StatusOK},
+		{"mixed case media type", http.MethodPatch, "Application/JSON", `[]`, http.StatusOK},
+		{"missing content type", http.MethodPost, "", `{}`, http.StatusUnsupportedMediaType},
+		{"form data", http.MethodPost, "application/x-www-form-urlencoded", "mode=ga", http.StatusUnsupportedMediaType},
+		{"text plain on put", http.MethodPut, "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"malformed content type", http.MethodPatch, "application/json; =", `{}`, http.StatusUnsupportedMediaType},
+		{"bodyless post", http.MethodPost, "", "", http.StatusOK},
+		{"get is not checked", http.MethodGet, "text/plain", "", http.StatusOK},
+		{"delete is not checked", http.MethodDelete, "text/plain", "x", http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var req *http.Request
+			if tc.body == "" {
+				req = httptest.NewRequest(tc.method, "/runs", nil)
+			} else {
+				req = httptest.NewRequest(tc.method, "/runs", strings.NewReader(tc.body))
+			}
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.wantCode {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.wantCode, rec.Body)
+			}
+			if tc.wantCode == http.StatusUnsupportedMediaType && !strings.Contains(rec.Body.String(), "Content-Type must be application/json") {
+				t.Errorf("body %q, want the content type error", rec.Body)
+			}
+		})
+	}
+}
+
+func TestJSONContentTypeDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/runs", strings.NewReader("mode=ga"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	withJSONContentType(echoBody, false).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status %d, want the check skipped", rec.Code)
+	}
+}
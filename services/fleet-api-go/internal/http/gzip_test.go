@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipTestBody is compressible but varied enough that gzip levels produce different output.
+var gzipTestBody = func() []byte {
+	var b strings.Builder
+	for i := range 2000 {
+		fmt.Fprintf(&b, `{"run_id":"run-%d","on_time_rate":0.%d,"total_distance":%d}`, i, i*7%1000, i*i%9973)
+	}
+	return []byte(b.String())
+}()
+
+func gzipTestHandler(level int) http.Handler {
+	return withResponseGzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/vary":
+			w.Header().Add("Vary", "Accept")
+			fallthrough
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(gzipTestBody)
+		}
+	}), level)
+}
+
+func gzipRequest(method, path, acceptEncoding string) *http.Request {
+	r := httptest.NewRequest(method, path, nil)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	return r
+}
+
+func TestResponseGzipAppliesConfiguredLevel(t *testing.T) {
+	for level := gzip.BestSpeed; level <= gzip.BestCompression; level++ {
+		rec := httptest.NewRecorder()
+		gzipTestHandler(level).ServeHTTP(rec, gzipRequest(http.MethodGet, "/", "gzip"))
+
+		if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("level %d: Content-Encoding = %q, want gzip", level, got)
+		}
+		var want bytes.Buffer
+		zw, err := gzip.NewWriterLevel(&want, level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = zw.Write(gzipTestBody)
+		_ = zw.Close()
+		if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+			t.Errorf("level %d: body differs from gzip output at that level (%d vs %d bytes)", level, rec.Body.Len(), want.Len())
+		}
+
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("level %d: %v", level, err)
+		}
+		plain, err := io.ReadAll(zr)
+		if err != nil || !bytes.Equal(plain, gzipTestBody) {
+			t.Fatalf("level %d: round trip mismatch (err %v)", level, err)
+		}
+	}
+}
+
+func TestResponseGzipPoolReuseKeepsStreamsIndependent(t *testing.T) {
+	h := gzipTestHandler(6)
+	for i := range 3 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, gzipRequest(http.MethodGet, "/", "gzip"))
+		zr, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if plain, _ := io.ReadAll(zr); !bytes.Equal(plain, gzipTestBody) {
+			t.Fatalf("request %d: round trip mismatch", i)
+		}
+	}
+}
+
+func TestResponseGzipPassThrough(t *testing.T) {
+	cases := []struct {
+		name, method, path, acceptEncoding string
+		level                              int
+	}{
+		{"disabled", http.MethodGet, "/", "gzip", 0},
+		{"no accept-encoding", http.MethodGet, "/", "", 6},
+		{"gzip refused", http.MethodGet, "/", "gzip;q=0, deflate", 6},
+		{"other encoding only", http.MethodGet, "/", "br", 6},
+		{"head", http.MethodHead, "/", "gzip", 6},
+		{"no content", http.MethodGet, "/empty", "gzip", 6},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			gzipTestHandler(tc.level).ServeHTTP(rec, gzipRequest(tc.method, tc.path, tc.acceptEncoding))
+			if got := rec.Header().Get("Content-Encoding"); got != "" {
+				t.Fatalf("Content-Encoding = %q, want none", got)
+			}
+			if tc.path == "/" && tc.method == http.MethodGet && !bytes.Equal(rec.Body.Bytes(), gzipTestBody) {
+				t.Fatal("body was modified")
+			}
+		})
+	}
+}
+
+func TestResponseGzipKeepsHandlerVary(t *testing.T) {
+	rec := httptest.NewRecorder()
+	gzipTestHandler(6).ServeHTTP(rec, gzipRequest(http.MethodGet, "/vary", "gzip"))
+	vary := rec.Header().Values("Vary")
+	if len(vary) != 2 || vary[0] != "Accept-Encoding" || vary[1] != "Accept" {
+		t.Fatalf("Vary = %q, want [Accept-Encoding Accept]", vary)
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                   false,
+		"gzip":               true,
+		"GZIP":               true,
+		"deflate, gzip":      true,
+		"gzip;q=0.5":         true,
+		"gzip; q=0":          false,
+		"gzip;q=0.000, br":   false,
+		"br, deflate":        false,
+		"x-gzip-like, other": false,
+	} {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func BenchmarkResponseGzipLevels(b *testing.B) {
+	for _, level := range []int{gzip.BestSpeed, 3, 6, gzip.BestCompression} {
+		b.Run(fmt.Sprintf("level=%d", level), func(b *testing.B) {
+			h := gzipTestHandler(level)
+			req := gzipRequest(http.MethodGet, "/", "gzip")
+			b.SetBytes(int64(len(gzipTestBody)))
+			b.ReportAllocs()
+			var compressed int
+			for range b.N {
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				compressed = rec.Body.Len()
+			}
+			b.ReportMetric(float64(compressed)/float64(len(gzipTestBody)), "ratio")
+		})
+	}
+}
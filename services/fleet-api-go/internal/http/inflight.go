@@ -0,0 +1,38 @@
+package http
+
+// File: internal/http/inflight.go
+// Purpose: In-flight request counting so shutdown can report what it is draining.
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// InFlight counts requests currently being served. One counter may be shared by
+// several routers (public and admin listeners).
+type InFlight struct {
+	n atomic.Int64
+}
+
+// NewInFlight returns a zeroed counter.
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Count reports the number of requests that have started but not yet returned.
+func (c *InFlight) Count() int64 {
+	return c.n.Load()
+}
+
+// withInFlight tracks each request for its full duration, including panics
+// unwinding through the handler. A nil counter disables tracking.
+func withInFlight(next http.Handler, c *InFlight) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.n.Add(1)
+		defer c.n.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInFlightCountsRequestsUntilTheyReturn(t *testing.T) {
+	c := NewInFlight()
+	const n = 5
+	entered := make(chan struct{}, n)
+	release := make(chan struct{})
+	handler := withInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}), c)
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/runs", nil))
+		}()
+	}
+	for range n {
+		<-entered
+	}
+	if got := c.Count(); got != n {
+		t.Errorf("Count = %d while %d requests are blocked, want %d", got, n, n)
+	}
+	close(release)
+	wg.Wait()
+	if got := c.Count(); got != 0 {
+		t.Errorf("Count = %d after every request returned, want 0", got)
+	}
+}
+
+func TestInFlightSurvivesPanics(t *testing.T) {
+	c := NewInFlight()
+	handler := withInFlight(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), c)
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/runs", nil))
+	}()
+	if got := c.Count(); got != 0 {
+		t.Errorf("Count = %d after a panicking request, want 0", got)
+	}
+}
+
+func TestInFlightSharedAcrossRouters(t *testing.T) {
+	c := NewInFlight()
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	register := func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /slow", func(http.ResponseWriter, *http.Request) {
+			entered <- struct{}{}
+			<-release
+		})
+	}
+	public := NewRouter(register, Options{InFlight: c})
+	admin := NewRouter(register, Options{InFlight: c})
+
+	var wg sync.WaitGroup
+	for _, router := range []http.Handler{public, admin} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+	}
+	<-entered
+	<-entered
+	if got := c.Count(); got != 2 {
+		t.Errorf("Count = %d, want one request per router", got)
+	}
+	close(release)
+	wg.Wait()
+
+	if withInFlight(http.NotFoundHandler(), nil) == nil {
+		t.Error("nil counter returned a nil handler")
+	}
+}
@@ -0,0 +1,143 @@
+package http
+
+// File: internal/http/jsoncase.go
+// Purpose: Buffered rewriting of JSON response bodies, and the optional camelCase
+// rewrite of response keys (JSON_FIELD_CASE=camel).
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// withCamelCaseJSON rewrites snake_case object keys in JSON responses to camelCase.
+// Struct tags stay snake_case; the transform runs on the encoded body, so every
+// handler is covered. Non-JSON responses pass through untouched.
+func withCamelCaseJSON(next http.Handler, enabled bool) http.Handler {
+	if !enabled {
+		return next
+	}
+	return withJSONRewrite(next, camelCaseJSON)
+}
+
+// withJSONRewrite buffers JSON response bodies and passes each through rewrite
+// before sending. A body rewrite cannot parse is sent unchanged.
+func withJSONRewrite(next http.Handler, rewrite func(body []byte) ([]byte, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jw := &jsonRewriteWriter{ResponseWriter: w, status: http.StatusOK, rewrite: rewrite}
+		next.ServeHTTP(jw, r)
+		jw.finish()
+	})
+}
+
+// jsonRewriteWriter buffers JSON bodies so they can be rewritten before sending.
+type jsonRewriteWriter struct {
+	http.ResponseWriter
+	status    int
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+	rewrite   func(body []byte) ([]byte, error)
+}
+
+func (w *jsonRewriteWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *jsonRewriteWriter) WriteHeader(status int) {
+	w.decide()
+	if w.buffering {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *jsonRewriteWriter) Write(p []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.buf.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *jsonRewriteWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *jsonRewriteWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf.Bytes()
+	if rewritten, err := w.rewrite(body); err == nil {
+		body = rewritten
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// camelCaseJSON re-encodes a JSON document with camelCase object keys. Numbers are
+// kept verbatim so integer and float formatting does not change.
+func camelCaseJSON(body []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(camelCaseKeys(doc)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func camelCaseKeys(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for key, value := range t {
+			out[snakeToCamel(key)] = camelCaseKeys(value)
+		}
+		return out
+	case []any:
+		for i := range t {
+			t[i] = camelCaseKeys(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// snakeToCamel converts "on_time_rate" to "onTimeRate"; keys without underscores are unchanged.
+func snakeToCamel(key string) string {
+	if !strings.Contains(key, "_") {
+		return key
+	}
+	var b strings.Builder
+	upper := false
+	for i, r := range key {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSnakeToCamel(t *testing.T) {
+	for in, want := range map[string]string{
+		"on_time_rate":      "onTimeRate",
+		"run_id":            "runId",
+		"seed":              "seed",
+		"alreadyCamel":      "alreadyCamel",
+		"_private":          "_private",
+		"trailing_":         "trailing",
+		"double__underline": "doubleUnderline",
+		"p95_ms":            "p95Ms",
+	} {
+		if got := snakeToCamel(in); got != want {
+			t.Errorf("snakeToCamel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCamelCaseJSONRewritesNestedKeys(t *testing.T) {
+	body := `{"run_id":"run-1","on_time_rate":0.90,"total_jobs":50,` +
+		`"effective":{"fleet_source":"preset"},"runs":[{"error_message":"x","retry_of":null}],"tags":["exp:q3","owner_alice"]}`
+	rec := serveJSON(body, "application/json; charset=utf-8", Options{CamelCaseJSON: true})
+	// Keys are rewritten at every depth; values (including snake_case strings) and
+	// number formatting are left alone.
+	want := `{"effective":{"fleetSource":"preset"},"onTimeRate":0.90,"runId":"run-1",` +
+		`"runs":[{"errorMessage":"x","retryOf":null}],"tags":["exp:q3","owner_alice"],"totalJobs":50}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("body = %s\nwant %s", got, want)
+	}
+	if rec.Code != http.StatusAccepted || rec.Header().Get("Content-Length") != "" {
+		t.Errorf("status %d, Content-Length %q; want 202 and no stale length", rec.Code, rec.Header().Get("Content-Length"))
+	}
+}
+
+func TestCamelCaseJSONOffOrNotJSON(t *testing.T) {
+	body := `{"run_id":"run-1"}`
+	for _, tc := range []struct {
+		name, contentType string
+		opts              Options
+	}{
+		{"disabled", "application/json", Options{}},
+		{"text", "text/plain", Options{CamelCaseJSON: true}},
+		{"prometheus", "text/plain; version=0.0.4", Options{CamelCaseJSON: true}},
+	} {
+		if got := serveJSON(body, tc.contentType, tc.opts).Body.String(); got != body {
+			t.Errorf("%s: body = %s, want it unchanged", tc.name, got)
+		}
+	}
+	if got := serveJSON(`{"run_id":`, "application/json", Options{CamelCaseJSON: true}).Body.String(); got != `{"run_id":` {
+		t.Errorf("invalid json: body = %s, want it unchanged", got)
+	}
+}
@@ -0,0 +1,60 @@
+package http
+
+// File: internal/http/omitnulls.go
+// Purpose: Optional removal of null-valued fields from JSON responses (OMIT_NULLS).
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// withOmitNulls removes object fields whose value is null from JSON responses, at
+// every depth, so clients see no "completed_at": null. Null array elements are kept.
+// Like withCamelCaseJSON it works on the encoded body, covering every handler.
+func withOmitNulls(next http.Handler, enabled bool) http.Handler {
+	if !enabled {
+		return next
+	}
+	return withJSONRewrite(next, omitNullsJSON)
+}
+
+// omitNullsJSON re-encodes a JSON document without null object fields. Object keys
+// come out in alphabetical order; numbers are kept verbatim.
+func omitNullsJSON(body []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.NewEncoder(&out).Encode(dropNulls(doc)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func dropNulls(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			if value == nil {
+				delete(t, key)
+				continue
+			}
+			t[key] = dropNulls(value)
+		}
+		return t
+	case []any:
+		for i := range t {
+			t[i] = dropNulls(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
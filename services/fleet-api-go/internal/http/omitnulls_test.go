@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const nullsTestBody = `{"run_id":"run-1","completed_at":null,"seed":42,"on_time_rate":0.90,` +
+	`"metrics":{"max_lateness":null,"total_jobs":50},"notes":[null,{"text":null,"author":"ops"}]}`
+
+func serveJSON(body, contentType string, opts Options) *httptest.ResponseRecorder {
+	router := NewRouter(func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /run", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Length", "999")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(body))
+		})
+	}, opts)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/run", nil))
+	return rec
+}
+
+func TestOmitNullsOff(t *testing.T) {
+	rec := serveJSON(nullsTestBody, "application/json", Options{})
+	if got := rec.Body.String(); got != nullsTestBody {
+		t.Fatalf("body = %s, want it unchanged", got)
+	}
+}
+
+func TestOmitNullsOn(t *testing.T) {
+	rec := serveJSON(nullsTestBody, "application/json", Options{OmitNulls: true})
+	want := `{"metrics":{"total_jobs":50},"notes":[null,{"author":"ops"}],"on_time_rate":0.90,"run_id":"run-1","seed":42}` + "\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("body = %s, want %s", got, want)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status %d, want the handler's 202", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want it dropped after the rewrite", got)
+	}
+}
+
+func TestOmitNullsWithCamelCase(t *testing.T) {
+	rec := serveJSON(`{"run_id":"run-1","completed_at":null}`, "application/json", Options{OmitNulls: true, CamelCaseJSON: true})
+	if got, want := rec.Body.String(), `{"runId":"run-1"}`+"\n"; got != want {
+		t.Fatalf("body = %s, want %s", got, want)
+	}
+}
+
+func TestOmitNullsLeavesOtherBodiesAlone(t *testing.T) {
+	for _, tc := range []struct{ name, body, contentType string }{
+		{"text", `{"a":null}`, "text/plain"},
+		{"invalid json", `{"a":null`, "application/json"},
+		{"empty", "", "application/json"},
+	} {
+		rec := serveJSON(tc.body, tc.contentType, Options{OmitNulls: true})
+		if got := rec.Body.String(); got != tc.body {
+			t.Errorf("%s: body = %q, want it unchanged", tc.name, got)
+		}
+	}
+}
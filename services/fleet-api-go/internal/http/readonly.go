@@ -0,0 +1,22 @@
+package http
+
+// File: internal/http/readonly.go
+// Purpose: Read-only (maintenance) mode middleware.
+
+import "net/http"
+
+// withReadOnly rejects every request that can write (anything but GET/HEAD/OPTIONS)
+// with 503 while enabled; reads, including /health, keep being served.
+func withReadOnly(next http.Handler, enabled bool) http.Handler {
+	if !enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+		default:
+			writeError(w, http.StatusServiceUnavailable, "service is in read-only mode for maintenance; write requests are disabled")
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	router := NewRouter(testRoutes, Options{ReadOnly: true})
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		rec := get(router, method, "/runs")
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s /runs: status %d, want 503", method, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), "read-only mode") {
+			t.Errorf("%s /runs: body %q, want the read-only error", method, rec.Body)
+		}
+	}
+}
+
+func TestReadOnlyServesReads(t *testing.T) {
+	router := NewRouter(testRoutes, Options{ReadOnly: true, BasePath: "/api", ExemptPaths: []string{"/health"}})
+	for _, tc := range []struct{ method, path, want string }{
+		{http.MethodGet, "/api/runs", "GET /runs"},
+		{http.MethodGet, "/api/runs/run-1", "GET /runs/{id}"},
+		{http.MethodGet, "/health", "GET /health"},
+		{http.MethodHead, "/health", "GET /health"}, // GET patterns also match HEAD
+	} {
+		rec := get(router, tc.method, tc.path)
+		if rec.Code != http.StatusOK || rec.Body.String() != tc.want {
+			t.Errorf("%s %s: status %d, body %q, want 200 %q", tc.method, tc.path, rec.Code, rec.Body, tc.want)
+		}
+	}
+	if rec := get(router, http.MethodOptions, "/api/runs"); rec.Code != http.StatusNoContent {
+		t.Errorf("OPTIONS /api/runs: status %d, want the 204 preflight", rec.Code)
+	}
+}
+
+func TestReadOnlyOff(t *testing.T) {
+	rec := get(NewRouter(testRoutes, Options{}), http.MethodPost, "/runs")
+	if rec.Code != http.StatusOK || rec.Body.String() != "POST /runs" {
+		t.Errorf("POST /runs: status %d, body %q, want it served", rec.Code, rec.Body)
+	}
+}
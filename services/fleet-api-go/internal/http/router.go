@@ -2,25 +2,81 @@
 package http
 
 // File: internal/http/router.go
-// Purpose: Construct mux and apply CORS + request logging middleware.
+// Purpose: Construct mux and apply CORS, response compression and (sampled) request logging middleware.
 
 import (
+	"log"
 	"net/http"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
-// NewRouter builds an HTTP handler with CORS and request logging.
-func NewRouter(register func(mux *http.ServeMux)) http.Handler {
+// Options configures the router.
+type Options struct {
+	// BasePath mounts every route under a prefix such as "/api/v1". Empty keeps routes at the root.
+	BasePath string
+	// ExemptPaths are still served unprefixed when BasePath is set (e.g. "/health" for probes).
+	ExemptPaths []string
+	// SampledPaths are logged only once every SampleRate requests (errors are always logged).
+	SampledPaths []string
+	// SampleRate is the 1-in-N rate for SampledPaths; values <= 1 log every request.
+	SampleRate int
+	// MaxBodyBytes caps request bodies after gzip decoding; <= 0 disables the cap.
+	MaxBodyBytes int64
+	// ReadOnly rejects write methods with 503 (maintenance mode).
+	ReadOnly bool
+	// RequireJSON rejects POST/PUT/PATCH bodies not sent as application/json with 415.
+	RequireJSON bool
+	// CamelCaseJSON rewrites JSON response keys from snake_case to camelCase.
+	CamelCaseJSON bool
+	// OmitNulls removes null-valued fields from JSON responses.
+	OmitNulls bool
+	// InFlight, when set, counts requests being served (logged while draining on shutdown).
+	InFlight *InFlight
+	// GzipLevel compresses responses for gzip-capable clients at this level (1-9); 0 disables it.
+	GzipLevel int
+}
+
+// NewRouter builds an HTTP handler with CORS, response compression and request logging.
+func NewRouter(register func(mux *http.ServeMux), opts Options) http.Handler {
 	mux := http.NewServeMux()
 	register(mux)
-	return withCORS(withRequestLogging(mux))
+	handler := withJSONContentType(withRequestBody(withBasePath(withTrailingSlash(mux), opts), opts.MaxBodyBytes), opts.RequireJSON)
+	handler = withReadOnly(handler, opts.ReadOnly)
+	handler = withOmitNulls(handler, opts.OmitNulls)
+	handler = withCamelCaseJSON(handler, opts.CamelCaseJSON)
+	handler = withResponseGzip(handler, opts.GzipLevel)
+	return withInFlight(withCORS(withRequestLogging(handler, newLogSampler(opts))), opts.InFlight)
+}
+
+// withBasePath serves mux under opts.BasePath, plus any exempt paths at the root.
+func withBasePath(mux http.Handler, opts Options) http.Handler {
+	base := strings.TrimRight(opts.BasePath, "/")
+	if base == "" {
+		return mux
+	}
+	if !strings.HasPrefix(base, "/") {
+		base = "/" + base
+	}
+	root := http.NewServeMux()
+	root.Handle(base+"/", http.StripPrefix(base, mux))
+	for _, path := range opts.ExemptPaths {
+		root.Handle(path, mux)
+		// Let the trailing-slash variant (/health/) through too, so withTrailingSlash can serve it.
+		if !strings.HasSuffix(path, "/") {
+			root.Handle(path+"/{$}", mux)
+		}
+	}
+	return root
 }
 
 func withCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Encoding")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PATCH,OPTIONS")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -29,10 +85,64 @@ func withCORS(next http.Handler) http.Handler {
 	})
 }
 
-func withRequestLogging(next http.Handler) http.Handler {
+func withRequestLogging(next http.Handler, sampler *logSampler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		_ = start
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if !sampler.shouldLog(r.URL.Path, rec.status) {
+			return
+		}
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Microsecond))
 	})
 }
+
+// logSampler decides whether a request is logged. Paths in the sampled set
+// (typically health probes) are logged once every rate requests.
+type logSampler struct {
+	rate     uint64
+	counters map[string]*atomic.Uint64
+}
+
+func newLogSampler(opts Options) *logSampler {
+	s := &logSampler{counters: map[string]*atomic.Uint64{}}
+	if opts.SampleRate > 1 {
+		s.rate = uint64(opts.SampleRate)
+	}
+	base := strings.TrimRight(opts.BasePath, "/")
+	for _, path := range opts.SampledPaths {
+		counter := &atomic.Uint64{}
+		s.counters[path] = counter
+		if base != "" {
+			s.counters[base+path] = counter
+		}
+	}
+	return s
+}
+
+func (s *logSampler) shouldLog(path string, status int) bool {
+	if s.rate == 0 || status >= http.StatusBadRequest {
+		return true
+	}
+	counter, ok := s.counters[path]
+	if !ok {
+		return true
+	}
+	return (counter.Add(1)-1)%s.rate == 0
+}
+
+// statusRecorder captures the response status for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
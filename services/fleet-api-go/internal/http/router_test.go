@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testRoutes registers a run list and a health probe that echo their matched pattern.
+func testRoutes(mux *http.ServeMux) {
+	for _, pattern := range []string{"GET /health", "GET /runs", "POST /runs", "GET /runs/{id}"} {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(pattern))
+		})
+	}
+}
+
+// get sends method path through handler and returns the recorded response.
+func get(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestRouterBasePath(t *testing.T) {
+	for _, tc := range []struct {
+		name, basePath string
+		exempt         []string
+		path           string
+		want           string // matched pattern, or "" for 404
+	}{
+		{"no base path", "", nil, "/runs", "GET /runs"},
+		{"prefixed route", "/api/v1", nil, "/api/v1/runs", "GET /runs"},
+		{"prefixed path value", "/api/v1", nil, "/api/v1/runs/run-1", "GET /runs/{id}"},
+		{"trailing slash in the base path", "/api/v1/", nil, "/api/v1/runs", "GET /runs"},
+		{"base path without a leading slash", "api", nil, "/api/runs", "GET /runs"},
+		{"unprefixed route is gone", "/api/v1", nil, "/runs", ""},
+		{"health is prefixed too", "/api/v1", nil, "/health", ""},
+		{"exempt health stays at the root", "/api/v1", []string{"/health"}, "/health", "GET /health"},
+		{"exempt health is also prefixed", "/api/v1", []string{"/health"}, "/api/v1/health", "GET /health"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewRouter(testRoutes, Options{BasePath: tc.basePath, ExemptPaths: tc.exempt})
+			rec := get(router, http.MethodGet, tc.path)
+			if tc.want == "" {
+				if rec.Code != http.StatusNotFound {
+					t.Errorf("GET %s: status %d, want 404", tc.path, rec.Code)
+				}
+				return
+			}
+			if rec.Code != http.StatusOK || rec.Body.String() != tc.want {
+				t.Errorf("GET %s: status %d, body %q, want %q", tc.path, rec.Code, rec.Body, tc.want)
+			}
+		})
+	}
+}
+
+func TestLogSampler(t *testing.T) {
+	s := newLogSampler(Options{SampledPaths: []string{"/health"}, SampleRate: 3, BasePath: "/api"})
+	var logged []bool
+	for range 7 {
+		logged = append(logged, s.shouldLog("/health", http.StatusOK))
+	}
+	if want := []bool{true, false, false, true, false, false, true}; !slices.Equal(logged, want) {
+		t.Errorf("sampled /health logs = %v, want %v", logged, want)
+	}
+	// The prefixed path shares the counter, so the next one continues the cycle.
+	if s.shouldLog("/api/health", http.StatusOK) {
+		t.Error("/api/health logged off-cycle; want it to share the /health counter")
+	}
+	if !s.shouldLog("/health", http.StatusServiceUnavailable) {
+		t.Error("a failing probe was sampled away; errors are always logged")
+	}
+	if !s.shouldLog("/runs", http.StatusOK) {
+		t.Error("an unsampled path was not logged")
+	}
+
+	for _, rate := range []int{0, 1} {
+		s := newLogSampler(Options{SampledPaths: []string{"/health"}, SampleRate: rate})
+		if !s.shouldLog("/health", http.StatusOK) || !s.shouldLog("/health", http.StatusOK) {
+			t.Errorf("rate %d: sampled a request, want every request logged", rate)
+		}
+	}
+}
+
+func TestRouterSamplesAccessLogs(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	router := NewRouter(testRoutes, Options{SampledPaths: []string{"/health"}, SampleRate: 10})
+	for range 10 {
+		get(router, http.MethodGet, "/health")
+	}
+	get(router, http.MethodGet, "/runs")
+	if got := strings.Count(buf.String(), "GET /health 200"); got != 1 {
+		t.Errorf("logged /health %d times in 10, want 1:\n%s", got, buf.String())
+	}
+	if !strings.Contains(buf.String(), "GET /runs 200") {
+		t.Errorf("unsampled /runs not logged:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,37 @@
+package http
+
+// File: internal/http/trailingslash.go
+// Purpose: Serve trailing-slash variants of registered routes (/runs/ as /runs).
+
+import (
+	"net/http"
+	"strings"
+)
+
+// withTrailingSlash rewrites a request path ending in "/" to the same path without
+// it when only the trimmed form matches a route on mux, so POST /runs/ is served as
+// POST /runs instead of 404. It is an internal rewrite rather than a redirect, which
+// clients would not follow for POST bodies. Paths that already match a pattern,
+// such as /debug/pprof/ or a /runs/{id} value, are left alone.
+func withTrailingSlash(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) <= 1 || !strings.HasSuffix(r.URL.Path, "/") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		trimmed := r.Clone(r.Context())
+		trimmed.URL.Path = strings.TrimSuffix(r.URL.Path, "/")
+		if r.URL.RawPath != "" {
+			trimmed.URL.RawPath = strings.TrimSuffix(r.URL.RawPath, "/")
+		}
+		if _, pattern := mux.Handler(trimmed); pattern == "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, trimmed)
+	})
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTrailingSlash(t *testing.T) {
+	for _, tc := range []struct {
+		name, basePath string
+		exempt         []string
+		method, path   string
+		want           string // matched pattern, or "" for 404
+	}{
+		{"GET /runs/", "", nil, http.MethodGet, "/runs/", "GET /runs"},
+		{"POST /runs/", "", nil, http.MethodPost, "/runs/", "POST /runs"},
+		{"GET /health/", "", nil, http.MethodGet, "/health/", "GET /health"},
+		{"path value is kept", "", nil, http.MethodGet, "/runs/run-1", "GET /runs/{id}"},
+		{"path value with a trailing slash", "", nil, http.MethodGet, "/runs/run-1/", "GET /runs/{id}"},
+		{"unknown path stays 404", "", nil, http.MethodGet, "/nope/", ""},
+		{"base path /runs/", "/api/v1", nil, http.MethodGet, "/api/v1/runs/", "GET /runs"},
+		{"base path POST /runs/", "/api/v1", nil, http.MethodPost, "/api/v1/runs/", "POST /runs"},
+		{"base path /health/", "/api/v1", nil, http.MethodGet, "/api/v1/health/", "GET /health"},
+		{"exempt /health/ at the root", "/api/v1", []string{"/health"}, http.MethodGet, "/health/", "GET /health"},
+		{"exempt subpaths are not exempt", "/api/v1", []string{"/health"}, http.MethodGet, "/health/deep", ""},
+		{"unprefixed /runs/ is still gone", "/api/v1", nil, http.MethodGet, "/runs/", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewRouter(testRoutes, Options{BasePath: tc.basePath, ExemptPaths: tc.exempt})
+			rec := get(router, tc.method, tc.path)
+			if tc.want == "" {
+				if rec.Code != http.StatusNotFound {
+					t.Errorf("%s %s: status %d, want 404", tc.method, tc.path, rec.Code)
+				}
+				return
+			}
+			if rec.Code != http.StatusOK || rec.Body.String() != tc.want {
+				t.Errorf("%s %s: status %d, body %q, want %q", tc.method, tc.path, rec.Code, rec.Body, tc.want)
+			}
+		})
+	}
+}
+
+func TestTrailingSlashKeepsSubtreePatterns(t *testing.T) {
+	router := NewRouter(func(mux *http.ServeMux) {
+		for _, pattern := range []string{"GET /debug/pprof/", "GET /debug/pprof"} {
+			mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(pattern))
+			})
+		}
+	}, Options{})
+	// /debug/pprof/ matches its own pattern, so it must not be rewritten to /debug/pprof.
+	if rec := get(router, http.MethodGet, "/debug/pprof/"); rec.Body.String() != "GET /debug/pprof/" {
+		t.Errorf("GET /debug/pprof/ served by %q, want the subtree pattern", rec.Body)
+	}
+	if rec := get(router, http.MethodGet, "/debug/pprof/heap"); rec.Body.String() != "GET /debug/pprof/" {
+		t.Errorf("GET /debug/pprof/heap served by %q, want the subtree pattern", rec.Body)
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+// File: internal/models/events.go
+// Purpose: Typed payloads for domain events published by fleet-api.
+
+// RunCreatedEvent is the run.created payload consumed by sim-runner. The legacy
+// run.started alias published during the compatibility window carries the same fields.
+type RunCreatedEvent struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	RunID     string `json:"run_id"`
+	Mode      string `json:"mode"`
+	Seed      int    `json:"seed"`
+	Scale     string `json:"scale"`
+	SimTimeS  int    `json:"sim_time_s"`
+	Robots    *int   `json:"robots,omitempty"`
+	Jobs      *int   `json:"jobs,omitempty"`
+	// RunStartedAlias tells sim-runner whether fleet-api also published run.started
+	// for this run; when false, sim-runner publishes run.started as the simulation begins.
+	RunStartedAlias *bool     `json:"run_started_alias,omitempty"`
+	GAParams        *GAParams `json:"ga_params,omitempty"`
+	RetryOf         *string   `json:"retry_of,omitempty"`
+	// ReplanIntervalS is the periodic GA replan interval for the run (its override,
+	// else GA_REPLAN_INTERVAL_S; 0 disables periodic replans). Unset for baseline runs.
+	ReplanIntervalS *int `json:"replan_interval_s,omitempty"`
+}
+
+// RunCancelledEvent is the run.cancelled payload. It carries the scenario identity
+// so sim-runner can stop the matching simulation without a database lookup.
+type RunCancelledEvent struct {
+	EventID             string `json:"event_id"`
+	EventType           string `json:"event_type"`
+	RunID               string `json:"run_id"`
+	Mode                string `json:"mode"`
+	Seed                int    `json:"seed"`
+	Scale               string `json:"scale"`
+	Robots              *int   `json:"robots,omitempty"`
+	Jobs                *int   `json:"jobs,omitempty"`
+	ScenarioHash        string `json:"scenario_hash"`
+	ScenarioHashVersion int    `json:"scenario_hash_version"`
+	Reason              string `json:"reason,omitempty"`
+}
+
+// Payload converts the event into the map shape accepted by the publisher.
+func (e RunCancelledEvent) Payload() map[string]any {
+	payload := map[string]any{
+		"event_id":              e.EventID,
+		"event_type":            e.EventType,
+		"run_id":                e.RunID,
+		"mode":                  e.Mode,
+		"seed":                  e.Seed,
+		"scale":                 e.Scale,
+		"scenario_hash":         e.ScenarioHash,
+		"scenario_hash_version": e.ScenarioHashVersion,
+	}
+	if e.Robots != nil && e.Jobs != nil {
+		payload["robots"] = *e.Robots
+		payload["jobs"] = *e.Jobs
+	}
+	if e.Reason != "" {
+		payload["reason"] = e.Reason
+	}
+	return payload
+}
+
+// Payload converts the event into the map shape accepted by the publisher.
+// Robots and jobs are only included when both overrides are present.
+func (e RunCreatedEvent) Payload() map[string]any {
+	payload := map[string]any{
+		"event_id":   e.EventID,
+		"event_type": e.EventType,
+		"run_id":     e.RunID,
+		"mode":       e.Mode,
+		"seed":       e.Seed,
+		"scale":      e.Scale,
+		"sim_time_s": e.SimTimeS,
+	}
+	if e.Robots != nil && e.Jobs != nil {
+		payload["robots"] = *e.Robots
+		payload["jobs"] = *e.Jobs
+	}
+	if e.RunStartedAlias != nil {
+		payload["run_started_alias"] = *e.RunStartedAlias
+	}
+	if e.GAParams != nil {
+		payload["ga_params"] = e.GAParams
+	}
+	if e.RetryOf != nil {
+		payload["retry_of"] = *e.RetryOf
+	}
+	if e.ReplanIntervalS != nil {
+		payload["replan_interval_s"] = *e.ReplanIntervalS
+	}
+	return payload
+}
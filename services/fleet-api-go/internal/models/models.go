@@ -4,62 +4,701 @@ package models
 // File: internal/models/models.go
 // Purpose: Shared data structures for runs and metrics.
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-// Run models the runs table and API payloads.
+// Run models the runs table and API payloads. StartedAt is nil when the (nullable)
+// started_at column is NULL.
 type Run struct {
-	ID           string     `json:"id"`
-	Mode         string     `json:"mode"`
-	Seed         int        `json:"seed"`
-	Scale        string     `json:"scale"`
-	RobotsCount  *int       `json:"robots_count,omitempty"`
-	JobsCount    *int       `json:"jobs_count,omitempty"`
-	ScenarioHash string     `json:"scenario_hash"`
-	Status       string     `json:"status"`
-	ErrorMessage *string    `json:"error_message,omitempty"`
-	CreatedAt    time.Time  `json:"created_at"`
-	StartedAt    time.Time  `json:"started_at"`
-	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ID                  string     `json:"id"`
+	Mode                string     `json:"mode"`
+	Seed                int        `json:"seed"`
+	Scale               string     `json:"scale"`
+	RobotsCount         *int       `json:"robots_count,omitempty"`
+	JobsCount           *int       `json:"jobs_count,omitempty"`
+	ScenarioHash        string     `json:"scenario_hash"`
+	ScenarioHashVersion int        `json:"scenario_hash_version"`
+	Status              string     `json:"status"`
+	ErrorMessage        *string    `json:"error_message,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	StartedAt           *time.Time `json:"started_at"`
+	CompletedAt         *time.Time `json:"completed_at,omitempty"`
+	GAParams            *GAParams  `json:"ga_params,omitempty"`
+	// RetryOf is the failed run this run retries (POST /runs/{id}/retry).
+	RetryOf *string `json:"retry_of,omitempty"`
+	// ExperimentID groups the run into an experiment (POST /experiments).
+	ExperimentID *string `json:"experiment_id,omitempty"`
+	// ProgressPct is the simulator-reported share of jobs finished (0-100).
+	ProgressPct *float64 `json:"progress_pct,omitempty"`
+	// ReplanIntervalS is the run's GA_REPLAN_INTERVAL_S override, if one was requested.
+	ReplanIntervalS *int `json:"replan_interval_s,omitempty"`
+	// DurationSeconds is completed_at - started_at, set on reads of finished runs.
+	DurationSeconds *int64 `json:"duration_seconds,omitempty"`
+	// Tags are the labels set on POST /runs, sorted (run_tags table).
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RunStatusResponse is the response payload for GET /runs/{id}/status.
+type RunStatusResponse struct {
+	ID           string   `json:"id"`
+	Status       string   `json:"status"`
+	ErrorMessage *string  `json:"error_message"`
+	ProgressPct  *float64 `json:"progress_pct"`
+}
+
+// RunProgressUpdate is the request payload for PATCH /runs/{id}/progress.
+type RunProgressUpdate struct {
+	ProgressPct *float64 `json:"progress_pct"`
 }
 
 // RunMetrics models the run_metrics table and API payloads.
 type RunMetrics struct {
-	RunID             string  `json:"run_id"`
-	OnTimeRate        float64 `json:"on_time_rate"`
-	TotalDistance     float64 `json:"total_distance"`
-	AvgCompletionTime float64 `json:"avg_completion_time"`
-	MaxLateness       float64 `json:"max_lateness"`
-	CompletedJobs     int     `json:"completed_jobs"`
-	FailedJobs        int     `json:"failed_jobs"`
-	TotalJobs         int     `json:"total_jobs"`
+	RunID             string    `json:"run_id"`
+	OnTimeRate        float64   `json:"on_time_rate"`
+	TotalDistance     float64   `json:"total_distance"`
+	AvgCompletionTime float64   `json:"avg_completion_time"`
+	MaxLateness       float64   `json:"max_lateness"`
+	CompletedJobs     int       `json:"completed_jobs"`
+	FailedJobs        int       `json:"failed_jobs"`
+	TotalJobs         int       `json:"total_jobs"`
+	ComputedAt        time.Time `json:"computed_at"`
+	// Passed and FailedCriteria are set only when thresholds were evaluated.
+	Passed         *bool              `json:"passed,omitempty"`
+	FailedCriteria []ThresholdFailure `json:"failed_criteria,omitempty"`
+	// RunStatus is the owning run's status; used for caching decisions, not serialized.
+	RunStatus string `json:"-"`
+}
+
+// BulkMetricsResponse is the response payload for GET /runs/metrics.
+type BulkMetricsResponse struct {
+	Metrics []RunMetrics `json:"metrics"`
+	// Missing lists requested run IDs that have no metrics yet (or do not exist).
+	Missing []string `json:"missing"`
 }
 
 // CreateRunRequest is the request payload for POST /runs.
 type CreateRunRequest struct {
-	Mode   string `json:"mode"`
-	Seed   *int   `json:"seed,omitempty"`
-	Scale  string `json:"scale,omitempty"`
-	Robots *int   `json:"robots,omitempty"`
-	Jobs   *int   `json:"jobs,omitempty"`
+	Mode string `json:"mode"`
+	Seed *int   `json:"seed,omitempty"`
+	// RandomSeed asks the server to pick a crypto-random seed; mutually exclusive with Seed.
+	RandomSeed bool   `json:"random_seed,omitempty"`
+	Scale      string `json:"scale,omitempty"`
+	Robots     *int   `json:"robots,omitempty"`
+	Jobs       *int   `json:"jobs,omitempty"`
+	// StrictFleet rejects runs with more robots than jobs; nil uses STRICT_FLEET.
+	StrictFleet *bool `json:"strict_fleet,omitempty"`
+	// GAParams tunes the optimizer for this run; only accepted for mode ga.
+	GAParams *GAParams `json:"ga_params,omitempty"`
+	// ExperimentID attaches the run to an existing experiment.
+	ExperimentID *string `json:"experiment_id,omitempty"`
+	// ReplanIntervalS overrides GA_REPLAN_INTERVAL_S for this run; only accepted for mode ga.
+	ReplanIntervalS *int `json:"replan_interval_s,omitempty"`
+	// Tags label the run for GET /runs?tag=... filtering (e.g. "exp:q3", "owner:alice").
+	Tags []string `json:"tags,omitempty"`
+}
+
+// GAParams overrides the optimizer's genetic-algorithm settings for one run.
+// Unset fields keep the optimizer-service defaults (GA_* env vars).
+type GAParams struct {
+	PopulationSize *int     `json:"population_size,omitempty"`
+	Generations    *int     `json:"generations,omitempty"`
+	EliteSize      *int     `json:"elite_size,omitempty"`
+	MutationRate   *float64 `json:"mutation_rate,omitempty"`
+	CrossoverRate  *float64 `json:"crossover_rate,omitempty"`
+}
+
+// CloneRunRequest is the optional request payload for POST /runs/{id}/clone.
+// Unset fields keep the original run's values.
+type CloneRunRequest struct {
+	Mode string `json:"mode,omitempty"`
+	Seed *int   `json:"seed,omitempty"`
 }
 
 // CreateRunResponse is the response payload for POST /runs.
 type CreateRunResponse struct {
+	RunID     string    `json:"run_id"`
+	Mode      string    `json:"mode"`
+	Seed      int       `json:"seed"`
+	Scale     string    `json:"scale"`
+	Robots    *int      `json:"robots,omitempty"`
+	Jobs      *int      `json:"jobs,omitempty"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	GAParams  *GAParams `json:"ga_params,omitempty"`
+	RetryOf   *string   `json:"retry_of,omitempty"`
+	// ExperimentID is the experiment the run was attached to, if any.
+	ExperimentID *string `json:"experiment_id,omitempty"`
+	// ReplanIntervalS echoes the requested GA_REPLAN_INTERVAL_S override.
+	ReplanIntervalS *int `json:"replan_interval_s,omitempty"`
+	// Tags echoes the stored tags, deduplicated and sorted.
+	Tags []string `json:"tags,omitempty"`
+	// Effective is the fleet size the run simulates, whether from overrides or the scale preset.
+	Effective EffectiveFleet `json:"effective"`
+}
+
+// RunWaitResponse is the response payload for POST /runs/wait. Run and Metrics are
+// set once the run is terminal; TimedOut runs carry only the ID and last status.
+// Error is set when the run was created but reading its outcome failed.
+type RunWaitResponse struct {
+	RunID    string      `json:"run_id"`
+	Status   string      `json:"status"`
+	TimedOut bool        `json:"timed_out"`
+	Run      *Run        `json:"run,omitempty"`
+	Metrics  *RunMetrics `json:"metrics,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// EffectiveFleet is a run's resolved robot and job counts; Source is "override" or "preset".
+type EffectiveFleet struct {
+	Robots int    `json:"robots"`
+	Jobs   int    `json:"jobs"`
+	Source string `json:"source"`
+}
+
+// CancelRunRequest is the optional request payload for POST /runs/{id}/cancel.
+type CancelRunRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CancelRunResponse is the response payload for POST /runs/{id}/cancel.
+type CancelRunResponse struct {
 	RunID  string `json:"run_id"`
-	Mode   string `json:"mode"`
-	Seed   int    `json:"seed"`
-	Scale  string `json:"scale"`
-	Robots *int   `json:"robots,omitempty"`
-	Jobs   *int   `json:"jobs,omitempty"`
 	Status string `json:"status"`
+	// Published is false when the status change committed but run.cancelled could not be published.
+	Published bool `json:"published"`
+}
+
+// RepublishRunResponse is the response payload for POST /runs/{id}/republish.
+type RepublishRunResponse struct {
+	RunID      string `json:"run_id"`
+	Status     string `json:"status"`
+	RoutingKey string `json:"routing_key"`
+	Forced     bool   `json:"forced"`
 }
 
 // CompareRunsResponse returns the latest baseline/GA metrics for a scenario.
 type CompareRunsResponse struct {
-	Seed     int         `json:"seed"`
-	Scale    string      `json:"scale"`
-	Robots   *int        `json:"robots,omitempty"`
-	Jobs     *int        `json:"jobs,omitempty"`
-	Baseline *RunMetrics `json:"baseline,omitempty"`
-	GA       *RunMetrics `json:"ga,omitempty"`
+	Seed     int           `json:"seed"`
+	Scale    string        `json:"scale"`
+	Robots   *int          `json:"robots,omitempty"`
+	Jobs     *int          `json:"jobs,omitempty"`
+	Baseline *RunMetrics   `json:"baseline,omitempty"`
+	GA       *RunMetrics   `json:"ga,omitempty"`
+	Delta    *CompareDelta `json:"delta,omitempty"`
+	// MissingModes lists modes with no completed run for the scenario (empty when both exist).
+	MissingModes []string `json:"missing_modes"`
+	// MinRuns is the completed-run count a mode needs before its metrics are returned.
+	MinRuns int `json:"min_runs"`
+	// RunCounts is the number of completed runs per mode for the scenario.
+	RunCounts map[string]int `json:"run_counts"`
+	// InsufficientData lists modes with completed runs, but fewer than MinRuns; their
+	// metrics are withheld (and they also appear in MissingModes).
+	InsufficientData []string `json:"insufficient_data"`
+	// Status summarizes what was found: "no_data", "partial" or "complete".
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Score is set only when weights were requested and both modes exist.
+	Score *CompareScore `json:"score,omitempty"`
+	// BaselineAvg is set when baseline=avg was requested and a baseline was found;
+	// Baseline then holds the averaged metrics.
+	BaselineAvg *CompareBaselineAvg `json:"baseline_avg,omitempty"`
+}
+
+// CompareBaselineAvg describes a rolling-average baseline: the window size asked
+// for and the runs actually averaged (newest first; fewer than N when the scenario
+// has fewer baseline runs).
+type CompareBaselineAvg struct {
+	N      int      `json:"n"`
+	RunIDs []string `json:"run_ids"`
+}
+
+// CompareScore is the weighted multi-objective score of both modes.
+type CompareScore struct {
+	// Weights are the requested weights normalized to sum to 1, keyed by metric.
+	Weights  map[string]float64 `json:"weights"`
+	Baseline ModeScore          `json:"baseline"`
+	GA       ModeScore          `json:"ga"`
+	// Winner is "baseline", "ga" or "tie".
+	Winner string `json:"winner"`
+}
+
+// ModeScore is one mode's total score and the per-metric contributions to it.
+type ModeScore struct {
+	Total         float64            `json:"total"`
+	Contributions map[string]float64 `json:"contributions"`
+}
+
+// CompareDelta is GA minus baseline for each metric, plus fleet-size normalized deltas.
+type CompareDelta struct {
+	OnTimeRate        float64 `json:"on_time_rate"`
+	TotalDistance     float64 `json:"total_distance"`
+	AvgCompletionTime float64 `json:"avg_completion_time"`
+	MaxLateness       float64 `json:"max_lateness"`
+	CompletedJobs     int     `json:"completed_jobs"`
+	FailedJobs        int     `json:"failed_jobs"`
+	// Robots and Jobs are the fleet size used for normalization.
+	Robots int `json:"robots"`
+	Jobs   int `json:"jobs"`
+	// FleetSource is "override" when robots/jobs came from the request, otherwise "preset".
+	FleetSource string          `json:"fleet_source"`
+	PerRobot    NormalizedDelta `json:"per_robot"`
+	PerJob      NormalizedDelta `json:"per_job"`
+}
+
+// NormalizedDelta holds deltas of count-like metrics divided by a fleet dimension.
+type NormalizedDelta struct {
+	TotalDistance float64 `json:"total_distance"`
+	CompletedJobs float64 `json:"completed_jobs"`
+	FailedJobs    float64 `json:"failed_jobs"`
+}
+
+// TimeRange is an optional [From, To] filter; nil bounds are open.
+type TimeRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ModeTrendRow is one (day, mode) aggregate returned by the trends query.
+type ModeTrendRow struct {
+	Day           time.Time
+	Mode          string
+	AvgOnTimeRate float64
+	Runs          int
+}
+
+// ModeTrend summarizes completed runs for one mode on one day.
+type ModeTrend struct {
+	AvgOnTimeRate float64 `json:"avg_on_time_rate"`
+	Runs          int     `json:"runs"`
+}
+
+// TrendPoint is one day of per-mode averages, shaped for charting.
+type TrendPoint struct {
+	Date     string     `json:"date"`
+	Baseline *ModeTrend `json:"baseline"`
+	GA       *ModeTrend `json:"ga"`
+}
+
+// RunTrendsResponse is the response payload for GET /runs/trends.
+type RunTrendsResponse struct {
+	Seed   *int         `json:"seed,omitempty"`
+	Scale  string       `json:"scale,omitempty"`
+	From   *time.Time   `json:"from,omitempty"`
+	To     *time.Time   `json:"to,omitempty"`
+	Points []TrendPoint `json:"points"`
+}
+
+// RunStatusUpdate is one item of the PATCH /runs/status batch.
+type RunStatusUpdate struct {
+	ID           string      `json:"id"`
+	Status       string      `json:"status"`
+	ErrorMessage *string     `json:"error_message,omitempty"`
+	Metrics      *RunMetrics `json:"metrics,omitempty"`
+	// CompletedAt is the simulator's completion time; the server clock is used when omitted.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// RunStatusUpdateResult reports the outcome of one batch item.
+// Result is "updated", "rejected" (this item caused the rollback), or "rolled_back".
+type RunStatusUpdateResult struct {
+	ID        string `json:"id"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+	Published bool   `json:"published"`
+}
+
+// BulkStatusResponse is the response payload for PATCH /runs/status.
+type BulkStatusResponse struct {
+	Applied bool                    `json:"applied"`
+	Results []RunStatusUpdateResult `json:"results"`
+}
+
+// VersionResponse is the response payload for GET /version.
+type VersionResponse struct {
+	Service             string   `json:"service"`
+	APIVersions         []string `json:"api_versions"`
+	ScenarioHashVersion int      `json:"scenario_hash_version"`
+}
+
+// StatusResponse is the response payload for GET /status.
+type StatusResponse struct {
+	Service     string `json:"service"`
+	UptimeS     int    `json:"uptime_s"`
+	RunsCreated int64  `json:"runs_created"`
+	ActiveRuns  int    `json:"active_runs"`
+	ReadOnly    bool   `json:"read_only"`
+
+	Publisher PublisherStats `json:"publisher"`
+}
+
+// PublisherStats reports event publish counters since startup.
+type PublisherStats struct {
+	Published uint64 `json:"published"`
+	Failed    uint64 `json:"failed"`
+	Retried   uint64 `json:"retried"`
+	Sampled   uint64 `json:"sampled"`
+}
+
+// ScenarioSummary is one distinct scenario (seed, scale, robots, jobs) with run counts.
+type ScenarioSummary struct {
+	Seed         int       `json:"seed"`
+	Scale        string    `json:"scale"`
+	Robots       *int      `json:"robots,omitempty"`
+	Jobs         *int      `json:"jobs,omitempty"`
+	BaselineRuns int       `json:"baseline_runs"`
+	GARuns       int       `json:"ga_runs"`
+	TotalRuns    int       `json:"total_runs"`
+	LatestRunAt  time.Time `json:"latest_run_at"`
+}
+
+// RunFilter narrows GET /runs. Zero values do not filter.
+type RunFilter struct {
+	Status string
+	// HasError keeps only runs with (true) or without (false) a non-empty error_message.
+	HasError *bool
+	// ExperimentID keeps only runs attached to that experiment.
+	ExperimentID string
+	// Created keeps only runs created within the (inclusive) range.
+	Created TimeRange
+	// Tags keeps only runs carrying every one of these tags.
+	Tags []string
+}
+
+// RunRetriesResponse is the response payload for GET /runs/{id}/retries.
+type RunRetriesResponse struct {
+	RunID  string `json:"run_id"`
+	RootID string `json:"root_id"`
+	// Retries are the direct and indirect retries of RunID, oldest first.
+	Retries []Run `json:"retries"`
+	// Lineage is the whole chain from RootID, including RunID and its ancestors, oldest first.
+	Lineage []Run `json:"lineage"`
+}
+
+// RunListResponse is the response payload for GET /runs.
+type RunListResponse struct {
+	Runs   []Run `json:"runs"`
+	Total  int   `json:"total"`
+	Limit  int   `json:"limit"`
+	Offset int   `json:"offset"`
+}
+
+// RunBatchResponse is the response payload for GET /runs?ids=...
+type RunBatchResponse struct {
+	Runs []Run `json:"runs"`
+	// NotFound lists requested run IDs that do not exist.
+	NotFound []string `json:"not_found"`
+}
+
+// ScenarioMatrixResponse is the response payload for GET /scenarios/matrix.
+// Deltas[i][j] is Modes[j] minus Modes[i]; missing modes and the diagonal are null.
+type ScenarioMatrixResponse struct {
+	Seed    int                    `json:"seed"`
+	Scale   string                 `json:"scale"`
+	Robots  *int                   `json:"robots,omitempty"`
+	Jobs    *int                   `json:"jobs,omitempty"`
+	Modes   []string               `json:"modes"`
+	Metrics map[string]*RunMetrics `json:"metrics"`
+	Deltas  [][]*CompareDelta      `json:"deltas"`
+}
+
+// ScenarioListResponse is the response payload for GET /scenarios.
+type ScenarioListResponse struct {
+	Scenarios []ScenarioSummary `json:"scenarios"`
+	Total     int               `json:"total"`
+	Limit     int               `json:"limit"`
+	Offset    int               `json:"offset"`
+}
+
+// ScenarioImprovement compares the latest completed baseline and GA runs of one scenario.
+type ScenarioImprovement struct {
+	Seed               int      `json:"seed"`
+	Scale              string   `json:"scale"`
+	Robots             *int     `json:"robots,omitempty"`
+	Jobs               *int     `json:"jobs,omitempty"`
+	BaselineRunID      string   `json:"baseline_run_id"`
+	GARunID            string   `json:"ga_run_id"`
+	BaselineOnTimeRate float64  `json:"baseline_on_time_rate"`
+	GAOnTimeRate       float64  `json:"ga_on_time_rate"`
+	OnTimeRateDelta    float64  `json:"on_time_rate_delta"`
+	DeltaPct           *float64 `json:"delta_pct"`
+}
+
+// ScenarioImprovementListResponse is the response payload for GET /scenarios/improvements.
+type ScenarioImprovementListResponse struct {
+	Scenarios []ScenarioImprovement `json:"scenarios"`
+	Total     int                   `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
+}
+
+// LeaderboardQuery selects and orders completed runs for GET /runs/leaderboard.
+// Min/Max hold inclusive per-metric bounds; zero-value fields do not filter.
+type LeaderboardQuery struct {
+	Metric     string
+	Descending bool
+	Mode       string
+	Scale      string
+	Min        map[string]float64
+	Max        map[string]float64
+	Limit      int
+}
+
+// LeaderboardEntry is one ranked run; Value is its ranking metric.
+type LeaderboardEntry struct {
+	Rank    int        `json:"rank"`
+	RunID   string     `json:"run_id"`
+	Mode    string     `json:"mode"`
+	Seed    int        `json:"seed"`
+	Scale   string     `json:"scale"`
+	Value   float64    `json:"value"`
+	Metrics RunMetrics `json:"metrics"`
+}
+
+// LeaderboardResponse is the response payload for GET /runs/leaderboard.
+type LeaderboardResponse struct {
+	Metric    string             `json:"metric"`
+	Direction string             `json:"direction"`
+	Min       map[string]float64 `json:"min,omitempty"`
+	Max       map[string]float64 `json:"max,omitempty"`
+	Runs      []LeaderboardEntry `json:"runs"`
+}
+
+// MaintenanceWindow is a period during which new runs are rejected.
+type MaintenanceWindow struct {
+	ID        int64     `json:"id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateMaintenanceWindowRequest is the request payload for POST /admin/maintenance.
+type CreateMaintenanceWindowRequest struct {
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// MaintenanceStatusResponse is the response payload for GET /admin/maintenance.
+// Active is the window in effect now; Next is the earliest one that has not started.
+type MaintenanceStatusResponse struct {
+	Active *MaintenanceWindow `json:"active"`
+	Next   *MaintenanceWindow `json:"next"`
+}
+
+// DevSeedResponse is the response payload for POST /admin/seed.
+type DevSeedResponse struct {
+	CreatedRuns int      `json:"created_runs"`
+	Scales      []string `json:"scales"`
+	RunsPerMode int      `json:"runs_per_mode"`
+	FirstSeed   int      `json:"first_seed"`
+	LastSeed    int      `json:"last_seed"`
+}
+
+// CompareMetricRow is one metric in the flat compare format (?format=flat).
+type CompareMetricRow struct {
+	Metric   string   `json:"metric"`
+	Baseline *float64 `json:"baseline"`
+	GA       *float64 `json:"ga"`
+	DeltaPct *float64 `json:"delta_pct"`
+}
+
+// RunPeerResponse is the response payload for GET /runs/{id}/peer.
+type RunPeerResponse struct {
+	RunID    string      `json:"run_id"`
+	Mode     string      `json:"mode"`
+	PeerMode string      `json:"peer_mode"`
+	Peer     *RunMetrics `json:"peer"`
+}
+
+// RunDeltaResponse is the response payload for GET /runs/{id}/delta.
+type RunDeltaResponse struct {
+	RunID   string           `json:"run_id"`
+	Against string           `json:"against"`
+	Metrics []MetricDeltaRow `json:"metrics"`
+}
+
+// NormalizedRunMetrics is the response payload for GET /runs/{id}/metrics/normalized.
+// The *PerJob values are null when the scenario resolved to zero jobs.
+type NormalizedRunMetrics struct {
+	RunID string `json:"run_id"`
+	Jobs  int    `json:"jobs"`
+	// FleetSource says where Jobs came from: "override", "metrics" or "preset".
+	FleetSource string `json:"fleet_source"`
+	// DistancePerJob, CompletedPerJob and FailedPerJob are the count-like metrics divided by Jobs.
+	DistancePerJob  *float64 `json:"distance_per_job"`
+	CompletedPerJob *float64 `json:"completed_per_job"`
+	FailedPerJob    *float64 `json:"failed_per_job"`
+	// OnTimeRate, AvgCompletionTime and MaxLateness are already size-independent.
+	OnTimeRate        float64 `json:"on_time_rate"`
+	AvgCompletionTime float64 `json:"avg_completion_time"`
+	MaxLateness       float64 `json:"max_lateness"`
+}
+
+// RunReproResponse is the response payload for GET /runs/{id}/repro. Request is a
+// POST /runs body that re-creates the run; Command is the same request as curl.
+type RunReproResponse struct {
+	RunID        string           `json:"run_id"`
+	ScenarioHash string           `json:"scenario_hash"`
+	Effective    EffectiveFleet   `json:"effective"`
+	Request      CreateRunRequest `json:"request"`
+	Command      string           `json:"command"`
+}
+
+// MetricDeltaRow is one metric of a run-vs-run delta: value minus against.
+// DeltaPct is null when the against value is zero.
+type MetricDeltaRow struct {
+	Metric   string   `json:"metric"`
+	Against  float64  `json:"against"`
+	Value    float64  `json:"value"`
+	Delta    float64  `json:"delta"`
+	DeltaPct *float64 `json:"delta_pct"`
+}
+
+// RunEventRecord is one row of a run's event history (run_events table).
+type RunEventRecord struct {
+	ID         int64           `json:"id"`
+	RunID      string          `json:"run_id"`
+	EventType  string          `json:"event_type"`
+	RoutingKey string          `json:"routing_key"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// RunEventListResponse is the response payload for GET /runs/{id}/events.
+type RunEventListResponse struct {
+	RunID  string           `json:"run_id"`
+	Events []RunEventRecord `json:"events"`
+	Total  int              `json:"total"`
+	Limit  int              `json:"limit"`
+	Offset int              `json:"offset"`
+}
+
+// TimelineEntry is one point in a run's lifecycle. Source says where it came from:
+// "run" (row timestamps), "metrics" (run_metrics) or "event" (run_events).
+type TimelineEntry struct {
+	At     time.Time `json:"at"`
+	Type   string    `json:"type"`
+	Source string    `json:"source"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// RunTimelineResponse is the response payload for GET /runs/{id}/timeline.
+type RunTimelineResponse struct {
+	RunID  string          `json:"run_id"`
+	Status string          `json:"status"`
+	Events []TimelineEntry `json:"events"`
+}
+
+// RunMetricsSnapshot is one row of a run's metrics history. SimTimeS is the
+// simulation time of the snapshot, nil when the writer did not report one.
+type RunMetricsSnapshot struct {
+	SimTimeS          *int      `json:"sim_time_s"`
+	OnTimeRate        float64   `json:"on_time_rate"`
+	TotalDistance     float64   `json:"total_distance"`
+	AvgCompletionTime float64   `json:"avg_completion_time"`
+	MaxLateness       float64   `json:"max_lateness"`
+	CompletedJobs     int       `json:"completed_jobs"`
+	FailedJobs        int       `json:"failed_jobs"`
+	TotalJobs         int       `json:"total_jobs"`
+	RecordedAt        time.Time `json:"recorded_at"`
+}
+
+// RunMetricsHistoryResponse is the response payload for GET /runs/{id}/metrics/history.
+type RunMetricsHistoryResponse struct {
+	RunID   string               `json:"run_id"`
+	History []RunMetricsSnapshot `json:"history"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+}
+
+// RunNote is a free-text annotation attached to a run.
+type RunNote struct {
+	ID        int64     `json:"id"`
+	RunID     string    `json:"run_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateRunNoteRequest is the request payload for POST /runs/{id}/notes.
+type CreateRunNoteRequest struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// RunExport is the response payload for GET /runs/{id}/export: a run and all of its
+// stored sub-records in one document. Metrics is null until the run has metrics.
+type RunExport struct {
+	FormatVersion  int                  `json:"format_version"`
+	ExportedAt     time.Time            `json:"exported_at"`
+	Run            Run                  `json:"run"`
+	Metrics        *RunMetrics          `json:"metrics"`
+	MetricsHistory []RunMetricsSnapshot `json:"metrics_history"`
+	Notes          []RunNote            `json:"notes"`
+	Events         []RunEventRecord     `json:"events"`
+}
+
+// RunNoteListResponse is the response payload for GET /runs/{id}/notes.
+type RunNoteListResponse struct {
+	RunID  string    `json:"run_id"`
+	Notes  []RunNote `json:"notes"`
+	Total  int       `json:"total"`
+	Limit  int       `json:"limit"`
+	Offset int       `json:"offset"`
+}
+
+// ThresholdFailure describes one threshold a run's metrics did not meet.
+type ThresholdFailure struct {
+	Criterion string  `json:"criterion"`
+	Metric    string  `json:"metric"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+}
+
+// Experiment groups related runs, e.g. a parameter sweep.
+type Experiment struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateExperimentRequest is the request payload for POST /experiments.
+type CreateExperimentRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// ExperimentResponse is the response payload for GET /experiments/{id}: the
+// experiment, its runs (oldest first) and per-mode aggregate metrics.
+type ExperimentResponse struct {
+	Experiment
+	Runs       []Run                 `json:"runs"`
+	Aggregates []ExperimentAggregate `json:"aggregates"`
+}
+
+// ExperimentAggregate summarizes an experiment's runs of one mode. Averages cover
+// the runs with metrics and are null when there are none.
+type ExperimentAggregate struct {
+	Mode                 string   `json:"mode"`
+	Runs                 int      `json:"runs"`
+	Completed            int      `json:"completed"`
+	WithMetrics          int      `json:"with_metrics"`
+	AvgOnTimeRate        *float64 `json:"avg_on_time_rate"`
+	AvgTotalDistance     *float64 `json:"avg_total_distance"`
+	AvgAvgCompletionTime *float64 `json:"avg_avg_completion_time"`
+	AvgMaxLateness       *float64 `json:"avg_max_lateness"`
+}
+
+// AuditEntry records the outcome of one run write for the audit log.
+type AuditEntry struct {
+	Action       string    `json:"action"`
+	RunID        *string   `json:"run_id"`
+	Outcome      string    `json:"outcome"`
+	ErrorMessage *string   `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 }
@@ -0,0 +1,44 @@
+package mq
+
+// File: internal/mq/amqp.go
+// Purpose: The slice of the AMQP client the publisher uses, so tests can stand in a fake broker.
+
+import "github.com/streadway/amqp"
+
+// amqpChannel is the subset of *amqp.Channel the publisher calls.
+type amqpChannel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	ExchangeBind(destination, key, source string, noWait bool, args amqp.Table) error
+	QueueDeclarePassive(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	Close() error
+}
+
+// amqpConn is the subset of *amqp.Connection the publisher calls.
+type amqpConn interface {
+	Channel() (amqpChannel, error)
+	Close() error
+}
+
+// dialFunc opens a broker connection; dialAMQP in production.
+type dialFunc func(url string, cfg amqp.Config) (amqpConn, error)
+
+// dialAMQP dials a real broker.
+func dialAMQP(url string, cfg amqp.Config) (amqpConn, error) {
+	conn, err := amqp.DialConfig(url, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// amqpConnection adapts *amqp.Connection, whose Channel returns the concrete type.
+type amqpConnection struct{ *amqp.Connection }
+
+func (c amqpConnection) Channel() (amqpChannel, error) {
+	ch, err := c.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
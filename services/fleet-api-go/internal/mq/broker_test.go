@@ -0,0 +1,198 @@
+package mq
+
+// File: internal/mq/broker_test.go
+// Purpose: An in-memory broker behind dialFunc, recording dials and publishes.
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+// published is one message a fakeChannel accepted.
+type published struct {
+	URL        string
+	Channel    int
+	Exchange   string
+	RoutingKey string
+	Msg        amqp.Publishing
+}
+
+// declared is one exchange declaration or binding a fakeChannel accepted.
+type declared struct {
+	Name, Kind string // for a binding, Name is the destination and Kind the source
+	Args       amqp.Table
+}
+
+// fakeBroker answers dials for every URL not marked down.
+type fakeBroker struct {
+	mu        sync.Mutex
+	down      map[string]bool
+	dials     []string
+	configs   []amqp.Config
+	conns     []*fakeConn
+	published []published
+	exchanges []declared
+	bindings  []declared
+	// queues answers QueueDeclarePassive; a missing name is a 404 channel error.
+	queues map[string]amqp.Queue
+	// publishErr, when set, fails every publish on an open channel.
+	publishErr error
+	// discard accepts publishes without recording them (benchmarks).
+	discard bool
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{down: map[string]bool{}, queues: map[string]amqp.Queue{}}
+}
+
+func (b *fakeBroker) dial(url string, cfg amqp.Config) (amqpConn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dials = append(b.dials, url)
+	b.configs = append(b.configs, cfg)
+	if b.down[url] {
+		return nil, fmt.Errorf("dial %s: connection refused", url)
+	}
+	c := &fakeConn{broker: b, url: url}
+	b.conns = append(b.conns, c)
+	return c, nil
+}
+
+// setDown marks url unreachable for new dials.
+func (b *fakeBroker) setDown(url string, down bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down[url] = down
+}
+
+// dropConnections closes every open connection, as a broker restart would.
+func (b *fakeBroker) dropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.conns {
+		c.closed = true
+	}
+}
+
+func (b *fakeBroker) dialed() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.dials...)
+}
+
+func (b *fakeBroker) messages() []published {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]published(nil), b.published...)
+}
+
+// newTestPublisher builds a Publisher over a fresh broker for opts, defaulting URLs
+// to a single broker and Exchange to amr.events.
+func newTestPublisher(t testing.TB, opts Options) (*Publisher, *fakeBroker) {
+	t.Helper()
+	b := newFakeBroker()
+	if len(opts.URLs) == 0 {
+		opts.URLs = []string{"amqp://a"}
+	}
+	if opts.Exchange == "" {
+		opts.Exchange = "amr.events"
+	}
+	p, err := newPublisher(opts, b.dial)
+	if err != nil {
+		t.Fatalf("newPublisher: %v", err)
+	}
+	t.Cleanup(p.Close)
+	return p, b
+}
+
+type fakeConn struct {
+	broker   *fakeBroker
+	url      string
+	channels int
+	closed   bool
+}
+
+func (c *fakeConn) Channel() (amqpChannel, error) {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	if c.closed {
+		return nil, amqp.ErrClosed
+	}
+	c.channels++
+	return &fakeChannel{conn: c, id: c.channels - 1}, nil
+}
+
+func (c *fakeConn) Close() error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+type fakeChannel struct {
+	conn   *fakeConn
+	id     int
+	closed bool
+}
+
+// usable reports whether the channel and its connection are open. Callers hold the broker lock.
+func (ch *fakeChannel) usable() bool { return !ch.closed && !ch.conn.closed }
+
+func (ch *fakeChannel) Publish(exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	b := ch.conn.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !ch.usable() {
+		return amqp.ErrClosed
+	}
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	if b.discard {
+		return nil
+	}
+	b.published = append(b.published, published{URL: ch.conn.url, Channel: ch.id, Exchange: exchange, RoutingKey: key, Msg: msg})
+	return nil
+}
+
+func (ch *fakeChannel) ExchangeDeclare(name, kind string, _, _, _, _ bool, args amqp.Table) error {
+	b := ch.conn.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.exchanges = append(b.exchanges, declared{Name: name, Kind: kind, Args: args})
+	return nil
+}
+
+func (ch *fakeChannel) ExchangeBind(destination, _, source string, _ bool, _ amqp.Table) error {
+	b := ch.conn.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bindings = append(b.bindings, declared{Name: destination, Kind: source})
+	return nil
+}
+
+func (ch *fakeChannel) QueueDeclarePassive(name string, _, _, _, _ bool, _ amqp.Table) (amqp.Queue, error) {
+	b := ch.conn.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !ch.usable() {
+		return amqp.Queue{}, amqp.ErrClosed
+	}
+	q, ok := b.queues[name]
+	if !ok {
+		ch.closed = true
+		return amqp.Queue{}, &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no queue '" + name + "'"}
+	}
+	return q, nil
+}
+
+func (ch *fakeChannel) Close() error {
+	b := ch.conn.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch.closed = true
+	return nil
+}
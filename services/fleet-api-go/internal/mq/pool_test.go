@@ -0,0 +1,99 @@
+package mq
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestPublishRoundRobinsOverChannels(t *testing.T) {
+	p, b := newTestPublisher(t, Options{Channels: 3})
+	for i := range 7 {
+		if err := p.Publish("run.created", map[string]any{"run_id": fmt.Sprintf("run-%d", i)}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	var got []int
+	for _, m := range b.messages() {
+		got = append(got, m.Channel)
+	}
+	if want := []int{0, 1, 2, 0, 1, 2, 0}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("channels = %v, want %v", got, want)
+	}
+}
+
+func TestPublisherChannelsDefaultToOne(t *testing.T) {
+	for _, n := range []int{0, -2} {
+		p, _ := newTestPublisher(t, Options{Channels: n})
+		if got := len(p.pool.Load().channels); got != 1 {
+			t.Errorf("Channels %d: pool has %d channels, want 1", n, got)
+		}
+	}
+}
+
+func TestPublishConcurrentlyAcrossChannels(t *testing.T) {
+	const publishers, each, channels = 16, 50, 4
+	p, b := newTestPublisher(t, Options{Channels: channels})
+
+	var wg sync.WaitGroup
+	for g := range publishers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range each {
+				if err := p.Publish("run.progress", map[string]any{"run_id": fmt.Sprintf("run-%d-%d", g, i)}); err != nil {
+					t.Errorf("Publish: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	perChannel := make([]int, channels)
+	for _, m := range b.messages() {
+		perChannel[m.Channel]++
+	}
+	for ch, n := range perChannel {
+		if n != publishers*each/channels {
+			t.Errorf("channel %d carried %d messages, want an even share of %d", ch, n, publishers*each/channels)
+		}
+	}
+	if got := p.Stats().Published; got != publishers*each {
+		t.Errorf("Published = %d, want %d", got, publishers*each)
+	}
+}
+
+func TestPublishConcurrentReconnectDialsOnce(t *testing.T) {
+	p, b := newTestPublisher(t, Options{URLs: []string{"amqp://a", "amqp://b"}, Channels: 2})
+	b.dropConnections()
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+				t.Errorf("Publish: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := len(b.dialed()); got != 2 {
+		t.Errorf("dials = %v, want the initial one plus a single reconnect", b.dialed())
+	}
+}
+
+func BenchmarkPublishParallel(b *testing.B) {
+	for _, channels := range []int{1, 4} {
+		b.Run(fmt.Sprintf("channels=%d", channels), func(b *testing.B) {
+			p, broker := newTestPublisher(b, Options{Channels: channels})
+			broker.discard = true
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = p.Publish("run.progress", map[string]any{"run_id": "run-1", "progress_pct": 50})
+				}
+			})
+		})
+	}
+}
@@ -5,66 +5,334 @@ package mq
 // Purpose: Publish domain events to the amr.events exchange.
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/streadway/amqp"
 )
 
-// Publisher wraps an AMQP connection/channel for event publishing.
+// Options configures a Publisher.
+type Options struct {
+	// URLs are the broker URLs, tried in order.
+	URLs []string
+	// Exchange is the topic exchange events are published to.
+	Exchange string
+	// ConnectionName is shown in the RabbitMQ management UI for this client.
+	ConnectionName string
+	// Channels is how many AMQP channels publishes are spread across (default 1).
+	Channels int
+	// DelayedExchange, when set, is declared as an x-delayed-message exchange
+	// (RabbitMQ delayed-message plugin) bound to Exchange, enabling PublishDelayed.
+	DelayedExchange string
+	// SampleRates publishes only 1 in N Publish calls per routing key (e.g.
+	// "run.progress": 10). Keys not listed, or with N <= 1, always publish.
+	SampleRates map[string]int
+	// MessageTTL sets the per-message expiration on every publish, so events in
+	// unconsumed queues are dropped instead of piling up. Zero means no expiration.
+	MessageTTL time.Duration
+}
+
+// ErrDelayedDisabled is returned by PublishDelayed when no delayed exchange is configured.
+var ErrDelayedDisabled = errors.New("delayed publishing is not enabled")
+
+// Publisher wraps an AMQP connection and a pool of channels for event publishing.
+// Publishes are spread round-robin over the channels so concurrent callers do not
+// queue behind one channel. It fails over across the configured broker URLs in order.
 type Publisher struct {
-	conn     *amqp.Connection
-	channel  *amqp.Channel
+	// mu serializes reconnects; publishing itself only reads pool.
+	mu       sync.Mutex
+	urls     []string
+	next     int
+	dial     dialFunc
+	dialCfg  amqp.Config
 	exchange string
+	delayed  string
+	size     int
+	pool     atomic.Pointer[channelPool]
+	nextCh   atomic.Uint64
+	samplers map[string]*keySampler
+	ttl      time.Duration
+
+	// Counters are atomic so Stats can be read without taking mu.
+	published atomic.Uint64
+	failed    atomic.Uint64
+	retried   atomic.Uint64
+	sampled   atomic.Uint64
+}
+
+// channelPool is one connection and its publishing channels. A reconnect swaps
+// in a whole new pool, so publishers never see a half-rebuilt set.
+type channelPool struct {
+	conn     amqpConn
+	channels []amqpChannel
+}
+
+// Stats is a snapshot of publish counters since the Publisher was created.
+type Stats struct {
+	// Published counts messages accepted by the broker channel.
+	Published uint64
+	// Failed counts Publish calls that returned an error.
+	Failed uint64
+	// Retried counts re-sends: after an automatic reconnect, plus caller retries
+	// reported through RecordRetry.
+	Retried uint64
+	// Sampled counts messages dropped by SampleRates.
+	Sampled uint64
 }
 
-// NewPublisher connects to RabbitMQ and declares the exchange.
-func NewPublisher(url, exchange string) (*Publisher, error) {
-	conn, err := amqp.Dial(url)
+// Stats returns the current publish counters. Safe for concurrent use.
+func (p *Publisher) Stats() Stats {
+	return Stats{
+		Published: p.published.Load(),
+		Failed:    p.failed.Load(),
+		Retried:   p.retried.Load(),
+		Sampled:   p.sampled.Load(),
+	}
+}
+
+// RecordRetry counts a caller-level retry (e.g. backoff-and-retry around Publish).
+func (p *Publisher) RecordRetry() {
+	p.retried.Add(1)
+}
+
+// NewPublisher connects to the first reachable RabbitMQ URL and declares the exchange.
+func NewPublisher(opts Options) (*Publisher, error) {
+	return newPublisher(opts, dialAMQP)
+}
+
+// newPublisher is NewPublisher over dial.
+func newPublisher(opts Options, dial dialFunc) (*Publisher, error) {
+	if len(opts.URLs) == 0 {
+		return nil, errors.New("amqp dial: no broker urls configured")
+	}
+	size := opts.Channels
+	if size < 1 {
+		size = 1
+	}
+	p := &Publisher{
+		urls:     opts.URLs,
+		exchange: opts.Exchange,
+		delayed:  opts.DelayedExchange,
+		dial:     dial,
+		dialCfg:  dialConfig(opts.ConnectionName),
+		size:     size,
+		samplers: newSamplers(opts.SampleRates),
+		ttl:      opts.MessageTTL,
+	}
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// connect dials the broker URLs starting after the last used one, so reconnects
+// cycle through the cluster instead of hammering a dead node. Callers hold p.mu
+// (or are the constructor).
+func (p *Publisher) connect() error {
+	var lastErr error
+	for i := 0; i < len(p.urls); i++ {
+		idx := (p.next + i) % len(p.urls)
+		pool, err := dialPool(p.dial, p.urls[idx], p.dialCfg, p.exchange, p.delayed, p.size)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		p.pool.Store(pool)
+		p.next = (idx + 1) % len(p.urls)
+		return nil
+	}
+	return lastErr
+}
+
+// reconnect replaces stale with a fresh pool. If another publisher already
+// replaced it, the newer pool is kept and no new connection is made.
+func (p *Publisher) reconnect(stale *channelPool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pool.Load() != stale {
+		return nil
+	}
+	stale.close()
+	return p.connect()
+}
+
+// channel picks the next channel round-robin.
+func (p *Publisher) channel(pool *channelPool) amqpChannel {
+	n := p.nextCh.Add(1) - 1
+	return pool.channels[n%uint64(len(pool.channels))]
+}
+
+// dialConfig mirrors amqp.Dial's defaults and sets the client-provided connection name.
+func dialConfig(connectionName string) amqp.Config {
+	cfg := amqp.Config{
+		Heartbeat:  10 * time.Second,
+		Locale:     "en_US",
+		Properties: amqp.Table{},
+	}
+	if connectionName != "" {
+		cfg.Properties["connection_name"] = connectionName
+	}
+	return cfg
+}
+
+// dialPool opens a connection with size channels and declares the exchange, plus
+// the delayed exchange (bound to it for every routing key) when one is configured.
+func dialPool(dial dialFunc, url string, cfg amqp.Config, exchange, delayed string, size int) (*channelPool, error) {
+	conn, err := dial(url, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("amqp dial: %w", err)
 	}
-	ch, err := conn.Channel()
-	if err != nil {
-		_ = conn.Close()
-		return nil, fmt.Errorf("amqp channel: %w", err)
+	pool := &channelPool{conn: conn}
+	for i := 0; i < size; i++ {
+		ch, err := conn.Channel()
+		if err != nil {
+			pool.close()
+			return nil, fmt.Errorf("amqp channel: %w", err)
+		}
+		pool.channels = append(pool.channels, ch)
 	}
-	if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
-		_ = ch.Close()
-		_ = conn.Close()
+	if err := pool.channels[0].ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		pool.close()
 		return nil, fmt.Errorf("declare exchange: %w", err)
 	}
-	return &Publisher{conn: conn, channel: ch, exchange: exchange}, nil
+	if delayed != "" {
+		if err := declareDelayedExchange(pool.channels[0], delayed, exchange); err != nil {
+			pool.close()
+			return nil, err
+		}
+	}
+	return pool, nil
 }
 
-// Close closes the AMQP channel and connection.
-func (p *Publisher) Close() {
-	if p.channel != nil {
-		_ = p.channel.Close()
+// declareDelayedExchange declares name as an x-delayed-message exchange that routes
+// like a topic exchange, and binds target to it so delayed messages reach the same
+// consumers once their delay expires. Requires the rabbitmq_delayed_message_exchange plugin.
+func declareDelayedExchange(ch amqpChannel, name, target string) error {
+	args := amqp.Table{"x-delayed-type": "topic"}
+	if err := ch.ExchangeDeclare(name, "x-delayed-message", true, false, false, false, args); err != nil {
+		return fmt.Errorf("declare delayed exchange: %w", err)
 	}
-	if p.conn != nil {
-		_ = p.conn.Close()
+	if err := ch.ExchangeBind(target, "#", name, false, nil); err != nil {
+		return fmt.Errorf("bind delayed exchange: %w", err)
 	}
+	return nil
 }
 
-// Publish emits a JSON event to the configured exchange.
+// close closes the pool's channels and then its connection.
+func (cp *channelPool) close() {
+	for _, ch := range cp.channels {
+		_ = ch.Close()
+	}
+	if cp.conn != nil {
+		_ = cp.conn.Close()
+	}
+}
+
+// Close closes the AMQP channels and connection.
+func (p *Publisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if pool := p.pool.Load(); pool != nil {
+		pool.close()
+	}
+}
+
+// Publish emits a JSON event to the configured exchange. If the connection has
+// been closed it reconnects to the next broker and retries once. Messages dropped
+// by SampleRates return nil without reaching the broker.
 func (p *Publisher) Publish(routingKey string, payload map[string]any) error {
+	if p.sampledOut(routingKey) {
+		p.sampled.Add(1)
+		return nil
+	}
+	msg, err := buildMessage(routingKey, payload, p.ttl)
+	if err != nil {
+		return p.count(err)
+	}
+	return p.count(p.send(p.exchange, routingKey, msg))
+}
+
+// PublishDelayed emits an event through the delayed exchange; the broker holds it
+// for delay before routing it to the main exchange. It returns ErrDelayedDisabled
+// when no delayed exchange is configured.
+func (p *Publisher) PublishDelayed(routingKey string, payload map[string]any, delay time.Duration) error {
+	if p.delayed == "" {
+		return ErrDelayedDisabled
+	}
+	msg, err := buildMessage(routingKey, payload, p.ttl)
+	if err != nil {
+		return p.count(err)
+	}
+	setDelay(&msg, delay)
+	return p.count(p.send(p.delayed, routingKey, msg))
+}
+
+// buildMessage stamps routing_key and ts_utc onto payload and encodes it as a persistent JSON message.
+// A positive ttl becomes the message expiration (whole milliseconds, as AMQP expects).
+func buildMessage(routingKey string, payload map[string]any, ttl time.Duration) (amqp.Publishing, error) {
 	payload["routing_key"] = routingKey
 	payload["ts_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal event: %w", err)
-	}
-	return p.channel.Publish(
-		p.exchange,
-		routingKey,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			Body:         body,
-		},
-	)
+		return amqp.Publishing{}, fmt.Errorf("marshal event: %w", err)
+	}
+	msg := amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	}
+	if ttl > 0 {
+		msg.Expiration = strconv.FormatInt(ttl.Milliseconds(), 10)
+	}
+	return msg, nil
+}
+
+// setDelay sets the x-delay header (milliseconds) read by the delayed-message plugin.
+func setDelay(msg *amqp.Publishing, delay time.Duration) {
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	msg.Headers["x-delay"] = max(delay.Milliseconds(), 0)
+}
+
+// count records the outcome of a publish and passes err through.
+func (p *Publisher) count(err error) error {
+	if err != nil {
+		p.failed.Add(1)
+		return err
+	}
+	p.published.Add(1)
+	return nil
+}
+
+// send publishes msg on the next pooled channel, reconnecting and retrying once
+// if the channel was closed.
+func (p *Publisher) send(exchange, routingKey string, msg amqp.Publishing) error {
+	pool := p.pool.Load()
+	err := p.channel(pool).Publish(exchange, routingKey, false, false, msg)
+	if !errors.Is(err, amqp.ErrClosed) {
+		return err
+	}
+	log.Printf("amqp connection closed, reconnecting: %v", err)
+	if err := p.reconnect(pool); err != nil {
+		return fmt.Errorf("amqp reconnect: %w", err)
+	}
+	p.retried.Add(1)
+	return p.channel(p.pool.Load()).Publish(exchange, routingKey, false, false, msg)
+}
+
+// PublishContext is Publish that refuses to start once ctx is done, so callers
+// retrying under a request deadline stop when the client has gone away.
+func (p *Publisher) PublishContext(ctx context.Context, routingKey string, payload map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.Publish(routingKey, payload)
 }
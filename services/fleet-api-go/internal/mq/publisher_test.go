@@ -0,0 +1,181 @@
+package mq
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestNewPublisherFailsOverToTheNextURL(t *testing.T) {
+	b := newFakeBroker()
+	b.setDown("amqp://a", true)
+	p, err := newPublisher(Options{URLs: []string{"amqp://a", "amqp://b", "amqp://c"}, Exchange: "amr.events"}, b.dial)
+	if err != nil {
+		t.Fatalf("newPublisher: %v", err)
+	}
+	defer p.Close()
+	if got, want := b.dialed(), []string{"amqp://a", "amqp://b"}; !slices.Equal(got, want) {
+		t.Fatalf("dials = %v, want %v", got, want)
+	}
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if msgs := b.messages(); len(msgs) != 1 || msgs[0].URL != "amqp://b" {
+		t.Errorf("messages = %+v, want one via amqp://b", msgs)
+	}
+}
+
+func TestNewPublisherAllURLsDown(t *testing.T) {
+	b := newFakeBroker()
+	b.setDown("amqp://a", true)
+	b.setDown("amqp://b", true)
+	_, err := newPublisher(Options{URLs: []string{"amqp://a", "amqp://b"}}, b.dial)
+	if err == nil || !strings.Contains(err.Error(), "amqp dial: dial amqp://b") {
+		t.Fatalf("err = %v, want the last URL's dial error", err)
+	}
+	if _, err := newPublisher(Options{}, b.dial); err == nil {
+		t.Error("newPublisher with no URLs succeeded")
+	}
+}
+
+func TestPublishReconnectsToTheNextBrokerInTurn(t *testing.T) {
+	p, b := newTestPublisher(t, Options{URLs: []string{"amqp://a", "amqp://b", "amqp://c"}})
+
+	b.dropConnections()
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+		t.Fatalf("Publish after a dropped connection: %v", err)
+	}
+	b.dropConnections()
+	b.setDown("amqp://c", true)
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-2"}); err != nil {
+		t.Fatalf("Publish with the next broker down: %v", err)
+	}
+
+	// a at start; b after the first drop; c refused, so a after the second.
+	if got, want := b.dialed(), []string{"amqp://a", "amqp://b", "amqp://c", "amqp://a"}; !slices.Equal(got, want) {
+		t.Errorf("dials = %v, want %v", got, want)
+	}
+	var urls []string
+	for _, m := range b.messages() {
+		urls = append(urls, m.URL)
+	}
+	if want := []string{"amqp://b", "amqp://a"}; !slices.Equal(urls, want) {
+		t.Errorf("published via %v, want %v", urls, want)
+	}
+	if st := p.Stats(); st.Published != 2 || st.Retried != 2 || st.Failed != 0 {
+		t.Errorf("stats = %+v, want 2 published, 2 retried", st)
+	}
+}
+
+func TestPublishFailsWhenNoBrokerIsReachable(t *testing.T) {
+	p, b := newTestPublisher(t, Options{URLs: []string{"amqp://a", "amqp://b"}})
+	b.setDown("amqp://a", true)
+	b.setDown("amqp://b", true)
+	b.dropConnections()
+
+	err := p.Publish("run.created", map[string]any{"run_id": "run-1"})
+	if err == nil || !strings.HasPrefix(err.Error(), "amqp reconnect: ") {
+		t.Fatalf("err = %v, want a reconnect error", err)
+	}
+	if st := p.Stats(); st.Failed != 1 || st.Published != 0 {
+		t.Errorf("stats = %+v, want 1 failed", st)
+	}
+}
+
+func TestPublisherSendsConnectionName(t *testing.T) {
+	_, b := newTestPublisher(t, Options{ConnectionName: "fleet-api@pod-7"})
+	if len(b.configs) != 1 {
+		t.Fatalf("dials = %d, want 1", len(b.configs))
+	}
+	cfg := b.configs[0]
+	if got := cfg.Properties["connection_name"]; got != "fleet-api@pod-7" {
+		t.Errorf("connection_name = %v, want fleet-api@pod-7", got)
+	}
+	if cfg.Heartbeat == 0 || cfg.Locale != "en_US" {
+		t.Errorf("dial config %+v lost amqp.Dial's defaults", cfg)
+	}
+
+	_, b = newTestPublisher(t, Options{})
+	if _, ok := b.configs[0].Properties["connection_name"]; ok {
+		t.Error("connection_name set without a ConnectionName")
+	}
+}
+
+func TestPublishSetsMessageTTL(t *testing.T) {
+	for _, tc := range []struct {
+		ttl  time.Duration
+		want string
+	}{
+		{0, ""},
+		{1500 * time.Millisecond, "1500"},
+		{time.Minute, "60000"},
+	} {
+		p, b := newTestPublisher(t, Options{MessageTTL: tc.ttl, DelayedExchange: "amr.delayed"})
+		if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+		if err := p.PublishDelayed("run.timeout", map[string]any{"run_id": "run-1"}, time.Second); err != nil {
+			t.Fatalf("PublishDelayed: %v", err)
+		}
+		msgs := b.messages()
+		if len(msgs) != 2 {
+			t.Fatalf("ttl %s: published %d messages, want 2", tc.ttl, len(msgs))
+		}
+		for _, m := range msgs {
+			if m.Msg.Expiration != tc.want {
+				t.Errorf("ttl %s: %s expiration = %q, want %q", tc.ttl, m.RoutingKey, m.Msg.Expiration, tc.want)
+			}
+		}
+	}
+}
+
+func TestDelayedExchangeDeclaration(t *testing.T) {
+	_, b := newTestPublisher(t, Options{DelayedExchange: "amr.delayed"})
+	want := []declared{
+		{Name: "amr.events", Kind: "topic"},
+		{Name: "amr.delayed", Kind: "x-delayed-message", Args: amqp.Table{"x-delayed-type": "topic"}},
+	}
+	if !reflect.DeepEqual(b.exchanges, want) {
+		t.Errorf("exchanges = %+v, want %+v", b.exchanges, want)
+	}
+	// Delayed messages are routed on to the main exchange once their delay expires.
+	if want := []declared{{Name: "amr.events", Kind: "amr.delayed"}}; !reflect.DeepEqual(b.bindings, want) {
+		t.Errorf("bindings = %+v, want %+v", b.bindings, want)
+	}
+
+	_, plain := newTestPublisher(t, Options{})
+	if len(plain.exchanges) != 1 || plain.exchanges[0].Kind != "topic" || len(plain.bindings) != 0 {
+		t.Errorf("declared %+v / bound %+v, want only the main exchange without a delayed one", plain.exchanges, plain.bindings)
+	}
+}
+
+func TestPublishDelayedSetsDelayHeader(t *testing.T) {
+	p, b := newTestPublisher(t, Options{DelayedExchange: "amr.delayed"})
+	for _, delay := range []time.Duration{90 * time.Second, 1500 * time.Microsecond, 0, -time.Second} {
+		if err := p.PublishDelayed("run.timeout", map[string]any{"run_id": "run-1"}, delay); err != nil {
+			t.Fatalf("PublishDelayed(%s): %v", delay, err)
+		}
+	}
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	msgs := b.messages()
+	if len(msgs) != 5 {
+		t.Fatalf("published %d messages, want 5", len(msgs))
+	}
+	// Whole milliseconds, and a negative delay is sent as no delay.
+	for i, want := range []int64{90000, 1, 0, 0} {
+		m := msgs[i]
+		if m.Exchange != "amr.delayed" || m.RoutingKey != "run.timeout" || m.Msg.Headers["x-delay"] != want {
+			t.Errorf("message %d = %s/%s x-delay %v, want amr.delayed/run.timeout x-delay %d", i, m.Exchange, m.RoutingKey, m.Msg.Headers["x-delay"], want)
+		}
+	}
+	if last := msgs[4]; last.Exchange != "amr.events" || last.Msg.Headers != nil {
+		t.Errorf("plain publish = %s with headers %v, want the main exchange and no x-delay", last.Exchange, last.Msg.Headers)
+	}
+}
@@ -0,0 +1,35 @@
+package mq
+
+// File: internal/mq/sampling.go
+// Purpose: Deterministic 1-in-N sampling of high-volume routing keys.
+
+import "sync/atomic"
+
+// keySampler keeps every rate-th message of one routing key, starting with the first.
+type keySampler struct {
+	rate uint64
+	seen atomic.Uint64
+}
+
+// keep counts one message and reports whether it should be published.
+func (s *keySampler) keep() bool {
+	return (s.seen.Add(1)-1)%s.rate == 0
+}
+
+// newSamplers builds one sampler per routing key with a rate above 1. The map is
+// never written after construction, so lookups need no lock.
+func newSamplers(rates map[string]int) map[string]*keySampler {
+	samplers := make(map[string]*keySampler, len(rates))
+	for key, rate := range rates {
+		if rate > 1 {
+			samplers[key] = &keySampler{rate: uint64(rate)}
+		}
+	}
+	return samplers
+}
+
+// sampledOut reports whether a message for routingKey should be dropped by sampling.
+func (p *Publisher) sampledOut(routingKey string) bool {
+	s, ok := p.samplers[routingKey]
+	return ok && !s.keep()
+}
@@ -0,0 +1,72 @@
+package mq
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSamplingKeepsEveryNthMessageStartingWithTheFirst(t *testing.T) {
+	p, b := newTestPublisher(t, Options{SampleRates: map[string]int{"run.progress": 3, "telemetry": 1, "run.stats": 0}})
+	for i := range 7 {
+		_ = p.Publish("run.progress", map[string]any{"n": i})
+		_ = p.Publish("telemetry", map[string]any{"n": i})
+		_ = p.Publish("run.stats", map[string]any{"n": i})
+	}
+	kept := map[string]int{}
+	var progress []int
+	for _, m := range b.messages() {
+		kept[m.RoutingKey]++
+		if m.RoutingKey == "run.progress" {
+			var n int
+			_, _ = fmt.Sscanf(string(m.Msg.Body), `{"n":%d`, &n)
+			progress = append(progress, n)
+		}
+	}
+	if fmt.Sprint(progress) != "[0 3 6]" {
+		t.Errorf("run.progress kept %v, want [0 3 6]", progress)
+	}
+	if kept["telemetry"] != 7 || kept["run.stats"] != 7 {
+		t.Errorf("rates <= 1 dropped messages: telemetry %d, run.stats %d", kept["telemetry"], kept["run.stats"])
+	}
+	if got := p.Stats().Sampled; got != 4 {
+		t.Errorf("Sampled = %d, want 4", got)
+	}
+}
+
+func TestSamplingIsDeterministicUnderConcurrency(t *testing.T) {
+	for run := range 2 {
+		p, b := newTestPublisher(t, Options{SampleRates: map[string]int{"run.progress": 10}})
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 125 {
+					_ = p.Publish("run.progress", map[string]any{})
+				}
+			}()
+		}
+		wg.Wait()
+		if got := len(b.messages()); got != 100 {
+			t.Errorf("run %d: kept %d of 1000, want exactly 100", run, got)
+		}
+		if got := p.Stats().Sampled; got != 900 {
+			t.Errorf("run %d: Sampled = %d, want 900", run, got)
+		}
+	}
+}
+
+func TestSamplersArePerKey(t *testing.T) {
+	s := newSamplers(map[string]int{"a": 2, "b": 2, "c": 1})
+	if _, ok := s["c"]; ok {
+		t.Error("rate 1 got a sampler")
+	}
+	var got []bool
+	for range 2 {
+		got = append(got, s["a"].keep(), s["b"].keep())
+	}
+	if fmt.Sprint(got) != "[true true false false]" {
+		t.Errorf("keep = %v, want each key to start with its own first message", got)
+	}
+}
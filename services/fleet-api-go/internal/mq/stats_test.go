@@ -0,0 +1,71 @@
+package mq
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestStatsCountPublishOutcomes(t *testing.T) {
+	p, b := newTestPublisher(t, Options{SampleRates: map[string]int{"run.progress": 2}})
+
+	for range 3 {
+		if err := p.Publish("run.created", map[string]any{"run_id": "run-1"}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	for range 4 {
+		_ = p.Publish("run.progress", map[string]any{"run_id": "run-1"})
+	}
+	if err := p.Publish("run.created", map[string]any{"bad": make(chan int)}); err == nil {
+		t.Fatal("Publish of an unencodable payload succeeded")
+	}
+	b.mu.Lock()
+	b.publishErr = &amqp.Error{Code: amqp.ResourceError, Reason: "RESOURCE_ERROR"}
+	b.mu.Unlock()
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-2"}); err == nil {
+		t.Fatal("Publish succeeded on a failing channel")
+	}
+	b.mu.Lock()
+	b.publishErr = nil
+	b.mu.Unlock()
+	b.dropConnections()
+	if err := p.Publish("run.created", map[string]any{"run_id": "run-3"}); err != nil {
+		t.Fatalf("Publish after reconnect: %v", err)
+	}
+	p.RecordRetry()
+
+	// 3 created + 2 of 4 progress + 1 after the reconnect; the encode and channel
+	// errors failed; the reconnect and RecordRetry retried; 2 progress sampled out.
+	want := Stats{Published: 6, Failed: 2, Retried: 2, Sampled: 2}
+	if got := p.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	if got := len(b.messages()); got != 6 {
+		t.Errorf("broker received %d messages, want 6", got)
+	}
+}
+
+func TestStatsCountDelayedPublishes(t *testing.T) {
+	p, b := newTestPublisher(t, Options{DelayedExchange: "amr.events.delayed"})
+	if err := p.PublishDelayed("run.timeout", map[string]any{"run_id": "run-1"}, 2*time.Second); err != nil {
+		t.Fatalf("PublishDelayed: %v", err)
+	}
+	if err := p.PublishDelayed("run.timeout", map[string]any{"bad": make(chan int)}, time.Second); err == nil {
+		t.Fatal("PublishDelayed of an unencodable payload succeeded")
+	}
+	if got, want := p.Stats(), (Stats{Published: 1, Failed: 1}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+	msgs := b.messages()
+	if len(msgs) != 1 || msgs[0].Exchange != "amr.events.delayed" || msgs[0].Msg.Headers["x-delay"] != int64(2000) {
+		t.Errorf("messages = %+v, want one on the delayed exchange with x-delay 2000", msgs)
+	}
+
+	plain, _ := newTestPublisher(t, Options{})
+	if err := plain.PublishDelayed("run.timeout", map[string]any{}, time.Second); !errors.Is(err, ErrDelayedDisabled) {
+		t.Errorf("err = %v, want ErrDelayedDisabled", err)
+	}
+}
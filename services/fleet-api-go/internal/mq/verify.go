@@ -0,0 +1,88 @@
+package mq
+
+// File: internal/mq/verify.go
+// Purpose: Passive startup checks that published events have somewhere to go.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// QueueState is what a passive declare reports about an existing queue.
+type QueueState struct {
+	Name      string
+	Messages  int
+	Consumers int
+}
+
+// InspectQueue passively declares queue and reports its depth and consumer count
+// without creating or changing it. AMQP offers no way to read a queue's bindings;
+// MissingBindings asks the management API for those. A missing queue makes the broker close the channel, so a throwaway channel is used.
+func (p *Publisher) InspectQueue(queue string) (QueueState, error) {
+	ch, err := p.pool.Load().conn.Channel()
+	if err != nil {
+		return QueueState{}, fmt.Errorf("amqp channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return QueueState{}, fmt.Errorf("inspect queue %s: %w", queue, err)
+	}
+	return QueueState{Name: q.Name, Messages: q.Messages, Consumers: q.Consumers}, nil
+}
+
+// ManagementAPI is the RabbitMQ management plugin's HTTP API, used to read what
+// AMQP cannot: a queue's bindings.
+type ManagementAPI struct {
+	// URL is the API root, e.g. http://rabbitmq:15672.
+	URL      string
+	User     string
+	Password string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// binding is one entry of GET /api/queues/{vhost}/{queue}/bindings.
+type binding struct {
+	Source     string `json:"source"`
+	RoutingKey string `json:"routing_key"`
+}
+
+// MissingBindings returns the routing keys in keys that do not bind exchange to
+// queue on the default vhost, in the order given. It fails when the API is
+// unreachable, rejects the credentials or does not know the queue.
+func (m ManagementAPI) MissingBindings(ctx context.Context, queue, exchange string, keys []string) ([]string, error) {
+	endpoint := m.URL + "/api/queues/%2F/" + url.PathEscape(queue) + "/bindings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list bindings of %s: %w", queue, err)
+	}
+	req.SetBasicAuth(m.User, m.Password)
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list bindings of %s: %w", queue, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list bindings of %s: management API returned %s", queue, resp.Status)
+	}
+	var bindings []binding
+	if err := json.NewDecoder(resp.Body).Decode(&bindings); err != nil {
+		return nil, fmt.Errorf("list bindings of %s: decode: %w", queue, err)
+	}
+	var missing []string
+	for _, key := range keys {
+		if !slices.ContainsFunc(bindings, func(b binding) bool { return b.Source == exchange && b.RoutingKey == key }) {
+			missing = append(missing, key)
+		}
+	}
+	return missing, nil
+}
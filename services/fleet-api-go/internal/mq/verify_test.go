@@ -0,0 +1,125 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/streadway/amqp"
+)
+
+func TestInspectQueueReportsState(t *testing.T) {
+	p, b := newTestPublisher(t, Options{})
+	b.queues["sim_runner.run_started"] = amqp.Queue{Name: "sim_runner.run_started", Messages: 3, Consumers: 2}
+
+	q, err := p.InspectQueue("sim_runner.run_started")
+	if err != nil {
+		t.Fatalf("InspectQueue: %v", err)
+	}
+	if q != (QueueState{Name: "sim_runner.run_started", Messages: 3, Consumers: 2}) {
+		t.Errorf("state = %+v", q)
+	}
+}
+
+func TestInspectQueueMissingKeepsPublishing(t *testing.T) {
+	p, b := newTestPublisher(t, Options{})
+
+	_, err := p.InspectQueue("sim_runner.run_started")
+	var amqpErr *amqp.Error
+	if !errors.As(err, &amqpErr) || amqpErr.Code != amqp.NotFound {
+		t.Fatalf("err = %v, want the broker's NOT_FOUND", err)
+	}
+	// The broker closed the inspection channel, not the publishing ones.
+	if err := p.Publish("run.started", map[string]any{"run_id": "run-1"}); err != nil {
+		t.Fatalf("Publish after a failed inspect: %v", err)
+	}
+	if got := b.messages(); len(got) != 1 || got[0].Channel != 0 {
+		t.Errorf("published = %+v, want one message on the pool channel", got)
+	}
+	if n := len(b.dialed()); n != 1 {
+		t.Errorf("dials = %d, want the inspect to reuse the connection", n)
+	}
+}
+
+func TestInspectQueueClosedConnection(t *testing.T) {
+	p, b := newTestPublisher(t, Options{})
+	b.dropConnections()
+	if _, err := p.InspectQueue("sim_runner.run_started"); !errors.Is(err, amqp.ErrClosed) {
+		t.Fatalf("err = %v, want amqp.ErrClosed", err)
+	}
+}
+
+// managementServer serves bindings for sim_runner.run_started on the default vhost
+// to the amr:amrpass user.
+func managementServer(t *testing.T, bindings string) ManagementAPI {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "amr" || pass != "amrpass" {
+			http.Error(w, "not authorised", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/api/queues/%2F/sim_runner.run_started/bindings" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(bindings))
+	}))
+	t.Cleanup(srv.Close)
+	return ManagementAPI{URL: srv.URL, User: "amr", Password: "amrpass"}
+}
+
+func TestMissingBindings(t *testing.T) {
+	keys := []string{"run.created", "run.started"}
+	for _, tc := range []struct {
+		name     string
+		bindings string
+		want     []string
+	}{
+		{
+			"all bound",
+			`[{"source":"","routing_key":"sim_runner.run_started"},{"source":"amr.events","routing_key":"run.created"},{"source":"amr.events","routing_key":"run.started"}]`,
+			nil,
+		},
+		{
+			"run.started missing",
+			`[{"source":"amr.events","routing_key":"run.created"}]`,
+			[]string{"run.started"},
+		},
+		{
+			"bound on another exchange",
+			`[{"source":"other","routing_key":"run.created"},{"source":"amr.events","routing_key":"run.started"}]`,
+			[]string{"run.created"},
+		},
+		{"unbound", `[]`, keys},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mgmt := managementServer(t, tc.bindings)
+			got, err := mgmt.MissingBindings(context.Background(), "sim_runner.run_started", "amr.events", keys)
+			if err != nil {
+				t.Fatalf("MissingBindings: %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("missing = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMissingBindingsErrors(t *testing.T) {
+	mgmt := managementServer(t, `[]`)
+	if _, err := mgmt.MissingBindings(context.Background(), "other.queue", "amr.events", []string{"run.created"}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("unknown queue: err = %v, want a 404", err)
+	}
+	mgmt.Password = "wrong"
+	if _, err := mgmt.MissingBindings(context.Background(), "sim_runner.run_started", "amr.events", []string{"run.created"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("bad credentials: err = %v, want a 401", err)
+	}
+	bad := managementServer(t, `{"error":"nope"}`)
+	if _, err := bad.MissingBindings(context.Background(), "sim_runner.run_started", "amr.events", []string{"run.created"}); err == nil || !strings.Contains(err.Error(), "decode") {
+		t.Errorf("malformed body: err = %v, want a decode error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fleet-api-go/internal/config"
+)
+
+func TestSeedDevDataRequiresDevMode(t *testing.T) {
+	t.Setenv("DEV_MODE", "false")
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.SeedDevData(context.Background(), 1); !errors.Is(err, ErrDevModeDisabled) {
+		t.Fatalf("err = %v, want ErrDevModeDisabled", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+}
+
+func TestSeedDevDataFeatureAloneIsNotDevMode(t *testing.T) {
+	t.Setenv("DEV_MODE", "false")
+	t.Setenv("FEATURES", "dev_seed")
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.SeedDevData(context.Background(), 1); !errors.Is(err, ErrDevModeDisabled) {
+		t.Fatalf("err = %v, want ErrDevModeDisabled", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+}
+
+func TestSeedDevDataInsertsEveryScaleAndMode(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+	cfg := testConfig(t)
+	svc, fake, _ := newTestService(t, cfg)
+
+	resp, err := svc.SeedDevData(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("SeedDevData: %v", err)
+	}
+	scales := len(config.ScaleMap)
+	if want := scales * 2 * 2; resp.CreatedRuns != want || len(fake.Matching("INSERT INTO runs")) != want {
+		t.Errorf("created %d runs (%d inserts), want %d", resp.CreatedRuns, len(fake.Matching("INSERT INTO runs")), want)
+	}
+	if len(resp.Scales) != scales || resp.RunsPerMode != 2 || resp.FirstSeed != cfg.DefaultSeed || resp.LastSeed != cfg.DefaultSeed+1 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(fake.Matching("BEGIN")) != 1 || len(fake.Matching("COMMIT")) != 1 {
+		t.Error("seed data was not written in one transaction")
+	}
+
+	for _, st := range fake.Matching("INSERT INTO run_metrics") {
+		onTime := st.Args[1].(float64)
+		completed, failed, total := st.Args[5].(int64), st.Args[6].(int64), st.Args[7].(int64)
+		if onTime < 0 || onTime > 1 || completed+failed != total || failed < 0 {
+			t.Errorf("implausible metrics %v", st.Args)
+		}
+	}
+}
+
+func TestSeedDevDataCount(t *testing.T) {
+	t.Setenv("DEV_MODE", "true")
+	t.Setenv("DEV_SEED_COUNT", "4")
+	svc, _, _ := newTestService(t, testConfig(t))
+	if _, err := svc.SeedDevData(context.Background(), maxDevSeedCount+1); !IsValidation(err) {
+		t.Errorf("count over the max: err = %v, want a validation error", err)
+	}
+	resp, err := svc.SeedDevData(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("SeedDevData: %v", err)
+	}
+	if resp.RunsPerMode != 4 {
+		t.Errorf("RunsPerMode = %d, want DEV_SEED_COUNT (4)", resp.RunsPerMode)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+// File: internal/services/analytics.go
+// Purpose: Aggregate views over completed runs (trends over time, GA improvement ranking).
+
+import (
+	"context"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/models"
+)
+
+// Trends returns per-day average on-time rate for each mode, oldest day first.
+func (s *RunService) Trends(ctx context.Context, seed *int, scale string, tr models.TimeRange) (*models.RunTrendsResponse, error) {
+	if scale != "" {
+		canonical, err := canonicalScale(scale)
+		if err != nil {
+			return nil, err
+		}
+		scale = canonical
+	}
+	rows, err := s.store.GetDailyModeTrends(ctx, seed, scale, tr)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RunTrendsResponse{
+		Seed:   seed,
+		Scale:  scale,
+		From:   tr.From,
+		To:     tr.To,
+		Points: groupTrendPoints(rows),
+	}, nil
+}
+
+// groupTrendPoints folds (day, mode) rows into one point per day. Rows must be
+// ordered by day; modes with no completed runs that day are left nil.
+func groupTrendPoints(rows []models.ModeTrendRow) []models.TrendPoint {
+	points := []models.TrendPoint{}
+	for _, row := range rows {
+		date := row.Day.Format("2006-01-02")
+		if len(points) == 0 || points[len(points)-1].Date != date {
+			points = append(points, models.TrendPoint{Date: date})
+		}
+		point := &points[len(points)-1]
+		trend := &models.ModeTrend{AvgOnTimeRate: row.AvgOnTimeRate, Runs: row.Runs}
+		switch row.Mode {
+		case "baseline":
+			point.Baseline = trend
+		case "ga":
+			point.GA = trend
+		}
+	}
+	return points
+}
+
+// ListScenarios returns a page of distinct scenarios with per-mode run counts.
+func (s *RunService) ListScenarios(ctx context.Context, tr models.TimeRange, limit, offset int) (*models.ScenarioListResponse, error) {
+	scenarios, total, err := s.store.ListScenarios(ctx, tr, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ScenarioListResponse{
+		Scenarios: scenarios,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
+}
+
+// ListScenarioImprovements returns a page of scenarios ranked by how much GA's latest
+// on-time rate beats baseline's. Only runs of the current scenario-hash version count.
+func (s *RunService) ListScenarioImprovements(ctx context.Context, limit, offset int) (*models.ScenarioImprovementListResponse, error) {
+	scenarios, total, err := s.store.ListScenarioImprovements(ctx, config.ScenarioHashVersion, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range scenarios {
+		sc := &scenarios[i]
+		sc.OnTimeRateDelta = sc.GAOnTimeRate - sc.BaselineOnTimeRate
+		sc.DeltaPct = percentChange(&sc.BaselineOnTimeRate, &sc.GAOnTimeRate)
+	}
+	return &models.ScenarioImprovementListResponse{
+		Scenarios: scenarios,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
+}
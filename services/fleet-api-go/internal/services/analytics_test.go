@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestListScenarioImprovements(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.Return("COUNT(*) FROM pairs", dbtest.Result{Rows: [][]any{{3}}})
+	fake.Return("FROM pairs", dbtest.Result{Rows: [][]any{
+		{7, "large", nil, nil, "b-7", "g-7", 0.5, 0.9},
+		{42, "demo", nil, nil, "b-42", "g-42", 0.8, 0.7},
+		{9, "demo", nil, nil, "b-9", "g-9", 0.0, 0.4},
+	}})
+
+	resp, err := svc.ListScenarioImprovements(context.Background(), 50, 0)
+	if err != nil {
+		t.Fatalf("ListScenarioImprovements: %v", err)
+	}
+	if resp.Total != 3 || resp.Limit != 50 || len(resp.Scenarios) != 3 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	// The store's ranking is kept as is; the service only derives the deltas.
+	for i, want := range []struct {
+		seed  int
+		delta float64
+		pct   *float64
+	}{
+		{7, 0.4, ptr(80.0)},
+		{42, -0.1, ptr(-12.5)},
+		{9, 0.4, nil}, // no percentage over a zero baseline
+	} {
+		sc := resp.Scenarios[i]
+		if sc.Seed != want.seed || !approx(sc.OnTimeRateDelta, want.delta) {
+			t.Errorf("scenario %d = seed %d delta %v, want seed %d delta %v", i, sc.Seed, sc.OnTimeRateDelta, want.seed, want.delta)
+		}
+		if (sc.DeltaPct == nil) != (want.pct == nil) || (sc.DeltaPct != nil && !approx(*sc.DeltaPct, *want.pct)) {
+			t.Errorf("scenario %d delta_pct = %v, want %v", i, sc.DeltaPct, want.pct)
+		}
+	}
+	if args := fake.Matching("COUNT(*) FROM pairs")[0].Args; args[0] != int64(config.ScenarioHashVersion) {
+		t.Errorf("hash version arg = %v, want the current version %d", args[0], config.ScenarioHashVersion)
+	}
+}
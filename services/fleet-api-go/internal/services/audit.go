@@ -0,0 +1,74 @@
+package services
+
+// File: internal/services/audit.go
+// Purpose: Audit entries for run writes, sent to stdout (JSON lines) or the audit_log table per AUDIT_LOG.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+// maxAuditErrorLen matches the audit_log.error_message column.
+const maxAuditErrorLen = 500
+
+// auditLogger is the dedicated channel for AUDIT_LOG=stdout, kept apart from request logs.
+var auditLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil)).With("log", "audit")
+
+// audit records the outcome of a write on runID (empty when none was assigned).
+// It never fails the caller: a failed write is logged and dropped.
+func (s *RunService) audit(ctx context.Context, action, runID string, opErr error) {
+	if s.cfg.AuditLog == "off" {
+		return
+	}
+	e := models.AuditEntry{Action: action, Outcome: "ok", CreatedAt: time.Now().UTC()}
+	if runID != "" {
+		e.RunID = &runID
+	}
+	if opErr != nil {
+		msg := sanitizeErrorMessage(opErr.Error(), maxAuditErrorLen)
+		e.Outcome = "error"
+		e.ErrorMessage = &msg
+	}
+
+	if s.cfg.AuditLog == "db" {
+		// Record the entry even when the request was cancelled mid-write.
+		if err := s.store.InsertAuditEntry(context.WithoutCancel(ctx), e); err != nil {
+			log.Printf("audit %s run_id=%s: %v", action, runID, err)
+		}
+		return
+	}
+	attrs := []slog.Attr{slog.String("action", e.Action), slog.String("run_id", runID), slog.String("outcome", e.Outcome)}
+	if e.ErrorMessage != nil {
+		attrs = append(attrs, slog.String("error", *e.ErrorMessage))
+	}
+	auditLogger.LogAttrs(ctx, slog.LevelInfo, "audit", attrs...)
+}
+
+// auditStatusBatch records one run.status entry per item of a status batch. In a
+// rejected batch the offending item carries its error and the rest are rolled back.
+// A batch refused before any item was looked at (empty, too large) gets a single
+// entry without a run ID.
+func (s *RunService) auditStatusBatch(ctx context.Context, updates []models.RunStatusUpdate, resp *models.BulkStatusResponse, opErr error) {
+	if IsValidation(opErr) {
+		s.audit(ctx, "run.status", "", opErr)
+		return
+	}
+	for i, u := range updates {
+		itemErr := opErr
+		if errors.Is(opErr, ErrBatchRejected) && resp != nil {
+			if r := resp.Results[i]; r.Result == "rejected" {
+				itemErr = errors.New(r.Error)
+			} else {
+				itemErr = fmt.Errorf("%s: another item was rejected", r.Result)
+			}
+		}
+		s.audit(ctx, "run.status", u.ID, itemErr)
+	}
+}
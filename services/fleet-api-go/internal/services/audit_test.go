@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// auditEntry is one audit_log insert as the fake store received it.
+type auditEntry struct {
+	action, runID, outcome, errorMessage string
+}
+
+func auditEntries(fake *dbtest.Fake) []auditEntry {
+	var out []auditEntry
+	for _, st := range fake.Matching("INSERT INTO audit_log") {
+		e := auditEntry{action: st.Args[0].(string), outcome: st.Args[2].(string)}
+		e.runID, _ = st.Args[1].(string)
+		e.errorMessage, _ = st.Args[3].(string)
+		out = append(out, e)
+	}
+	return out
+}
+
+func newAuditedService(t *testing.T) (*RunService, *dbtest.Fake) {
+	t.Helper()
+	cfg := testConfig(t)
+	cfg.AuditLog = "db"
+	cfg.BulkStatusMax = 2
+	svc, fake, _ := newTestService(t, cfg)
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started", "run-2": "started"}))
+	return svc, fake
+}
+
+func TestAuditStatusBatchRefusedBatchIsOneEntry(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		updates []models.RunStatusUpdate
+		want    string
+	}{
+		{"empty", nil, "at least one status update is required"},
+		{"too large", []models.RunStatusUpdate{
+			{ID: "run-1", Status: "completed"}, {ID: "run-2", Status: "completed"}, {ID: "run-3", Status: "completed"},
+		}, "batch too large: 3 items (max 2)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake := newAuditedService(t)
+			if _, err := svc.BulkUpdateStatus(context.Background(), tc.updates); !IsValidation(err) {
+				t.Fatalf("err = %v, want a validation error", err)
+			}
+			entries := auditEntries(fake)
+			want := auditEntry{action: "run.status", outcome: "error", errorMessage: tc.want}
+			if len(entries) != 1 || entries[0] != want {
+				t.Fatalf("audit entries = %+v, want only %+v", entries, want)
+			}
+		})
+	}
+}
+
+func TestAuditStatusBatchRejectedItemIsPerItem(t *testing.T) {
+	svc, fake := newAuditedService(t)
+	_, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "cancelled"},
+	})
+	if err == nil {
+		t.Fatal("batch with a cancelled status was applied")
+	}
+	entries := auditEntries(fake)
+	if len(entries) != 2 {
+		t.Fatalf("audit entries = %+v, want one per item", entries)
+	}
+	if e := entries[0]; e.runID != "run-1" || !strings.Contains(e.errorMessage, "another item was rejected") {
+		t.Errorf("entry 0 = %+v, want run-1 rolled back", e)
+	}
+	if e := entries[1]; e.runID != "run-2" || !strings.Contains(e.errorMessage, "status must be") {
+		t.Errorf("entry 1 = %+v, want run-2 rejected", e)
+	}
+}
+
+func TestAuditStatusBatchAppliedIsPerItem(t *testing.T) {
+	svc, fake := newAuditedService(t)
+	if _, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "failed"},
+	}); err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	entries := auditEntries(fake)
+	if len(entries) != 2 || entries[0].runID != "run-1" || entries[1].runID != "run-2" {
+		t.Fatalf("audit entries = %+v, want run-1 and run-2", entries)
+	}
+	for _, e := range entries {
+		if e.outcome != "ok" {
+			t.Errorf("entry %+v, want ok", e)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+// File: internal/services/cancel.go
+// Purpose: Run cancellation and the run.cancelled event that stops the simulation.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"fleet-api-go/internal/models"
+)
+
+const maxCancelReasonLen = 500
+
+// CancelRun moves a started run to cancelled and, once that update has committed,
+// publishes run.cancelled so sim-runner stops the simulation. A publish failure
+// leaves the run cancelled and is reported as Published=false. Every attempt is
+// audited as run.cancel.
+func (s *RunService) CancelRun(ctx context.Context, runID string, req models.CancelRunRequest) (_ *models.CancelRunResponse, err error) {
+	defer func() { s.audit(ctx, "run.cancel", runID, err) }()
+
+	if utf8.RuneCountInString(req.Reason) > maxCancelReasonLen {
+		return nil, invalidf("reason must be at most %d characters", maxCancelReasonLen)
+	}
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	if isTerminalStatus(run.Status) {
+		return nil, fmt.Errorf("%w: %s", ErrRunTerminal, run.Status)
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+	cancelled, err := s.store.CancelRun(ctx, runID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		// The run finished between the read and the update.
+		return nil, fmt.Errorf("%w: run finished before it could be cancelled", ErrRunTerminal)
+	}
+	run.Status = "cancelled"
+
+	resp := &models.CancelRunResponse{RunID: run.ID, Status: run.Status}
+	if err := s.publishWithRetry(ctx, "run.cancelled", buildRunCancelledEvent(*run, req.Reason).Payload()); err != nil {
+		log.Printf("publish run.cancelled run_id=%s: %v", run.ID, err)
+		return resp, nil
+	}
+	resp.Published = true
+	return resp, nil
+}
+
+// buildRunCancelledEvent builds the run.cancelled event for a cancelled run.
+func buildRunCancelledEvent(run models.Run, reason string) models.RunCancelledEvent {
+	return models.RunCancelledEvent{
+		EventID:             uuid.NewString(),
+		EventType:           "run.cancelled",
+		RunID:               run.ID,
+		Mode:                run.Mode,
+		Seed:                run.Seed,
+		Scale:               run.Scale,
+		Robots:              run.RobotsCount,
+		Jobs:                run.JobsCount,
+		ScenarioHash:        run.ScenarioHash,
+		ScenarioHashVersion: run.ScenarioHashVersion,
+		Reason:              reason,
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// cancelRun scripts the run row CancelRun reads, with the given status and fleet override.
+func cancelRun(fake *dbtest.Fake, status string, robots, jobs any) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", robots, jobs, "hash-abc", 2, status, nil, now, now, nil, nil, nil, nil, nil, nil},
+	}})
+}
+
+func TestCancelRunPublishesScenarioIdentity(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	cancelRun(fake, "started", 7, 30)
+	// The event goes out only after the status update.
+	pub.fail = func(string) error {
+		if n := len(fake.Matching("SET status = 'cancelled'")); n != 1 {
+			t.Errorf("published with %d cancel updates recorded, want the update first", n)
+		}
+		return nil
+	}
+
+	resp, err := svc.CancelRun(context.Background(), "run-1", models.CancelRunRequest{Reason: "wrong seed"})
+	if err != nil {
+		t.Fatalf("CancelRun: %v", err)
+	}
+	if resp.RunID != "run-1" || resp.Status != "cancelled" || !resp.Published {
+		t.Errorf("resp = %+v, want run-1 cancelled and published", resp)
+	}
+
+	events := pub.published("run.cancelled")
+	if len(events) != 1 {
+		t.Fatalf("run.cancelled events = %d, want 1", len(events))
+	}
+	payload := events[0].Payload
+	if id, _ := payload["event_id"].(string); id == "" {
+		t.Error("event_id is empty")
+	}
+	delete(payload, "event_id")
+	want := map[string]any{
+		"event_type":            "run.cancelled",
+		"run_id":                "run-1",
+		"mode":                  "ga",
+		"seed":                  42,
+		"scale":                 "demo",
+		"robots":                7,
+		"jobs":                  30,
+		"scenario_hash":         "hash-abc",
+		"scenario_hash_version": 2,
+		"reason":                "wrong seed",
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("payload = %v, want %v", payload, want)
+	}
+
+	update := fake.Matching("SET status = 'cancelled'")[0]
+	if reason, ok := update.Args[0].(string); !ok || reason != "wrong seed" || update.Args[1] != "run-1" {
+		t.Errorf("update args = %v, want the reason and run ID", update.Args)
+	}
+}
+
+func TestCancelRunPayloadOmitsUnsetFields(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	cancelRun(fake, "started", nil, nil)
+
+	if _, err := svc.CancelRun(context.Background(), "run-1", models.CancelRunRequest{}); err != nil {
+		t.Fatalf("CancelRun: %v", err)
+	}
+	payload := pub.published("run.cancelled")[0].Payload
+	for _, key := range []string{"robots", "jobs", "reason"} {
+		if _, ok := payload[key]; ok {
+			t.Errorf("payload has %s = %v, want it omitted", key, payload[key])
+		}
+	}
+	if update := fake.Matching("SET status = 'cancelled'")[0]; update.Args[0] != nil {
+		t.Errorf("error_message arg = %v, want NULL without a reason", update.Args[0])
+	}
+}
+
+func TestCancelRunNotPublishedWhenUpdateLoses(t *testing.T) {
+	for _, tc := range []struct {
+		name, status string
+		affected     int64
+		wantUpdate   bool
+	}{
+		{"already terminal", "completed", 1, false},
+		{"finished before the update", "started", 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, pub := newTestService(t, testConfig(t))
+			cancelRun(fake, tc.status, nil, nil)
+			fake.Return("SET status = 'cancelled'", dbtest.Result{RowsAffected: tc.affected})
+
+			_, err := svc.CancelRun(context.Background(), "run-1", models.CancelRunRequest{})
+			if !errors.Is(err, ErrRunTerminal) {
+				t.Fatalf("err = %v, want ErrRunTerminal", err)
+			}
+			if n := len(fake.Matching("SET status = 'cancelled'")); (n == 1) != tc.wantUpdate {
+				t.Errorf("cancel updates = %d, want update %v", n, tc.wantUpdate)
+			}
+			if n := len(pub.published("run.cancelled")); n != 0 {
+				t.Errorf("published %d run.cancelled events, want none", n)
+			}
+		})
+	}
+}
+
+func TestCancelRunPublishFailureKeepsTheCancel(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PublishAttempts = 2
+	cfg.PublishBackoff = time.Millisecond
+	svc, fake, pub := newTestService(t, cfg)
+	cancelRun(fake, "started", nil, nil)
+	pub.fail = func(string) error { return errors.New("broker down") }
+
+	resp, err := svc.CancelRun(context.Background(), "run-1", models.CancelRunRequest{})
+	if err != nil {
+		t.Fatalf("CancelRun: %v", err)
+	}
+	if resp.Status != "cancelled" || resp.Published {
+		t.Errorf("resp = %+v, want cancelled but not published", resp)
+	}
+	if n := len(fake.Matching("SET status = 'cancelled'")); n != 1 {
+		t.Errorf("cancel updates = %d, want 1", n)
+	}
+}
+
+func TestCancelRunValidation(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	long := make([]rune, maxCancelReasonLen+1)
+	for i := range long {
+		long[i] = 'é'
+	}
+	if _, err := svc.CancelRun(context.Background(), "run-1", models.CancelRunRequest{Reason: string(long)}); !IsValidation(err) {
+		t.Errorf("long reason err = %v, want a validation error", err)
+	}
+	if _, err := svc.CancelRun(context.Background(), "missing", models.CancelRunRequest{}); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("unknown run err = %v, want ErrRunNotFound", err)
+	}
+	if n := len(fake.Matching("SET status = 'cancelled'")); n != 0 {
+		t.Errorf("cancel updates = %d, want none", n)
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+// File: internal/services/compare.go
+// Purpose: Derived values for baseline vs GA comparisons (deltas, normalization).
+
+import (
+	"math"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/models"
+)
+
+// resolveFleetSize returns the robots/jobs used for a scenario: the explicit
+// overrides when present, otherwise the scale preset.
+func resolveFleetSize(scale string, robots, jobs *int) (int, int, string) {
+	if robots != nil && jobs != nil {
+		return *robots, *jobs, "override"
+	}
+	preset := config.ScaleMap[scale]
+	return preset.Robots, preset.Jobs, "preset"
+}
+
+// buildCompareDelta computes GA minus baseline. Count-like metrics (distance, job
+// counts) are also normalized per robot and per job so different fleet sizes compare
+// fairly; rates and averages are already size-independent and are not normalized.
+func buildCompareDelta(baseline, ga *models.RunMetrics, robots, jobs int, source string) *models.CompareDelta {
+	if baseline == nil || ga == nil {
+		return nil
+	}
+	delta := &models.CompareDelta{
+		OnTimeRate:        ga.OnTimeRate - baseline.OnTimeRate,
+		TotalDistance:     ga.TotalDistance - baseline.TotalDistance,
+		AvgCompletionTime: ga.AvgCompletionTime - baseline.AvgCompletionTime,
+		MaxLateness:       ga.MaxLateness - baseline.MaxLateness,
+		CompletedJobs:     ga.CompletedJobs - baseline.CompletedJobs,
+		FailedJobs:        ga.FailedJobs - baseline.FailedJobs,
+		Robots:            robots,
+		Jobs:              jobs,
+		FleetSource:       source,
+	}
+	delta.PerRobot = normalizeDelta(delta, robots)
+	delta.PerJob = normalizeDelta(delta, jobs)
+	return delta
+}
+
+func normalizeDelta(delta *models.CompareDelta, size int) models.NormalizedDelta {
+	if size <= 0 {
+		return models.NormalizedDelta{}
+	}
+	n := float64(size)
+	return models.NormalizedDelta{
+		TotalDistance: delta.TotalDistance / n,
+		CompletedJobs: float64(delta.CompletedJobs) / n,
+		FailedJobs:    float64(delta.FailedJobs) / n,
+	}
+}
+
+// missingModes lists which compared modes have no completed run, in a stable order.
+func missingModes(baseline, ga *models.RunMetrics) []string {
+	missing := []string{}
+	if baseline == nil {
+		missing = append(missing, "baseline")
+	}
+	if ga == nil {
+		missing = append(missing, "ga")
+	}
+	return missing
+}
+
+// compareMetricNames is the fixed row order of the flat compare format.
+var compareMetricNames = []string{
+	"on_time_rate",
+	"total_distance",
+	"avg_completion_time",
+	"max_lateness",
+	"completed_jobs",
+	"failed_jobs",
+	"total_jobs",
+}
+
+// metricValue returns the named metric as a float, or nil when m is nil.
+func metricValue(m *models.RunMetrics, name string) *float64 {
+	if m == nil {
+		return nil
+	}
+	var v float64
+	switch name {
+	case "on_time_rate":
+		v = m.OnTimeRate
+	case "total_distance":
+		v = m.TotalDistance
+	case "avg_completion_time":
+		v = m.AvgCompletionTime
+	case "max_lateness":
+		v = m.MaxLateness
+	case "completed_jobs":
+		v = float64(m.CompletedJobs)
+	case "failed_jobs":
+		v = float64(m.FailedJobs)
+	case "total_jobs":
+		v = float64(m.TotalJobs)
+	default:
+		return nil
+	}
+	return &v
+}
+
+// percentChange returns (to - from) / from * 100, or nil when either side is
+// missing or from is zero.
+func percentChange(from, to *float64) *float64 {
+	if from == nil || to == nil || *from == 0 {
+		return nil
+	}
+	pct := (*to - *from) / *from * 100
+	return &pct
+}
+
+// FlattenCompare converts a compare response into one row per metric, which is
+// easier to paste into spreadsheets than the nested shape. Every metric row is
+// present; values for a missing mode are null.
+func FlattenCompare(resp *models.CompareRunsResponse) []models.CompareMetricRow {
+	rows := make([]models.CompareMetricRow, 0, len(compareMetricNames))
+	for _, name := range compareMetricNames {
+		baseline := metricValue(resp.Baseline, name)
+		ga := metricValue(resp.GA, name)
+		rows = append(rows, models.CompareMetricRow{
+			Metric:   name,
+			Baseline: baseline,
+			GA:       ga,
+			DeltaPct: percentChange(baseline, ga),
+		})
+	}
+	return rows
+}
+
+// compareStatus summarizes which modes have data, with a message clients can show as-is.
+func compareStatus(baseline, ga *models.RunMetrics) (string, string) {
+	switch {
+	case baseline == nil && ga == nil:
+		return "no_data", "no completed baseline or ga run for this scenario yet"
+	case baseline == nil:
+		return "partial", "no completed baseline run for this scenario yet"
+	case ga == nil:
+		return "partial", "no completed ga run for this scenario yet"
+	}
+	return "complete", "both modes have a completed run"
+}
+
+// averageMetrics averages metrics rows (newest first) into one baseline. Counts are
+// rounded to the nearest whole job; RunID is empty since no single run matches and
+// ComputedAt is the newest row's. It returns nil for no rows.
+func averageMetrics(rows []models.RunMetrics) *models.RunMetrics {
+	if len(rows) == 0 {
+		return nil
+	}
+	var onTime, distance, completion, lateness float64
+	var completed, failed, total int
+	for _, m := range rows {
+		onTime += m.OnTimeRate
+		distance += m.TotalDistance
+		completion += m.AvgCompletionTime
+		lateness += m.MaxLateness
+		completed += m.CompletedJobs
+		failed += m.FailedJobs
+		total += m.TotalJobs
+	}
+	n := float64(len(rows))
+	return &models.RunMetrics{
+		OnTimeRate:        onTime / n,
+		TotalDistance:     distance / n,
+		AvgCompletionTime: completion / n,
+		MaxLateness:       lateness / n,
+		CompletedJobs:     int(math.Round(float64(completed) / n)),
+		FailedJobs:        int(math.Round(float64(failed) / n)),
+		TotalJobs:         int(math.Round(float64(total) / n)),
+		ComputedAt:        rows[0].ComputedAt,
+		RunStatus:         rows[0].RunStatus,
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// compareScenario answers Compare's reads for one scenario: completed metrics per
+// mode (newest first) and the completed-run count per mode.
+func compareScenario(fake *dbtest.Fake, runs map[string][]float64, counts map[string]int) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("COUNT(*)", func(args []any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{counts[args[2].(string)]}}}
+	})
+	fake.On("ORDER BY r.completed_at DESC", func(args []any) dbtest.Result {
+		mode := args[2].(string)
+		limit := 1
+		if len(args) == 5 { // GetRecentRunMetricsByMode appends its LIMIT
+			limit = int(args[4].(int64))
+		}
+		var rows [][]any
+		for i, onTime := range runs[mode][:min(limit, len(runs[mode]))] {
+			rows = append(rows, []any{fmt.Sprintf("%s-%d", mode, i), onTime, 100.0, 30.0, 5.0, 45, 5, 50, now, "completed"})
+		}
+		return dbtest.Result{Rows: rows}
+	})
+}
+
+func TestCompareMinRunsBelowThreshold(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 2, "ga": 3})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 3, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Baseline != nil || resp.GA == nil {
+		t.Fatalf("baseline = %v, ga = %v, want only ga returned", resp.Baseline, resp.GA)
+	}
+	if !slices.Equal(resp.InsufficientData, []string{"baseline"}) || !slices.Contains(resp.MissingModes, "baseline") {
+		t.Errorf("insufficient = %v, missing = %v, want baseline in both", resp.InsufficientData, resp.MissingModes)
+	}
+	if resp.MinRuns != 3 || resp.RunCounts["baseline"] != 2 || resp.RunCounts["ga"] != 3 {
+		t.Errorf("min_runs = %d, run_counts = %v", resp.MinRuns, resp.RunCounts)
+	}
+	if resp.Status != "partial" || resp.Message != "baseline has fewer than 3 completed runs for this scenario" {
+		t.Errorf("status = %q, message = %q", resp.Status, resp.Message)
+	}
+	if resp.Delta != nil {
+		t.Errorf("delta = %+v, want none without a baseline", resp.Delta)
+	}
+}
+
+func TestCompareMinRunsAtThreshold(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 3, "ga": 3})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 3, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Baseline == nil || resp.GA == nil || resp.Delta == nil {
+		t.Fatalf("baseline = %v, ga = %v, delta = %v, want all set", resp.Baseline, resp.GA, resp.Delta)
+	}
+	if len(resp.InsufficientData) != 0 || resp.Status != "complete" {
+		t.Errorf("insufficient = %v, status = %q, want none and complete", resp.InsufficientData, resp.Status)
+	}
+}
+
+func TestCompareMinRunsDefaultsToConfig(t *testing.T) {
+	t.Setenv("COMPARE_MIN_RUNS", "2")
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 2, "ga": 1})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.MinRuns != 2 || !slices.Equal(resp.InsufficientData, []string{"ga"}) {
+		t.Errorf("min_runs = %d, insufficient = %v, want 2 and [ga]", resp.MinRuns, resp.InsufficientData)
+	}
+}
+
+func TestCompareRejectsNegativeMinRuns(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", -1, 0); !IsValidation(err) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+}
+
+func TestCompareBaselineAvgAveragesRecentRuns(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.9, 0.7, 0.8, 0.1}, "ga": {0.95}}, map[string]int{"baseline": 4, "ga": 1})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, 3)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	recent := fake.Matching("LIMIT ?")
+	if len(recent) != 1 || recent[0].Args[2] != "baseline" || recent[0].Args[4] != int64(3) {
+		t.Fatalf("recent selects = %+v, want one baseline read limited to 3", recent)
+	}
+	if resp.Baseline == nil || resp.Baseline.RunID != "" || !approx(resp.Baseline.OnTimeRate, 0.8) {
+		t.Errorf("baseline = %+v, want the unattributed 0.8 average of the newest 3", resp.Baseline)
+	}
+	if resp.GA == nil || resp.GA.RunID != "ga-0" {
+		t.Errorf("ga = %+v, want the latest ga run", resp.GA)
+	}
+	if resp.BaselineAvg == nil || resp.BaselineAvg.N != 3 || !slices.Equal(resp.BaselineAvg.RunIDs, []string{"baseline-0", "baseline-1", "baseline-2"}) {
+		t.Errorf("baseline_avg = %+v, want n 3 over baseline-0..2", resp.BaselineAvg)
+	}
+}
+
+func TestCompareBaselineAvgWithFewerRuns(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.9, 0.7}, "ga": {0.95}}, map[string]int{"baseline": 2, "ga": 1})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, 5)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Baseline == nil || !approx(resp.Baseline.OnTimeRate, 0.8) {
+		t.Errorf("baseline = %+v, want the average of both runs", resp.Baseline)
+	}
+	if resp.BaselineAvg == nil || resp.BaselineAvg.N != 5 || len(resp.BaselineAvg.RunIDs) != 2 {
+		t.Errorf("baseline_avg = %+v, want n 5 over the 2 available runs", resp.BaselineAvg)
+	}
+}
+
+func TestCompareBaselineAvgWithoutBaselineRuns(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"ga": {0.95}}, map[string]int{"ga": 1})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, 5)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Baseline != nil || resp.BaselineAvg != nil || !slices.Equal(resp.MissingModes, []string{"baseline"}) {
+		t.Errorf("baseline = %v, baseline_avg = %v, missing = %v", resp.Baseline, resp.BaselineAvg, resp.MissingModes)
+	}
+}
+
+func TestCompareBaselineAvgLimit(t *testing.T) {
+	svc, _, _ := newTestService(t, testConfig(t))
+	if _, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, maxBaselineAvgRuns+1); !IsValidation(err) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+}
+
+func TestAverageMetricsRoundsCounts(t *testing.T) {
+	got := averageMetrics([]models.RunMetrics{
+		{RunID: "r2", TotalDistance: 100, CompletedJobs: 9, FailedJobs: 1, TotalJobs: 10, RunStatus: "completed"},
+		{RunID: "r1", TotalDistance: 200, CompletedJobs: 10, FailedJobs: 0, TotalJobs: 10},
+	})
+	if got.RunID != "" || got.TotalDistance != 150 || got.CompletedJobs != 10 || got.FailedJobs != 1 || got.RunStatus != "completed" {
+		t.Errorf("average = %+v", got)
+	}
+	if averageMetrics(nil) != nil {
+		t.Error("averageMetrics(nil) != nil")
+	}
+}
+
+func approx(got, want float64) bool { return math.Abs(got-want) < 1e-9 }
+
+func TestCompareCanonicalizesScale(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 1, "ga": 1})
+
+	resp, err := svc.Compare(context.Background(), 42, " LARGE ", nil, nil, "", 0, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Scale != "large" || resp.Baseline == nil || resp.GA == nil {
+		t.Errorf("scale = %q, baseline = %v, ga = %v, want large with both modes", resp.Scale, resp.Baseline, resp.GA)
+	}
+	for _, st := range fake.Matching("ORDER BY r.completed_at DESC") {
+		if st.Args[1] != "large" {
+			t.Errorf("metrics read scale arg = %v, want large", st.Args[1])
+		}
+	}
+
+	if _, err := svc.Compare(context.Background(), 42, "Gigantic", nil, nil, "", 0, 0); !IsValidation(err) {
+		t.Errorf("unknown scale: err = %v, want a validation error", err)
+	}
+}
+
+func TestCompareMissingModes(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		runs        map[string][]float64
+		wantMissing []string
+		wantStatus  string
+	}{
+		{"both present", map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, []string{}, "complete"},
+		{"baseline missing", map[string][]float64{"ga": {0.9}}, []string{"baseline"}, "partial"},
+		{"ga missing", map[string][]float64{"baseline": {0.8}}, []string{"ga"}, "partial"},
+		{"both missing", map[string][]float64{}, []string{"baseline", "ga"}, "no_data"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			counts := map[string]int{}
+			for mode, runs := range tc.runs {
+				counts[mode] = len(runs)
+			}
+			compareScenario(fake, tc.runs, counts)
+
+			resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 1, 0)
+			if err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+			if resp.MissingModes == nil || !slices.Equal(resp.MissingModes, tc.wantMissing) {
+				t.Errorf("missing_modes = %v, want %v", resp.MissingModes, tc.wantMissing)
+			}
+			if resp.Status != tc.wantStatus {
+				t.Errorf("status = %q, want %q", resp.Status, tc.wantStatus)
+			}
+			if len(tc.wantMissing) > 0 && resp.Delta != nil {
+				t.Errorf("delta = %+v, want none with a missing mode", resp.Delta)
+			}
+		})
+	}
+}
+
+func TestCompareIgnoresOtherHashVersions(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	// Only runs hashed with an older algorithm exist for the scenario.
+	stale := int64(config.ScenarioHashVersion - 1)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("COUNT(*)", func(args []any) dbtest.Result {
+		if args[3] != stale {
+			return dbtest.Result{Rows: [][]any{{0}}}
+		}
+		return dbtest.Result{Rows: [][]any{{1}}}
+	})
+	fake.On("ORDER BY r.completed_at DESC", func(args []any) dbtest.Result {
+		if args[3] != stale {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{"old-" + args[2].(string), 0.8, 100.0, 30.0, 5.0, 45, 5, 50, now, "completed"}}}
+	})
+
+	resp, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 1, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Baseline != nil || resp.GA != nil || resp.Status != "no_data" {
+		t.Errorf("baseline = %v, ga = %v, status = %q, want no stale-version matches", resp.Baseline, resp.GA, resp.Status)
+	}
+	reads := fake.Matching("ORDER BY r.completed_at DESC")
+	if len(reads) == 0 {
+		t.Fatal("no metrics reads")
+	}
+	for _, st := range reads {
+		if st.Args[3] != int64(config.ScenarioHashVersion) {
+			t.Errorf("hash version arg = %v, want %d", st.Args[3], config.ScenarioHashVersion)
+		}
+	}
+}
+
+func TestCompareScaleRestrictions(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		createOnly   []string
+		compareOnly  []string
+		wantRejected bool
+	}{
+		{"unrestricted", nil, nil, false},
+		{"create-only scale", []string{"mini"}, nil, true},
+		{"compare-only scale", nil, []string{"mini"}, false},
+		{"other scale restricted", []string{"demo"}, nil, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.CreateOnlyScales, cfg.CompareOnlyScales = tc.createOnly, tc.compareOnly
+			svc, fake, _ := newTestService(t, cfg)
+			compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 1, "ga": 1})
+
+			_, err := svc.Compare(context.Background(), 42, "Mini", nil, nil, "", 0, 0)
+			if got := errors.Is(err, ErrScaleNotAllowed); got != tc.wantRejected {
+				t.Fatalf("err = %v, want rejected %v", err, tc.wantRejected)
+			}
+			if !tc.wantRejected && err != nil {
+				t.Fatalf("Compare: %v", err)
+			}
+			if tc.wantRejected && len(fake.Statements()) != 0 {
+				t.Errorf("rejected compare still queried: %v", fake.Statements())
+			}
+		})
+	}
+}
+
+func TestBuildCompareDeltaNormalization(t *testing.T) {
+	baseline := &models.RunMetrics{TotalDistance: 100, CompletedJobs: 40, FailedJobs: 10}
+	ga := &models.RunMetrics{TotalDistance: 80, CompletedJobs: 45, FailedJobs: 5}
+
+	d := buildCompareDelta(baseline, ga, 4, 20, "override")
+	if d.PerRobot != (models.NormalizedDelta{TotalDistance: -5, CompletedJobs: 1.25, FailedJobs: -1.25}) {
+		t.Errorf("per_robot = %+v", d.PerRobot)
+	}
+	if d.PerJob != (models.NormalizedDelta{TotalDistance: -1, CompletedJobs: 0.25, FailedJobs: -0.25}) {
+		t.Errorf("per_job = %+v", d.PerJob)
+	}
+	// A zero fleet dimension leaves the normalized deltas at zero instead of dividing by it.
+	if d := buildCompareDelta(baseline, ga, 0, 20, "override"); d.PerRobot != (models.NormalizedDelta{}) {
+		t.Errorf("per_robot with 0 robots = %+v, want zeros", d.PerRobot)
+	}
+	if buildCompareDelta(nil, ga, 4, 20, "override") != nil {
+		t.Error("delta without a baseline, want nil")
+	}
+}
+
+func TestFlattenCompare(t *testing.T) {
+	resp := &models.CompareRunsResponse{
+		Baseline: &models.RunMetrics{OnTimeRate: 0.8, TotalDistance: 100, AvgCompletionTime: 30, MaxLateness: 0, CompletedJobs: 40, FailedJobs: 10, TotalJobs: 50},
+		GA:       &models.RunMetrics{OnTimeRate: 0.9, TotalDistance: 80, AvgCompletionTime: 33, MaxLateness: 4, CompletedJobs: 45, FailedJobs: 5, TotalJobs: 50},
+	}
+	rows := FlattenCompare(resp)
+	var names []string
+	for _, row := range rows {
+		names = append(names, row.Metric)
+	}
+	if !slices.Equal(names, compareMetricNames) {
+		t.Fatalf("metrics = %v, want every metric in order %v", names, compareMetricNames)
+	}
+	byName := map[string]models.CompareMetricRow{}
+	for _, row := range rows {
+		byName[row.Metric] = row
+	}
+	for name, want := range map[string]float64{"total_distance": -20, "avg_completion_time": 10, "failed_jobs": -50, "total_jobs": 0} {
+		if got := byName[name].DeltaPct; got == nil || !approx(*got, want) {
+			t.Errorf("%s delta_pct = %v, want %v", name, got, want)
+		}
+	}
+	if row := byName["max_lateness"]; row.Baseline == nil || *row.Baseline != 0 || row.DeltaPct != nil {
+		t.Errorf("max_lateness = %+v, want a zero baseline with no percent change", row)
+	}
+
+	rows = FlattenCompare(&models.CompareRunsResponse{GA: resp.GA})
+	if len(rows) != len(compareMetricNames) {
+		t.Fatalf("rows without a baseline = %d, want every metric", len(rows))
+	}
+	for _, row := range rows {
+		if row.Baseline != nil || row.GA == nil || row.DeltaPct != nil {
+			t.Errorf("%s = %+v, want null baseline and delta", row.Metric, row)
+		}
+	}
+}
+
+func TestCompareReadsInOneSnapshot(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 1, "ga": 1})
+
+	if _, err := svc.Compare(context.Background(), 42, "demo", nil, nil, "", 0, 0); err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	log := fake.Statements()
+	if len(log) < 3 || log[0].Query != "BEGIN" || log[len(log)-1].Query != "COMMIT" {
+		t.Fatalf("statements = %+v, want every read inside one transaction", log)
+	}
+	if want := []any{sql.LevelRepeatableRead, true}; !reflect.DeepEqual(log[0].Args, want) {
+		t.Errorf("BEGIN options = %v, want a read-only REPEATABLE READ snapshot", log[0].Args)
+	}
+	for _, st := range log[1 : len(log)-1] {
+		if st.Query == "BEGIN" || st.Query == "COMMIT" {
+			t.Errorf("statements = %+v, want a single transaction", log)
+			break
+		}
+	}
+}
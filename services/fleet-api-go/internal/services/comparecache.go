@@ -0,0 +1,130 @@
+package services
+
+// File: internal/services/comparecache.go
+// Purpose: Short-lived LRU cache of the store reads behind Compare.
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+// compareKey identifies one scenario's compare reads. Robots/jobs are 0 when the
+// compare is not filtered by fleet size; baselineAvg is 0 for the latest baseline.
+type compareKey struct {
+	seed        int
+	scale       string
+	robots      int
+	jobs        int
+	baselineAvg int
+}
+
+func newCompareKey(seed int, scale string, robots, jobs *int, baselineAvg int) compareKey {
+	k := compareKey{seed: seed, scale: scale, baselineAvg: baselineAvg}
+	if robots != nil && jobs != nil {
+		k.robots, k.jobs = *robots, *jobs
+	}
+	return k
+}
+
+// compareReads is what Compare reads from one snapshot: the metrics compared for
+// each mode, the runs averaged into the baseline, and completed-run counts. The
+// values are shared between cache hits and must not be modified.
+type compareReads struct {
+	baseline *models.RunMetrics
+	ga       *models.RunMetrics
+	averaged []models.RunMetrics
+	counts   map[string]int
+}
+
+type compareCacheEntry struct {
+	key     compareKey
+	reads   compareReads
+	expires time.Time
+}
+
+// compareCache is a size-bounded LRU of compareReads with a fixed TTL. It is safe
+// for concurrent use. A nil *compareCache caches nothing. Only completions seen by
+// this process invalidate entries; runs sim-runner completes in MySQL stay hidden
+// until the entry's TTL runs out.
+type compareCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List // front is most recently used
+	entries map[compareKey]*list.Element
+}
+
+// newCompareCache returns a cache of up to size entries kept for ttl, or nil
+// (caching disabled) when either is zero.
+func newCompareCache(size int, ttl time.Duration) *compareCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &compareCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: map[compareKey]*list.Element{},
+	}
+}
+
+func (c *compareCache) get(k compareKey) (compareReads, bool) {
+	if c == nil {
+		return compareReads{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[k]
+	if !ok {
+		return compareReads{}, false
+	}
+	entry := el.Value.(*compareCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, k)
+		return compareReads{}, false
+	}
+	c.order.MoveToFront(el)
+	return entry.reads, true
+}
+
+func (c *compareCache) put(k compareKey, reads compareReads) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[k]; ok {
+		el.Value = &compareCacheEntry{key: k, reads: reads, expires: expires}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[k] = c.order.PushFront(&compareCacheEntry{key: k, reads: reads, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*compareCacheEntry).key)
+	}
+}
+
+// invalidateScenario drops every entry for seed and scale, whatever its fleet-size
+// filter or baseline averaging, since a newly completed run can change all of them.
+func (c *compareCache) invalidateScenario(seed int, scale string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, el := range c.entries {
+		if k.seed == seed && k.scale == scale {
+			c.order.Remove(el)
+			delete(c.entries, k)
+		}
+	}
+}
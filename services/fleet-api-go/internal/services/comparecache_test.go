@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// testClock is a settable clock for compareCache.now.
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newTestCompareCache(size int, ttl time.Duration) (*compareCache, *testClock) {
+	clock := &testClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := newCompareCache(size, ttl)
+	c.now = clock.now
+	return c, clock
+}
+
+func readsWithCount(n int) compareReads {
+	return compareReads{counts: map[string]int{"baseline": n}}
+}
+
+func TestCompareCacheDisabled(t *testing.T) {
+	for _, tc := range []struct {
+		size int
+		ttl  time.Duration
+	}{{0, time.Second}, {8, 0}} {
+		c := newCompareCache(tc.size, tc.ttl)
+		if c != nil {
+			t.Fatalf("newCompareCache(%d, %s) = %v, want nil", tc.size, tc.ttl, c)
+		}
+		k := newCompareKey(42, "demo", nil, nil, 0)
+		c.put(k, readsWithCount(1))
+		if _, ok := c.get(k); ok {
+			t.Error("nil cache returned a hit")
+		}
+		c.invalidateScenario(42, "demo")
+	}
+}
+
+func TestCompareCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestCompareCache(2, time.Minute)
+	a := newCompareKey(1, "demo", nil, nil, 0)
+	b := newCompareKey(2, "demo", nil, nil, 0)
+	d := newCompareKey(3, "demo", nil, nil, 0)
+
+	c.put(a, readsWithCount(1))
+	c.put(b, readsWithCount(2))
+	if _, ok := c.get(a); !ok { // a is now the most recently used
+		t.Fatal("a missing before eviction")
+	}
+	c.put(d, readsWithCount(3))
+
+	if _, ok := c.get(b); ok {
+		t.Error("b survived; it was the least recently used")
+	}
+	for _, k := range []compareKey{a, d} {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("%+v evicted", k)
+		}
+	}
+	if c.order.Len() != 2 || len(c.entries) != 2 {
+		t.Errorf("cache holds %d/%d entries, want 2", c.order.Len(), len(c.entries))
+	}
+}
+
+func TestCompareCachePutReplacesEntry(t *testing.T) {
+	c, _ := newTestCompareCache(2, time.Minute)
+	k := newCompareKey(1, "demo", nil, nil, 0)
+	c.put(k, readsWithCount(1))
+	c.put(k, readsWithCount(5))
+	got, ok := c.get(k)
+	if !ok || got.counts["baseline"] != 5 {
+		t.Fatalf("get = %v, %v; want the replaced reads", got, ok)
+	}
+	if c.order.Len() != 1 {
+		t.Errorf("cache holds %d entries, want 1", c.order.Len())
+	}
+}
+
+func TestCompareCacheExpiresAfterTTL(t *testing.T) {
+	c, clock := newTestCompareCache(4, 5*time.Second)
+	k := newCompareKey(1, "demo", nil, nil, 0)
+	c.put(k, readsWithCount(1))
+
+	clock.t = clock.t.Add(5*time.Second - time.Millisecond)
+	if _, ok := c.get(k); !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+	clock.t = clock.t.Add(time.Millisecond)
+	if _, ok := c.get(k); ok {
+		t.Fatal("entry served at its TTL")
+	}
+	if len(c.entries) != 0 {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestCompareCacheInvalidateScenario(t *testing.T) {
+	c, _ := newTestCompareCache(8, time.Minute)
+	robots, jobs := 10, 50
+	drop := []compareKey{
+		newCompareKey(42, "demo", nil, nil, 0),
+		newCompareKey(42, "demo", &robots, &jobs, 0),
+		newCompareKey(42, "demo", nil, nil, 5),
+	}
+	keep := []compareKey{
+		newCompareKey(42, "large", nil, nil, 0),
+		newCompareKey(7, "demo", nil, nil, 0),
+	}
+	for _, k := range append(append([]compareKey{}, drop...), keep...) {
+		c.put(k, readsWithCount(1))
+	}
+
+	c.invalidateScenario(42, "demo")
+	for _, k := range drop {
+		if _, ok := c.get(k); ok {
+			t.Errorf("%+v survived invalidation", k)
+		}
+	}
+	for _, k := range keep {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("%+v was invalidated", k)
+		}
+	}
+}
+
+func TestCompareServesCachedReadsUntilCompletion(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.CompareCacheSize, cfg.CompareCacheTTL = 16, time.Minute
+	svc, fake, _ := newTestService(t, cfg)
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started"}))
+	fake.Return("SELECT COUNT(*)", dbtest.Result{Rows: [][]any{{0}}})
+
+	compare := func() {
+		t.Helper()
+		if _, err := svc.Compare(context.Background(), 42, "small", nil, nil, "", 0, 0); err != nil {
+			t.Fatalf("Compare: %v", err)
+		}
+	}
+	snapshots := func() int { return len(fake.Matching("BEGIN")) }
+
+	compare()
+	compare()
+	if got := snapshots(); got != 1 {
+		t.Fatalf("snapshot reads = %d, want 1 (second compare cached)", got)
+	}
+
+	// run-1 is seed 42 / small (see statusRows), so completing it invalidates the entry.
+	if _, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{{ID: "run-1", Status: "completed"}}); err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	before := snapshots()
+	compare()
+	if got := snapshots() - before; got != 1 {
+		t.Errorf("snapshot reads after completion = %d, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+// File: internal/services/delta.go
+// Purpose: Run-vs-run metric deltas against a pinned baseline run.
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// RunDelta compares runID's metrics against those of the pinned baseline run, one
+// row per metric with the absolute and percent change. Unlike compare, the two runs
+// need not share a scenario or mode. It returns ErrMetricsNotFound (naming the run)
+// when either side has no metrics.
+func (s *RunService) RunDelta(ctx context.Context, runID, againstID string) (*models.RunDeltaResponse, error) {
+	if againstID == "" {
+		return nil, invalidf("against is required")
+	}
+	run, err := s.store.GetRunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMetricsNotFound, runID)
+	}
+	against, err := s.store.GetRunMetrics(ctx, againstID)
+	if err != nil {
+		return nil, err
+	}
+	if against == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMetricsNotFound, againstID)
+	}
+	return &models.RunDeltaResponse{
+		RunID:   runID,
+		Against: againstID,
+		Metrics: buildMetricDeltas(against, run),
+	}, nil
+}
+
+// buildMetricDeltas returns run minus against for every compare metric.
+func buildMetricDeltas(against, run *models.RunMetrics) []models.MetricDeltaRow {
+	rows := make([]models.MetricDeltaRow, 0, len(compareMetricNames))
+	for _, name := range compareMetricNames {
+		from := metricValue(against, name)
+		to := metricValue(run, name)
+		rows = append(rows, models.MetricDeltaRow{
+			Metric:   name,
+			Against:  *from,
+			Value:    *to,
+			Delta:    *to - *from,
+			DeltaPct: percentChange(from, to),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+// deltaMetrics answers GetRunMetrics with on_time_rate, total_distance and failed_jobs per run ID.
+func deltaMetrics(fake *dbtest.Fake, metrics map[string][3]float64) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM run_metrics rm", func(args []any) dbtest.Result {
+		m, ok := metrics[args[0].(string)]
+		if !ok {
+			return dbtest.Result{}
+		}
+		failed := int(m[2])
+		return dbtest.Result{Rows: [][]any{{args[0], m[0], m[1], 30.0, 5.0, 50 - failed, failed, 50, now, "completed"}}}
+	})
+}
+
+func TestRunDelta(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	deltaMetrics(fake, map[string][3]float64{"base": {0.8, 200, 0}, "run-1": {0.9, 150, 2}})
+
+	resp, err := svc.RunDelta(context.Background(), "run-1", "base")
+	if err != nil {
+		t.Fatalf("RunDelta: %v", err)
+	}
+	if resp.RunID != "run-1" || resp.Against != "base" || len(resp.Metrics) != len(compareMetricNames) {
+		t.Fatalf("resp = %+v", resp)
+	}
+	rows := make(map[string]int, len(resp.Metrics))
+	for i, row := range resp.Metrics {
+		rows[row.Metric] = i
+	}
+
+	onTime := resp.Metrics[rows["on_time_rate"]]
+	if onTime.Against != 0.8 || onTime.Value != 0.9 || !approx(onTime.Delta, 0.1) || onTime.DeltaPct == nil || !approx(*onTime.DeltaPct, 12.5) {
+		t.Errorf("on_time_rate = %+v, want +0.1 (+12.5%%)", onTime)
+	}
+	dist := resp.Metrics[rows["total_distance"]]
+	if dist.Delta != -50 || dist.DeltaPct == nil || !approx(*dist.DeltaPct, -25) {
+		t.Errorf("total_distance = %+v, want -50 (-25%%)", dist)
+	}
+	// The baseline had no failed jobs, so there is no percent change to report.
+	if failed := resp.Metrics[rows["failed_jobs"]]; failed.Delta != 2 || failed.DeltaPct != nil {
+		t.Errorf("failed_jobs = %+v, want +2 with a null percent", failed)
+	}
+}
+
+func TestRunDeltaMissingMetrics(t *testing.T) {
+	for _, tc := range []struct{ name, runID, against string }{
+		{"run without metrics", "pending", "base"},
+		{"baseline without metrics", "run-1", "pending"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			deltaMetrics(fake, map[string][3]float64{"base": {0.8, 200, 0}, "run-1": {0.9, 150, 2}})
+
+			_, err := svc.RunDelta(context.Background(), tc.runID, tc.against)
+			if !errors.Is(err, ErrMetricsNotFound) || !strings.Contains(err.Error(), "pending") {
+				t.Errorf("err = %v, want ErrMetricsNotFound naming the run", err)
+			}
+		})
+	}
+}
+
+func TestRunDeltaRequiresAgainst(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.RunDelta(context.Background(), "run-1", ""); !IsValidation(err) {
+		t.Errorf("err = %v, want a validation error", err)
+	}
+	if got := len(fake.Statements()); got != 0 {
+		t.Errorf("statements = %d, want none before validation passes", got)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+// File: internal/services/errors.go
+// Purpose: Sentinel errors that handlers map to HTTP status codes.
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrRunNotFound is returned when a referenced run does not exist.
+	ErrRunNotFound = errors.New("run not found")
+	// ErrPeerNotFound is returned when a run has no completed opposite-mode run for its scenario.
+	ErrPeerNotFound = errors.New("no completed peer run for scenario")
+	// ErrMetricsNotFound is returned when a run referenced by a metrics comparison has no metrics.
+	ErrMetricsNotFound = errors.New("metrics not found")
+	// ErrRunTerminal is returned when an operation requires a non-terminal run.
+	ErrRunTerminal = errors.New("run is in a terminal status")
+	// ErrRunNotFailed is returned when retrying a run that did not fail (without force).
+	ErrRunNotFailed = errors.New("run has not failed")
+	// ErrRunNotStarted is returned (with ErrBatchRejected) when a status update targets a run that already finished.
+	ErrRunNotStarted = errors.New("run is not started")
+	// ErrBatchRejected is returned when a batch was not applied; per-item results explain why.
+	ErrBatchRejected = errors.New("batch rejected")
+	// ErrTooManyActiveRuns is returned when MAX_ACTIVE_RUNS non-terminal runs already exist.
+	ErrTooManyActiveRuns = errors.New("too many active runs")
+	// ErrFleetTooManyRobots is returned under strict fleet validation when robots exceed jobs.
+	ErrFleetTooManyRobots = errors.New("robots exceed jobs")
+	// ErrScaleNotAllowed is returned when a scale is restricted from the requested use (create or compare).
+	ErrScaleNotAllowed = errors.New("scale not allowed")
+	// ErrGAParamsRequireGA is returned when ga_params are sent for a non-ga run.
+	ErrGAParamsRequireGA = errors.New("ga_params are only accepted for ga runs")
+	// ErrReplanIntervalRequiresGA is returned when replan_interval_s is sent for a non-ga run.
+	ErrReplanIntervalRequiresGA = errors.New("replan_interval_s is only accepted for ga runs")
+	// ErrMaintenanceWindow is returned (as *MaintenanceError) when runs are created during maintenance.
+	ErrMaintenanceWindow = errors.New("run creation is paused for a maintenance window")
+	// ErrExperimentNotFound is returned when a referenced experiment does not exist.
+	ErrExperimentNotFound = errors.New("experiment not found")
+	// ErrDevModeDisabled is returned by development-only operations when DEV_MODE is off.
+	ErrDevModeDisabled = errors.New("dev mode is disabled")
+)
+
+// ValidationError marks an error caused by invalid client input.
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// invalidf returns a ValidationError with a formatted message.
+func invalidf(format string, args ...any) error {
+	return &ValidationError{msg: fmt.Sprintf(format, args...)}
+}
+
+// IsValidation reports whether err (or anything it wraps) is a ValidationError.
+func IsValidation(err error) bool {
+	var v *ValidationError
+	return errors.As(err, &v)
+}
@@ -0,0 +1,60 @@
+package services
+
+// File: internal/services/events.go
+// Purpose: Recording the run events fleet-api publishes, and paginated and tail
+// access to that history.
+
+import (
+	"context"
+	"log"
+
+	"fleet-api-go/internal/models"
+)
+
+// ListRunEvents returns a page of an existing run's event history, oldest first.
+// When tail > 0 it returns the last tail events instead, ignoring limit and offset.
+func (s *RunService) ListRunEvents(ctx context.Context, runID string, limit, offset, tail int) (*models.RunEventListResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	total, err := s.store.CountRunEvents(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if tail > 0 {
+		limit = tail
+		offset = max(total-tail, 0)
+	}
+	events, err := s.store.ListRunEvents(ctx, runID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RunEventListResponse{
+		RunID:  runID,
+		Events: events,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// recordRunEvent appends a published run event to run_events. Like an audit entry
+// it never fails the caller: the event is already on the broker, so a failed write
+// is logged and dropped. Payloads without a run_id are not run history.
+func (s *RunService) recordRunEvent(ctx context.Context, routingKey string, payload map[string]any) {
+	runID, _ := payload["run_id"].(string)
+	if runID == "" {
+		return
+	}
+	eventType, _ := payload["event_type"].(string)
+	if eventType == "" {
+		eventType = routingKey
+	}
+	if err := s.store.InsertRunEvent(context.WithoutCancel(ctx), runID, eventType, routingKey, payload); err != nil {
+		log.Printf("record %s run_id=%s: %v", routingKey, runID, err)
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"fleet-api-go/internal/models"
+)
+
+func TestPublishedRunEventsAreRecorded(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RunStartedAlias = true
+	svc, fake, _ := newTestService(t, cfg)
+
+	resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"})
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	inserts := fake.Matching("INSERT INTO run_events")
+	if len(inserts) != 2 {
+		t.Fatalf("run_events inserts = %d, want run.created and run.started", len(inserts))
+	}
+	for i, want := range []string{"run.created", "run.started"} {
+		args := inserts[i].Args
+		if args[0] != resp.RunID || args[1] != want || args[2] != want {
+			t.Errorf("insert %d args = %v, want run %s %s", i, args[:3], resp.RunID, want)
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(args[3].([]byte), &payload); err != nil {
+			t.Fatalf("payload: %v", err)
+		}
+		if payload["run_id"] != resp.RunID || payload["event_type"] != want {
+			t.Errorf("insert %d payload = %v", i, payload)
+		}
+	}
+}
+
+func TestFailedPublishIsNotRecorded(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PublishAttempts = 1
+	svc, fake, pub := newTestService(t, cfg)
+	pub.fail = func(string) error { return errors.New("broker down") }
+
+	if _, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"}); err == nil {
+		t.Fatal("CreateRun succeeded with the broker down")
+	}
+	if got := len(fake.Matching("INSERT INTO run_events")); got != 0 {
+		t.Errorf("run_events inserts = %d, want 0", got)
+	}
+}
+
+func TestHeartbeatIsNotRunHistory(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	svc.recordRunEvent(context.Background(), "fleet.heartbeat", map[string]any{"service": "fleet-api"})
+	if got := len(fake.Matching("INSERT INTO run_events")); got != 0 {
+		t.Errorf("run_events inserts = %d, want 0", got)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+// File: internal/services/experiments.go
+// Purpose: Experiments grouping related runs, with per-mode aggregate metrics.
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fleet-api-go/internal/models"
+)
+
+const maxExperimentNameLength = 128
+
+// CreateExperiment validates and stores a named experiment. Its ID comes from the
+// same generator as run IDs (RUN_ID_SCHEME).
+func (s *RunService) CreateExperiment(ctx context.Context, req models.CreateExperimentRequest) (*models.Experiment, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidf("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxExperimentNameLength {
+		return nil, invalidf("name must be at most %d characters", maxExperimentNameLength)
+	}
+	id, err := s.newRunID()
+	if err != nil {
+		return nil, err
+	}
+	exp := models.Experiment{
+		ID:        id,
+		Name:      name,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if desc := strings.TrimSpace(req.Description); desc != "" {
+		exp.Description = &desc
+	}
+	if err := s.store.CreateExperiment(ctx, exp); err != nil {
+		return nil, err
+	}
+	return &exp, nil
+}
+
+// GetExperiment returns an experiment with all of its runs and per-mode aggregates,
+// or ErrExperimentNotFound.
+func (s *RunService) GetExperiment(ctx context.Context, id string) (*models.ExperimentResponse, error) {
+	exp, err := s.store.GetExperiment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exp == nil {
+		return nil, ErrExperimentNotFound
+	}
+	runs, err := s.store.ListExperimentRuns(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		setDuration(&runs[i])
+	}
+	aggregates, err := s.store.GetExperimentAggregates(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ExperimentResponse{
+		Experiment: *exp,
+		Runs:       runs,
+		Aggregates: aggregates,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestCreateExperiment(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+
+	exp, err := svc.CreateExperiment(context.Background(), models.CreateExperimentRequest{Name: "  q3 sweep ", Description: " "})
+	if err != nil {
+		t.Fatalf("CreateExperiment: %v", err)
+	}
+	if exp.ID == "" || exp.Name != "q3 sweep" || exp.Description != nil || exp.CreatedAt.IsZero() {
+		t.Errorf("experiment = %+v, want a generated ID, trimmed name and no description", exp)
+	}
+	inserts := fake.Matching("INSERT INTO experiments")
+	if len(inserts) != 1 || inserts[0].Args[0] != exp.ID || inserts[0].Args[1] != "q3 sweep" || inserts[0].Args[2] != nil {
+		t.Errorf("inserts = %+v", inserts)
+	}
+}
+
+func TestCreateExperimentValidation(t *testing.T) {
+	for _, name := range []string{"", "   ", strings.Repeat("x", maxExperimentNameLength+1)} {
+		svc, fake, _ := newTestService(t, testConfig(t))
+		if _, err := svc.CreateExperiment(context.Background(), models.CreateExperimentRequest{Name: name}); !IsValidation(err) {
+			t.Errorf("name of %d chars: err = %v, want a validation error", len(name), err)
+		}
+		if got := len(fake.Matching("INSERT INTO experiments")); got != 0 {
+			t.Errorf("inserts = %d, want none", got)
+		}
+	}
+}
+
+func TestCreateRunAttachesExperiment(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("FROM experiments WHERE id = ?", func(args []any) dbtest.Result {
+		if args[0] != "exp-1" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{"exp-1", "q3 sweep", nil, now}}}
+	})
+
+	resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline", ExperimentID: ptr("exp-1")})
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	if resp.ExperimentID == nil || *resp.ExperimentID != "exp-1" {
+		t.Errorf("experiment_id = %v, want exp-1", resp.ExperimentID)
+	}
+	if inserts := fake.Matching("INSERT INTO runs"); len(inserts) != 1 || inserts[0].Args[13] != "exp-1" {
+		t.Errorf("run inserts = %+v, want experiment_id stored", inserts)
+	}
+
+	_, err = svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline", ExperimentID: ptr("exp-9")})
+	if !errors.Is(err, ErrExperimentNotFound) || !strings.Contains(err.Error(), "exp-9") {
+		t.Errorf("unknown experiment: err = %v, want ErrExperimentNotFound naming it", err)
+	}
+	if got := len(fake.Matching("INSERT INTO runs")); got != 1 {
+		t.Errorf("run inserts = %d, want the unknown experiment's run refused", got)
+	}
+}
+
+func TestGetExperiment(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM experiments WHERE id = ?", dbtest.Result{Rows: [][]any{{"exp-1", "q3 sweep", "grid over seeds", now}}})
+	fake.Return("WHERE experiment_id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "baseline", 7, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now.Add(time.Minute), nil, nil, "exp-1", 100.0, nil},
+		{"run-2", "ga", 7, "demo", nil, nil, "hash", 2, "started", nil, now, now, nil, nil, nil, "exp-1", 40.0, nil},
+	}})
+	fake.Return("GROUP BY r.mode", dbtest.Result{Rows: [][]any{
+		{"baseline", 1, 1, 1, 0.8, 100.0, 30.0, 5.0},
+		{"ga", 1, 0, 0, nil, nil, nil, nil},
+	}})
+
+	resp, err := svc.GetExperiment(context.Background(), "exp-1")
+	if err != nil {
+		t.Fatalf("GetExperiment: %v", err)
+	}
+	if resp.ID != "exp-1" || resp.Description == nil || *resp.Description != "grid over seeds" {
+		t.Errorf("experiment = %+v", resp.Experiment)
+	}
+	if len(resp.Runs) != 2 || resp.Runs[0].DurationSeconds == nil || *resp.Runs[0].DurationSeconds != 60 || resp.Runs[1].DurationSeconds != nil {
+		t.Errorf("runs = %+v, want both runs with the finished one's duration", resp.Runs)
+	}
+	if len(resp.Aggregates) != 2 || resp.Aggregates[0].AvgOnTimeRate == nil || *resp.Aggregates[0].AvgOnTimeRate != 0.8 || resp.Aggregates[1].AvgOnTimeRate != nil {
+		t.Errorf("aggregates = %+v, want baseline averaged and ga null", resp.Aggregates)
+	}
+}
+
+func TestGetExperimentNotFound(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.GetExperiment(context.Background(), "missing"); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("err = %v, want ErrExperimentNotFound", err)
+	}
+	if got := len(fake.Matching("WHERE experiment_id = ?")); got != 0 {
+		t.Errorf("run reads = %d, want none for a missing experiment", got)
+	}
+}
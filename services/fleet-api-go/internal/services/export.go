@@ -0,0 +1,84 @@
+package services
+
+// File: internal/services/export.go
+// Purpose: Self-contained export of one run (metadata, metrics, history, notes, events).
+
+import (
+	"context"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+// runExportFormatVersion identifies the layout of models.RunExport; bump it when
+// sections are renamed or removed so archived exports can be told apart.
+const runExportFormatVersion = 1
+
+// exportPageSize is how many rows each section is read in while assembling an export.
+const exportPageSize = 500
+
+// ExportRun bundles everything stored for an existing run into one document.
+// Sub-records the run does not have yet (metrics for a started run, notes, ...)
+// come back as null or empty lists rather than failing the export.
+func (s *RunService) ExportRun(ctx context.Context, runID string) (*models.RunExport, error) {
+	run, err := s.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+
+	metrics, err := s.store.GetRunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	evaluateThresholds(metrics, s.cfg.MetricThresholds)
+
+	history, err := collectPages(func(limit, offset int) ([]models.RunMetricsSnapshot, error) {
+		page, _, err := s.store.ListRunMetricsHistory(ctx, runID, limit, offset)
+		return page, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	notes, err := collectPages(func(limit, offset int) ([]models.RunNote, error) {
+		page, _, err := s.store.ListRunNotes(ctx, runID, limit, offset)
+		return page, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	events, err := collectPages(func(limit, offset int) ([]models.RunEventRecord, error) {
+		return s.store.ListRunEvents(ctx, runID, limit, offset)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RunExport{
+		FormatVersion:  runExportFormatVersion,
+		ExportedAt:     time.Now().UTC().Truncate(time.Second),
+		Run:            *run,
+		Metrics:        metrics,
+		MetricsHistory: history,
+		Notes:          notes,
+		Events:         events,
+	}, nil
+}
+
+// collectPages reads fetch in exportPageSize pages until a short page, returning
+// every row in order (an empty, non-nil slice when there are none).
+func collectPages[T any](fetch func(limit, offset int) ([]T, error)) ([]T, error) {
+	out := []T{}
+	for offset := 0; ; offset += exportPageSize {
+		page, err := fetch(exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if len(page) < exportPageSize {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+var exportTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// exportRun scripts the run row ExportRun starts from, with the given status.
+func exportRun(fake *dbtest.Fake, status string) {
+	var completed any
+	if status == "completed" {
+		completed = exportTime.Add(time.Minute)
+	}
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, status, nil, exportTime, exportTime, completed, nil, nil, nil, nil, nil},
+	}})
+}
+
+func TestExportRunIncludesEverySection(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	exportRun(fake, "completed")
+	fake.Return("FROM run_tags", dbtest.Result{Rows: [][]any{{"run-1", "exp:q3"}}})
+	fake.Return("WHERE rm.run_id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.9, 100.0, 30.0, 5.0, 9, 1, 10, exportTime, "completed"},
+	}})
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{2}}})
+	fake.Return("FROM run_metrics_history", dbtest.Result{Rows: [][]any{
+		{30, 1.0, 50.0, 20.0, 0.0, 4, 0, 10, exportTime},
+		{60, 0.9, 100.0, 30.0, 5.0, 9, 1, 10, exportTime.Add(time.Minute)},
+	}})
+	fake.Return("SELECT COUNT(*) FROM run_notes", dbtest.Result{Rows: [][]any{{1}}})
+	fake.Return("FROM run_notes", dbtest.Result{Rows: [][]any{{int64(1), "run-1", "alice", "flaky robot 3", exportTime}}})
+	fake.Return("FROM run_events", dbtest.Result{Rows: [][]any{
+		{int64(1), "run-1", "run.created", "run.created", []byte(`{"run_id":"run-1"}`), exportTime},
+		{int64(2), "run-1", "run.completed", "run.completed", []byte(`{"run_id":"run-1"}`), exportTime.Add(time.Minute)},
+	}})
+
+	export, err := svc.ExportRun(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("ExportRun: %v", err)
+	}
+	if export.FormatVersion != runExportFormatVersion || export.ExportedAt.IsZero() {
+		t.Errorf("header = %d/%v", export.FormatVersion, export.ExportedAt)
+	}
+	if export.Run.ID != "run-1" || !slices.Equal(export.Run.Tags, []string{"exp:q3"}) || export.Run.DurationSeconds == nil {
+		t.Errorf("run = %+v, want run-1 with tags and a duration", export.Run)
+	}
+	if export.Metrics == nil || export.Metrics.OnTimeRate != 0.9 {
+		t.Errorf("metrics = %+v", export.Metrics)
+	}
+	if len(export.MetricsHistory) != 2 || len(export.Notes) != 1 || export.Notes[0].Author != "alice" {
+		t.Errorf("history = %d rows, notes = %+v", len(export.MetricsHistory), export.Notes)
+	}
+	if len(export.Events) != 2 || string(export.Events[0].Payload) != `{"run_id":"run-1"}` {
+		t.Errorf("events = %+v", export.Events)
+	}
+}
+
+func TestExportRunWithoutSubRecords(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	exportRun(fake, "started")
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{0}}})
+	fake.Return("SELECT COUNT(*) FROM run_notes", dbtest.Result{Rows: [][]any{{0}}})
+
+	export, err := svc.ExportRun(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("ExportRun: %v", err)
+	}
+	if export.Metrics != nil {
+		t.Errorf("metrics = %+v, want null for a started run", export.Metrics)
+	}
+	if export.MetricsHistory == nil || export.Notes == nil || export.Events == nil {
+		t.Errorf("sections = %v/%v/%v, want empty lists, not null", export.MetricsHistory, export.Notes, export.Events)
+	}
+}
+
+func TestExportRunReadsEveryPage(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	exportRun(fake, "completed")
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{0}}})
+	fake.Return("SELECT COUNT(*) FROM run_notes", dbtest.Result{Rows: [][]any{{0}}})
+	// ListRunEvents binds LIMIT offset, count.
+	fake.On("FROM run_events", func(args []any) dbtest.Result {
+		n := exportPageSize
+		if args[1].(int64) > 0 {
+			n = 3
+		}
+		rows := make([][]any, n)
+		for i := range rows {
+			rows[i] = []any{args[1].(int64) + int64(i), "run-1", "run.progress", "run.progress", []byte(`{}`), exportTime}
+		}
+		return dbtest.Result{Rows: rows}
+	})
+
+	export, err := svc.ExportRun(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("ExportRun: %v", err)
+	}
+	if len(export.Events) != exportPageSize+3 || export.Events[exportPageSize].ID != exportPageSize {
+		t.Errorf("events = %d, want %d across two pages", len(export.Events), exportPageSize+3)
+	}
+	if n := len(fake.Matching("FROM run_events")); n != 2 {
+		t.Errorf("event pages read = %d, want 2", n)
+	}
+}
+
+func TestExportRunUnknownRun(t *testing.T) {
+	svc, _, _ := newTestService(t, testConfig(t))
+	if _, err := svc.ExportRun(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("err = %v, want ErrRunNotFound", err)
+	}
+}
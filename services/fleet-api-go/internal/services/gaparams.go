@@ -0,0 +1,43 @@
+package services
+
+// File: internal/services/gaparams.go
+// Purpose: Validation of per-run GA optimizer parameters (ga_params).
+
+import "fleet-api-go/internal/models"
+
+// Bounds for ga_params: well above the optimizer defaults (64 x 80), but small enough
+// that one run cannot tie up the optimizer.
+const (
+	minGAPopulation  = 2
+	maxGAPopulation  = 1000
+	maxGAGenerations = 1000
+)
+
+// minReplanIntervalS is the shortest per-run GA replan interval accepted; shorter
+// intervals would keep the optimizer busy replanning nearly every tick.
+const minReplanIntervalS = 5
+
+// validateGAParams checks each set field against its bounds. An elite larger than
+// the population would leave no room for offspring, so it is rejected too; that
+// check only applies when both are set, as the optimizer's defaults are not known here.
+func validateGAParams(p *models.GAParams) error {
+	if p.PopulationSize != nil && (*p.PopulationSize < minGAPopulation || *p.PopulationSize > maxGAPopulation) {
+		return invalidf("ga_params.population_size must be between %d and %d", minGAPopulation, maxGAPopulation)
+	}
+	if p.Generations != nil && (*p.Generations < 1 || *p.Generations > maxGAGenerations) {
+		return invalidf("ga_params.generations must be between 1 and %d", maxGAGenerations)
+	}
+	if p.EliteSize != nil && *p.EliteSize < 0 {
+		return invalidf("ga_params.elite_size must be >= 0")
+	}
+	if p.EliteSize != nil && p.PopulationSize != nil && *p.EliteSize >= *p.PopulationSize {
+		return invalidf("ga_params.elite_size must be less than population_size")
+	}
+	if p.MutationRate != nil && (*p.MutationRate < 0 || *p.MutationRate > 1) {
+		return invalidf("ga_params.mutation_rate must be between 0 and 1")
+	}
+	if p.CrossoverRate != nil && (*p.CrossoverRate < 0 || *p.CrossoverRate > 1) {
+		return invalidf("ga_params.crossover_rate must be between 0 and 1")
+	}
+	return nil
+}
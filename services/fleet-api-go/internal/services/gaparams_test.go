@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/models"
+)
+
+func TestValidateGAParams(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		params models.GAParams
+		want   string // "" for valid
+	}{
+		{"empty", models.GAParams{}, ""},
+		{"all at their bounds", models.GAParams{
+			PopulationSize: ptr(maxGAPopulation), Generations: ptr(maxGAGenerations), EliteSize: ptr(0),
+			MutationRate: ptr(1.0), CrossoverRate: ptr(0.0),
+		}, ""},
+		{"smallest population", models.GAParams{PopulationSize: ptr(minGAPopulation), EliteSize: ptr(1)}, ""},
+		{"population too small", models.GAParams{PopulationSize: ptr(1)}, "population_size must be between 2 and 1000"},
+		{"population too large", models.GAParams{PopulationSize: ptr(1001)}, "population_size must be between 2 and 1000"},
+		{"zero generations", models.GAParams{Generations: ptr(0)}, "generations must be between 1 and 1000"},
+		{"negative elite", models.GAParams{EliteSize: ptr(-1)}, "elite_size must be >= 0"},
+		{"elite fills the population", models.GAParams{PopulationSize: ptr(10), EliteSize: ptr(10)}, "elite_size must be less than population_size"},
+		{"elite without a population", models.GAParams{EliteSize: ptr(500)}, ""},
+		{"mutation above 1", models.GAParams{MutationRate: ptr(1.5)}, "mutation_rate must be between 0 and 1"},
+		{"negative crossover", models.GAParams{CrossoverRate: ptr(-0.1)}, "crossover_rate must be between 0 and 1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateGAParams(&tc.params)
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("validateGAParams = %v, want nil", err)
+				}
+				return
+			}
+			if !IsValidation(err) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("validateGAParams = %v, want a validation error containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateRunGAParams(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	params := &models.GAParams{PopulationSize: ptr(32), MutationRate: ptr(0.2)}
+
+	resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "ga", GAParams: params})
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	if resp.GAParams != params {
+		t.Errorf("response ga_params = %+v, want the request's", resp.GAParams)
+	}
+	events := pub.published("run.created")
+	if len(events) != 1 || events[0].Payload["ga_params"] != params {
+		t.Fatalf("run.created events = %+v, want one carrying ga_params", events)
+	}
+	if got := len(fake.Matching("INSERT INTO runs")); got != 1 {
+		t.Errorf("run inserts = %d, want 1", got)
+	}
+}
+
+func TestCreateRunGAParamsRequireGA(t *testing.T) {
+	for _, mode := range []string{"baseline", ""} {
+		svc, fake, pub := newTestService(t, testConfig(t))
+		_, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: mode, GAParams: &models.GAParams{Generations: ptr(10)}})
+		if !errors.Is(err, ErrGAParamsRequireGA) || !strings.Contains(err.Error(), "mode is baseline") {
+			t.Errorf("mode %q: err = %v, want ErrGAParamsRequireGA naming baseline", mode, err)
+		}
+		if len(fake.Matching("INSERT INTO runs")) != 0 || len(pub.published("run.created")) != 0 {
+			t.Errorf("mode %q: a rejected run was stored or published", mode)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+// File: internal/services/heartbeat.go
+// Purpose: Periodic fleet.heartbeat events so consumers can detect a silent publisher.
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RunHeartbeat publishes fleet.heartbeat every interval until ctx is cancelled.
+// Failures are logged and the loop keeps going; a missed beat is the signal.
+func (s *RunService) RunHeartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	s.heartbeatLoop(ctx, ticker.C)
+}
+
+// heartbeatLoop publishes one heartbeat per tick, stamped with the tick's time,
+// until ctx is cancelled. Tests drive it with their own tick channel.
+func (s *RunService) heartbeatLoop(ctx context.Context, ticks <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticks:
+			if err := s.publishHeartbeat(ctx, now); err != nil {
+				log.Printf("publish fleet.heartbeat: %v", err)
+			}
+		}
+	}
+}
+
+func (s *RunService) publishHeartbeat(ctx context.Context, now time.Time) error {
+	active, err := s.store.CountActiveRuns(ctx)
+	if err != nil {
+		return err
+	}
+	return s.publisher.PublishContext(ctx, "fleet.heartbeat", map[string]any{
+		"event_id":    uuid.NewString(),
+		"event_type":  "fleet.heartbeat",
+		"service":     s.cfg.RabbitConnectionName(),
+		"uptime_s":    int(now.Sub(s.startedAt).Seconds()),
+		"active_runs": active,
+	})
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestHeartbeatLoop(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.Return("WHERE status = 'started'", dbtest.Result{Rows: [][]any{{3}}})
+	start := svc.startedAt
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.heartbeatLoop(ctx, ticks)
+	}()
+	// The two beats are stamped 30s and 90s after startup, whatever the wall clock says.
+	ticks <- start.Add(30 * time.Second)
+	ticks <- start.Add(90 * time.Second)
+	waitForEvents(t, pub, "fleet.heartbeat", 2)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("heartbeatLoop did not return after cancel")
+	}
+
+	beats := pub.published("fleet.heartbeat")
+	if len(beats) != 2 {
+		t.Fatalf("heartbeats = %d, want one per tick", len(beats))
+	}
+	for i, wantUptime := range []int{30, 90} {
+		p := beats[i].Payload
+		if p["uptime_s"] != wantUptime || p["active_runs"] != 3 || p["event_type"] != "fleet.heartbeat" {
+			t.Errorf("beat %d = %v, want uptime %d and 3 active runs", i, p, wantUptime)
+		}
+	}
+	if beats[0].Payload["event_id"] == beats[1].Payload["event_id"] {
+		t.Error("heartbeats share an event_id")
+	}
+}
+
+func TestHeartbeatLoopKeepsGoingAfterAFailure(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.Return("WHERE status = 'started'", dbtest.Result{Rows: [][]any{{0}}})
+	failures := 1
+	pub.fail = func(string) error {
+		if failures > 0 {
+			failures--
+			return errors.New("broker down")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		svc.heartbeatLoop(ctx, ticks)
+	}()
+	ticks <- svc.startedAt.Add(time.Second)
+	ticks <- svc.startedAt.Add(2 * time.Second)
+	waitForEvents(t, pub, "fleet.heartbeat", 1)
+	cancel()
+	<-done
+
+	if beats := pub.published("fleet.heartbeat"); len(beats) != 1 || beats[0].Payload["uptime_s"] != 2 {
+		t.Errorf("heartbeats = %+v, want only the second beat", beats)
+	}
+}
+
+// waitForEvents waits up to a second for n events with routingKey to be published.
+func waitForEvents(t *testing.T, pub *fakePublisher, routingKey string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(pub.published(routingKey)) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s events = %d after 1s, want %d", routingKey, len(pub.published(routingKey)), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+// File: internal/services/history.go
+// Purpose: A run's metrics history (how its metrics evolved while it ran).
+
+import (
+	"context"
+
+	"fleet-api-go/internal/models"
+)
+
+// RunMetricsHistory returns a page of metrics snapshots for an existing run, oldest first.
+func (s *RunService) RunMetricsHistory(ctx context.Context, runID string, limit, offset int) (*models.RunMetricsHistoryResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	history, total, err := s.store.ListRunMetricsHistory(ctx, runID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RunMetricsHistoryResponse{
+		RunID:   runID,
+		History: history,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestRunMetricsHistory(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "started", nil, now, now, nil, nil, nil, nil, nil, nil},
+	}})
+	fake.Return("SELECT COUNT(*) FROM run_metrics_history", dbtest.Result{Rows: [][]any{{2}}})
+	fake.Return("FROM run_metrics_history", dbtest.Result{Rows: [][]any{
+		{60, 1.0, 40.0, 20.0, 0.0, 3, 0, 10, now},
+		{120, 0.8, 80.0, 25.0, 2.0, 6, 1, 10, now.Add(time.Minute)},
+	}})
+
+	resp, err := svc.RunMetricsHistory(context.Background(), "run-1", 50, 0)
+	if err != nil {
+		t.Fatalf("RunMetricsHistory: %v", err)
+	}
+	if resp.RunID != "run-1" || resp.Total != 2 || resp.Limit != 50 || len(resp.History) != 2 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if resp.History[0].OnTimeRate != 1.0 || resp.History[1].OnTimeRate != 0.8 {
+		t.Errorf("on_time_rate progression = %v, %v, want 1.0 then 0.8", resp.History[0].OnTimeRate, resp.History[1].OnTimeRate)
+	}
+}
+
+func TestRunMetricsHistoryUnknownRun(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.RunMetricsHistory(context.Background(), "missing", 50, 0); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("err = %v, want ErrRunNotFound", err)
+	}
+	if n := len(fake.Matching("run_metrics_history")); n != 0 {
+		t.Errorf("read history %d times for an unknown run", n)
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+// File: internal/services/ids.go
+// Purpose: Run ID generation (UUIDv4 or time-sortable ULID, per RUN_ID_SCHEME).
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// idGenerator returns a new unique run ID.
+type idGenerator func() (string, error)
+
+// newIDGenerator returns the generator for a RUN_ID_SCHEME value. Load has
+// already validated the scheme; anything other than "ulid" means UUIDs.
+func newIDGenerator(scheme string) idGenerator {
+	if scheme == "ulid" {
+		return func() (string, error) { return newULID(time.Now()) }
+	}
+	return func() (string, error) { return uuid.NewString(), nil }
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a 26-character ULID: a 48-bit millisecond timestamp followed by
+// 80 random bits. IDs created in different milliseconds sort by creation time;
+// within one millisecond their order is random.
+func newULID(now time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("generate ulid entropy: %w", err)
+	}
+
+	// Encode the 128 bits as 26 base32 digits, least significant first.
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:]), nil
+}
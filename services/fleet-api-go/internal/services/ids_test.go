@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"fleet-api-go/internal/models"
+)
+
+// isULID reports whether id is 26 Crockford base32 digits that fit in 128 bits.
+func isULID(id string) bool {
+	if len(id) != 26 || id[0] > '7' {
+		return false
+	}
+	for _, c := range id {
+		if !strings.ContainsRune(crockford, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ulidTime decodes the millisecond timestamp in a ULID's first ten digits.
+func ulidTime(id string) time.Time {
+	var ms int64
+	for _, c := range id[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, c))
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+func TestNewULID(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 678_000_000, time.UTC)
+	id, err := newULID(now)
+	if err != nil {
+		t.Fatalf("newULID: %v", err)
+	}
+	if !isULID(id) {
+		t.Fatalf("id %q is not a ULID", id)
+	}
+	if got := ulidTime(id); !got.Equal(now) {
+		t.Errorf("timestamp = %v, want %v", got, now)
+	}
+
+	other, _ := newULID(now)
+	if other == id || other[:10] != id[:10] {
+		t.Errorf("same-millisecond IDs %q and %q, want a shared timestamp and distinct entropy", id, other)
+	}
+
+	// IDs from later milliseconds sort after earlier ones.
+	var ids []string
+	for i := range 5 {
+		id, err := newULID(now.Add(time.Duration(i) * time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if !slices.IsSorted(ids) {
+		t.Errorf("ids %v are not in creation order", ids)
+	}
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	for _, tc := range []struct {
+		scheme string
+		valid  func(string) bool
+	}{
+		{"uuid", func(id string) bool {
+			u, err := uuid.Parse(id)
+			return err == nil && u.Version() == 4
+		}},
+		{"ulid", isULID},
+	} {
+		t.Run(tc.scheme, func(t *testing.T) {
+			gen := newIDGenerator(tc.scheme)
+			seen := map[string]bool{}
+			for range 100 {
+				id, err := gen()
+				if err != nil {
+					t.Fatalf("generate: %v", err)
+				}
+				if !tc.valid(id) {
+					t.Fatalf("id %q is not a valid %s", id, tc.scheme)
+				}
+				if seen[id] {
+					t.Fatalf("duplicate id %q", id)
+				}
+				seen[id] = true
+			}
+		})
+	}
+}
+
+func TestCreateRunUsesTheConfiguredIDScheme(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RunIDScheme = "ulid"
+	svc, fake, _ := newTestService(t, cfg)
+
+	before := time.Now().UTC().Truncate(time.Millisecond)
+	resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"})
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	if !isULID(resp.RunID) {
+		t.Fatalf("run ID %q is not a ULID", resp.RunID)
+	}
+	if at := ulidTime(resp.RunID); at.Before(before) || at.After(time.Now().UTC()) {
+		t.Errorf("ULID timestamp %v is not the creation time", at)
+	}
+	if insert := fake.Matching("INSERT INTO runs"); len(insert) != 1 || insert[0].Args[0] != resp.RunID {
+		t.Errorf("insert = %+v, want the generated ID stored", insert)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+// File: internal/services/leaderboard.go
+// Purpose: Best completed runs by one metric, optionally gated by per-metric bounds.
+
+import (
+	"context"
+	"sort"
+
+	"fleet-api-go/internal/models"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+)
+
+// leaderboardHigherIsBetter gives each rankable metric's natural direction, used when
+// the request does not set one.
+var leaderboardHigherIsBetter = map[string]bool{
+	"on_time_rate":        true,
+	"total_distance":      false,
+	"avg_completion_time": false,
+	"max_lateness":        false,
+	"completed_jobs":      true,
+	"failed_jobs":         false,
+	"total_jobs":          true,
+}
+
+// Leaderboard ranks completed runs by q.Metric (default on_time_rate). Bounds in
+// q.Min/q.Max are validated here and pushed into the query, so runs below the bar
+// never take a leaderboard slot. direction is "asc", "desc" or empty for the
+// metric's natural direction.
+func (s *RunService) Leaderboard(ctx context.Context, q models.LeaderboardQuery, direction string) (*models.LeaderboardResponse, error) {
+	if q.Metric == "" {
+		q.Metric = "on_time_rate"
+	}
+	higherIsBetter, ok := leaderboardHigherIsBetter[q.Metric]
+	if !ok {
+		return nil, invalidf("metric must be one of %v", leaderboardMetricNames())
+	}
+	switch direction {
+	case "":
+		q.Descending = higherIsBetter
+	case "asc", "desc":
+		q.Descending = direction == "desc"
+	default:
+		return nil, invalidf("direction must be asc or desc")
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultLeaderboardLimit
+	case q.Limit < 0 || q.Limit > maxLeaderboardLimit:
+		return nil, invalidf("limit must be between 1 and %d", maxLeaderboardLimit)
+	}
+	if q.Scale != "" {
+		scale, err := canonicalScale(q.Scale)
+		if err != nil {
+			return nil, err
+		}
+		q.Scale = scale
+	}
+	if q.Mode != "" && q.Mode != "baseline" && q.Mode != "ga" {
+		return nil, invalidf("mode must be baseline or ga")
+	}
+	if err := validateLeaderboardBounds(q.Min, q.Max); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.store.ListLeaderboard(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Rank = i + 1
+		entries[i].Value = *metricValue(&entries[i].Metrics, q.Metric)
+	}
+	resp := &models.LeaderboardResponse{
+		Metric:    q.Metric,
+		Direction: "asc",
+		Min:       q.Min,
+		Max:       q.Max,
+		Runs:      entries,
+	}
+	if q.Descending {
+		resp.Direction = "desc"
+	}
+	return resp, nil
+}
+
+// validateLeaderboardBounds checks bound names and ranges: on_time_rate is a fraction
+// in [0, 1], every other metric is non-negative, and a min may not exceed its max.
+func validateLeaderboardBounds(minBounds, maxBounds map[string]float64) error {
+	for _, bounds := range []map[string]float64{minBounds, maxBounds} {
+		for name, v := range bounds {
+			if _, ok := leaderboardHigherIsBetter[name]; !ok {
+				return invalidf("unknown metric %q in bound; must be one of %v", name, leaderboardMetricNames())
+			}
+			switch {
+			case name == "on_time_rate" && (v < 0 || v > 1):
+				return invalidf("on_time_rate bounds must be between 0 and 1")
+			case v < 0:
+				return invalidf("%s bounds must be >= 0", name)
+			}
+		}
+	}
+	for name, lo := range minBounds {
+		if hi, ok := maxBounds[name]; ok && lo > hi {
+			return invalidf("min_%s must not exceed max_%s", name, name)
+		}
+	}
+	return nil
+}
+
+func leaderboardMetricNames() []string {
+	names := make([]string, 0, len(leaderboardHigherIsBetter))
+	for name := range leaderboardHigherIsBetter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
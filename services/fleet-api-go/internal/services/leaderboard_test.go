@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestLeaderboardSortAndFilter(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-2", 0.95, 120.0, 30.0, 2.0, 48, 2, 50, now, "completed", "ga", 42, "demo"},
+		{"run-1", 0.85, 150.0, 30.0, 4.0, 45, 5, 50, now, "completed", "ga", 7, "demo"},
+	}})
+
+	resp, err := svc.Leaderboard(context.Background(), models.LeaderboardQuery{
+		Metric: "total_distance",
+		Mode:   "ga",
+		Scale:  "Demo",
+		Min:    map[string]float64{"on_time_rate": 0.8},
+	}, "")
+	if err != nil {
+		t.Fatalf("Leaderboard: %v", err)
+	}
+	if resp.Metric != "total_distance" || resp.Direction != "asc" || resp.Min["on_time_rate"] != 0.8 {
+		t.Errorf("resp = %+v, want total_distance ascending with the on_time_rate bound echoed", resp)
+	}
+	if len(resp.Runs) != 2 || resp.Runs[0].Rank != 1 || resp.Runs[0].Value != 120 || resp.Runs[1].Rank != 2 || resp.Runs[1].Value != 150 {
+		t.Errorf("runs = %+v, want ranked by total_distance", resp.Runs)
+	}
+
+	stmt := fake.Matching("FROM run_metrics rm")[0]
+	if !strings.Contains(stmt.Query, "rm.on_time_rate >= ?") || !strings.Contains(stmt.Query, "ORDER BY rm.total_distance ASC") {
+		t.Errorf("query %q, want the bound as a predicate and the natural direction", stmt.Query)
+	}
+	// Mode, canonical scale, the bound, then the default limit.
+	if len(stmt.Args) != 4 || stmt.Args[0] != "ga" || stmt.Args[1] != "demo" || stmt.Args[2] != 0.8 || stmt.Args[3] != int64(defaultLeaderboardLimit) {
+		t.Errorf("args = %v", stmt.Args)
+	}
+}
+
+func TestLeaderboardDirection(t *testing.T) {
+	for _, tc := range []struct {
+		metric, direction, want string
+	}{
+		{"", "", "DESC"},
+		{"on_time_rate", "asc", "ASC"},
+		{"failed_jobs", "", "ASC"},
+		{"failed_jobs", "desc", "DESC"},
+	} {
+		svc, fake, _ := newTestService(t, testConfig(t))
+		if _, err := svc.Leaderboard(context.Background(), models.LeaderboardQuery{Metric: tc.metric}, tc.direction); err != nil {
+			t.Fatalf("Leaderboard(%q, %q): %v", tc.metric, tc.direction, err)
+		}
+		if q := fake.Matching("FROM run_metrics rm")[0].Query; !strings.Contains(q, " "+tc.want+", rm.run_id") {
+			t.Errorf("Leaderboard(%q, %q) orders %q, want %s", tc.metric, tc.direction, q, tc.want)
+		}
+	}
+}
+
+func TestLeaderboardValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		q         models.LeaderboardQuery
+		direction string
+		want      string
+	}{
+		{"unknown metric", models.LeaderboardQuery{Metric: "speed"}, "", "metric must be one of"},
+		{"bad direction", models.LeaderboardQuery{}, "up", "direction must be asc or desc"},
+		{"limit too high", models.LeaderboardQuery{Limit: maxLeaderboardLimit + 1}, "", "limit must be between"},
+		{"bad mode", models.LeaderboardQuery{Mode: "sa"}, "", "mode must be baseline or ga"},
+		{"on_time_rate above 1", models.LeaderboardQuery{Min: map[string]float64{"on_time_rate": 1.2}}, "", "between 0 and 1"},
+		{"negative bound", models.LeaderboardQuery{Max: map[string]float64{"failed_jobs": -1}}, "", "failed_jobs bounds must be >= 0"},
+		{"unknown bound", models.LeaderboardQuery{Min: map[string]float64{"speed": 1}}, "", `unknown metric "speed"`},
+		{"min over max", models.LeaderboardQuery{
+			Min: map[string]float64{"on_time_rate": 0.9},
+			Max: map[string]float64{"on_time_rate": 0.8},
+		}, "", "min_on_time_rate must not exceed max_on_time_rate"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			_, err := svc.Leaderboard(context.Background(), tc.q, tc.direction)
+			if !IsValidation(err) || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want a validation error containing %q", err, tc.want)
+			}
+			if got := len(fake.Statements()); got != 0 {
+				t.Errorf("statements = %d, want none for an invalid query", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+// File: internal/services/maintenance.go
+// Purpose: Scheduled maintenance windows that block run creation.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fleet-api-go/internal/models"
+)
+
+const maxMaintenanceReasonLength = 255
+
+// MaintenanceError is returned by run creation during a maintenance window; it
+// matches ErrMaintenanceWindow and carries the window so callers can report its end.
+type MaintenanceError struct {
+	Window models.MaintenanceWindow
+}
+
+func (e *MaintenanceError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrMaintenanceWindow, e.Window.EndsAt.UTC().Format(time.RFC3339))
+}
+
+func (e *MaintenanceError) Unwrap() error {
+	return ErrMaintenanceWindow
+}
+
+// ScheduleMaintenance validates and stores a maintenance window. Windows may start in
+// the past (taking effect immediately) but must end in the future and after they start.
+func (s *RunService) ScheduleMaintenance(ctx context.Context, req models.CreateMaintenanceWindowRequest) (*models.MaintenanceWindow, error) {
+	if req.StartsAt == nil || req.EndsAt == nil {
+		return nil, invalidf("starts_at and ends_at are required")
+	}
+	// TIMESTAMP columns have second precision; truncate so the response matches the row.
+	now := time.Now().UTC().Truncate(time.Second)
+	w := models.MaintenanceWindow{
+		StartsAt:  req.StartsAt.UTC().Truncate(time.Second),
+		EndsAt:    req.EndsAt.UTC().Truncate(time.Second),
+		CreatedAt: now,
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return nil, invalidf("ends_at must be after starts_at")
+	}
+	if !w.EndsAt.After(now) {
+		return nil, invalidf("ends_at must be in the future")
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		if utf8.RuneCountInString(reason) > maxMaintenanceReasonLength {
+			return nil, invalidf("reason must be at most %d characters", maxMaintenanceReasonLength)
+		}
+		w.Reason = &reason
+	}
+	if err := s.store.InsertMaintenanceWindow(ctx, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// MaintenanceStatus returns the window in effect now and the next scheduled one.
+func (s *RunService) MaintenanceStatus(ctx context.Context) (*models.MaintenanceStatusResponse, error) {
+	now := time.Now().UTC()
+	active, err := s.store.GetActiveMaintenanceWindow(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	next, err := s.store.GetNextMaintenanceWindow(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MaintenanceStatusResponse{Active: active, Next: next}, nil
+}
+
+// checkMaintenanceWindow returns a *MaintenanceError while a window is active.
+func (s *RunService) checkMaintenanceWindow(ctx context.Context) error {
+	active, err := s.store.GetActiveMaintenanceWindow(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if active != nil {
+		return &MaintenanceError{Window: *active}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestScheduleMaintenanceValidates(t *testing.T) {
+	now := time.Now().UTC()
+	hourAgo, inAnHour, inTwoHours := now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+	for _, tc := range []struct {
+		name string
+		req  models.CreateMaintenanceWindowRequest
+		want string
+	}{
+		{"missing end", models.CreateMaintenanceWindowRequest{StartsAt: &inAnHour}, "starts_at and ends_at are required"},
+		{"ends before it starts", models.CreateMaintenanceWindowRequest{StartsAt: &inTwoHours, EndsAt: &inAnHour}, "ends_at must be after starts_at"},
+		{"already over", models.CreateMaintenanceWindowRequest{StartsAt: &hourAgo, EndsAt: &hourAgo}, "ends_at must be after starts_at"},
+		{"ended", models.CreateMaintenanceWindowRequest{StartsAt: ptr(now.Add(-2 * time.Hour)), EndsAt: &hourAgo}, "ends_at must be in the future"},
+		{"long reason", models.CreateMaintenanceWindowRequest{StartsAt: &inAnHour, EndsAt: &inTwoHours, Reason: strings.Repeat("x", 256)}, "reason must be at most 255 characters"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			_, err := svc.ScheduleMaintenance(context.Background(), tc.req)
+			if !IsValidation(err) || err.Error() != tc.want {
+				t.Fatalf("err = %v, want validation error %q", err, tc.want)
+			}
+			if len(fake.Matching("INSERT INTO maintenance_windows")) != 0 {
+				t.Error("invalid window was stored")
+			}
+		})
+	}
+}
+
+func TestScheduleMaintenanceStoresTheWindow(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.Return("INSERT INTO maintenance_windows", dbtest.Result{RowsAffected: 1, LastInsertID: 7})
+	starts := time.Now().Add(-time.Minute).Truncate(time.Second).Add(400 * time.Millisecond)
+	ends := starts.Add(time.Hour)
+
+	w, err := svc.ScheduleMaintenance(context.Background(), models.CreateMaintenanceWindowRequest{
+		StartsAt: &starts, EndsAt: &ends, Reason: "  broker upgrade ",
+	})
+	if err != nil {
+		t.Fatalf("ScheduleMaintenance: %v", err)
+	}
+	if w.ID != 7 || w.Reason == nil || *w.Reason != "broker upgrade" {
+		t.Errorf("window = %+v, want id 7 and a trimmed reason", w)
+	}
+	if !w.StartsAt.Equal(starts.Truncate(time.Second)) || w.StartsAt.Location() != time.UTC {
+		t.Errorf("starts_at = %v, want %v truncated to seconds in UTC", w.StartsAt, starts)
+	}
+}
+
+func TestCreateRunDuringMaintenance(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	now := time.Now().UTC().Truncate(time.Second)
+	ends := now.Add(30 * time.Minute)
+	fake.Return("WHERE starts_at <= ? AND ends_at > ?", dbtest.Result{Rows: [][]any{{int64(3), now.Add(-time.Minute), ends, "upgrade", now}}})
+
+	_, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"})
+	var maint *MaintenanceError
+	if !errors.As(err, &maint) || !errors.Is(err, ErrMaintenanceWindow) {
+		t.Fatalf("err = %v, want a *MaintenanceError", err)
+	}
+	if maint.Window.ID != 3 || !maint.Window.EndsAt.Equal(ends) {
+		t.Errorf("window = %+v, want id 3 ending %v", maint.Window, ends)
+	}
+	if len(fake.Matching("INSERT INTO runs")) != 0 || len(pub.published("run.created")) != 0 {
+		t.Error("run was created during maintenance")
+	}
+}
+
+func TestMaintenanceStatus(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Now().UTC().Truncate(time.Second)
+	fake.Return("WHERE starts_at > ?", dbtest.Result{Rows: [][]any{{int64(4), now.Add(time.Hour), now.Add(2 * time.Hour), nil, now}}})
+
+	resp, err := svc.MaintenanceStatus(context.Background())
+	if err != nil {
+		t.Fatalf("MaintenanceStatus: %v", err)
+	}
+	if resp.Active != nil || resp.Next == nil || resp.Next.ID != 4 || resp.Next.Reason != nil {
+		t.Errorf("status = %+v, want no active window and window 4 next", resp)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,84 @@
+package services
+
+// File: internal/services/matrix.go
+// Purpose: Pairwise comparison of every planner mode for one scenario.
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db"
+	"fleet-api-go/internal/models"
+)
+
+// matrixModes lists the planner modes compared by ScenarioMatrix, in row/column order.
+// New planners are added here once runs can be created with them.
+var matrixModes = []string{"baseline", "ga"}
+
+// ScenarioMatrix returns each mode's latest completed metrics for a scenario and an
+// NxN grid where Deltas[i][j] is mode j minus mode i. Cells involving a mode with
+// no completed run (and the diagonal) are nil.
+func (s *RunService) ScenarioMatrix(ctx context.Context, seed int, scale string, robots, jobs *int) (*models.ScenarioMatrixResponse, error) {
+	scale, err := canonicalScale(scale)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(s.cfg.CreateOnlyScales, scale) {
+		return nil, fmt.Errorf("%w: %s is creation-only and cannot be compared", ErrScaleNotAllowed, scale)
+	}
+	if (robots == nil) != (jobs == nil) {
+		return nil, invalidf("robots and jobs filters must be provided together")
+	}
+
+	latest := make([]*models.RunMetrics, len(matrixModes))
+	err = s.store.WithTx(ctx, compareTxOptions, func(tx *db.Store) error {
+		for i, mode := range matrixModes {
+			m, err := tx.GetLatestRunMetricsByMode(ctx, seed, scale, mode, config.ScenarioHashVersion, robots, jobs)
+			if err != nil {
+				return err
+			}
+			latest[i] = m
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fleetRobots, fleetJobs, fleetSource := resolveFleetSize(scale, robots, jobs)
+	return &models.ScenarioMatrixResponse{
+		Seed:    seed,
+		Scale:   scale,
+		Robots:  robots,
+		Jobs:    jobs,
+		Modes:   matrixModes,
+		Metrics: matrixMetrics(matrixModes, latest),
+		Deltas:  buildMatrix(latest, fleetRobots, fleetJobs, fleetSource),
+	}, nil
+}
+
+func matrixMetrics(modes []string, latest []*models.RunMetrics) map[string]*models.RunMetrics {
+	out := make(map[string]*models.RunMetrics, len(modes))
+	for i, mode := range modes {
+		out[mode] = latest[i]
+	}
+	return out
+}
+
+// buildMatrix fills the NxN delta grid; buildCompareDelta leaves cells nil when
+// either side is missing.
+func buildMatrix(latest []*models.RunMetrics, robots, jobs int, source string) [][]*models.CompareDelta {
+	grid := make([][]*models.CompareDelta, len(latest))
+	for i := range latest {
+		grid[i] = make([]*models.CompareDelta, len(latest))
+		for j := range latest {
+			if i == j {
+				continue
+			}
+			grid[i][j] = buildCompareDelta(latest[i], latest[j], robots, jobs, source)
+		}
+	}
+	return grid
+}
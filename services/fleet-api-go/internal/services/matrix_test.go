@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScenarioMatrixThreeModes(t *testing.T) {
+	modes := matrixModes
+	matrixModes = []string{"baseline", "ga", "sa"}
+	t.Cleanup(func() { matrixModes = modes })
+
+	svc, fake, _ := newTestService(t, testConfig(t))
+	// sa has no completed run for the scenario.
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, nil)
+
+	resp, err := svc.ScenarioMatrix(context.Background(), 42, "demo", nil, nil)
+	if err != nil {
+		t.Fatalf("ScenarioMatrix: %v", err)
+	}
+	if len(resp.Modes) != 3 || len(resp.Deltas) != 3 {
+		t.Fatalf("modes = %v, deltas = %d rows, want 3x3", resp.Modes, len(resp.Deltas))
+	}
+	if resp.Metrics["baseline"] == nil || resp.Metrics["ga"] == nil || resp.Metrics["sa"] != nil {
+		t.Errorf("metrics = %v, want baseline and ga only", resp.Metrics)
+	}
+	for i, row := range resp.Deltas {
+		if len(row) != 3 {
+			t.Fatalf("row %d has %d cells, want 3", i, len(row))
+		}
+		if row[i] != nil {
+			t.Errorf("diagonal cell [%d][%d] = %+v, want nil", i, i, row[i])
+		}
+		if i != 2 && (row[2] != nil || resp.Deltas[2][i] != nil) {
+			t.Errorf("cells pairing %s with sa are not nil", resp.Modes[i])
+		}
+	}
+
+	gaOverBaseline, baselineOverGA := resp.Deltas[0][1], resp.Deltas[1][0]
+	if gaOverBaseline == nil || !approx(gaOverBaseline.OnTimeRate, 0.1) {
+		t.Errorf("deltas[baseline][ga] = %+v, want on_time_rate 0.1", gaOverBaseline)
+	}
+	if baselineOverGA == nil || !approx(baselineOverGA.OnTimeRate, -0.1) {
+		t.Errorf("deltas[ga][baseline] = %+v, want on_time_rate -0.1", baselineOverGA)
+	}
+}
+
+func TestScenarioMatrixValidation(t *testing.T) {
+	svc, _, _ := newTestService(t, testConfig(t))
+	robots := 4
+	if _, err := svc.ScenarioMatrix(context.Background(), 42, "demo", &robots, nil); !IsValidation(err) {
+		t.Errorf("robots without jobs: err = %v, want a validation error", err)
+	}
+	if _, err := svc.ScenarioMatrix(context.Background(), 42, "huge", nil, nil); !IsValidation(err) {
+		t.Errorf("unknown scale: err = %v, want a validation error", err)
+	}
+}
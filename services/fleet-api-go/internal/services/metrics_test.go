@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestGetMetricsByIDsSpansChunks(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MetricsChunkSize = 2
+	svc, fake, _ := newTestService(t, cfg)
+	computed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.On("WHERE rm.run_id IN", func(args []any) dbtest.Result {
+		var rows [][]any
+		for _, id := range args {
+			if id != "r3" {
+				rows = append(rows, []any{id, 0.9, 100.0, 30.0, 5.0, 9, 1, 10, computed, "completed"})
+			}
+		}
+		return dbtest.Result{Rows: rows}
+	})
+
+	resp, err := svc.GetMetricsByIDs(context.Background(), []string{"r1", "r2", "r3", "r4", "r5", "r3"})
+	if err != nil {
+		t.Fatalf("GetMetricsByIDs: %v", err)
+	}
+	if got := len(fake.Matching("WHERE rm.run_id IN")); got != 3 {
+		t.Errorf("selects = %d, want 3 chunks of at most 2", got)
+	}
+	var ids []string
+	for _, m := range resp.Metrics {
+		ids = append(ids, m.RunID)
+	}
+	if !slices.Equal(ids, []string{"r1", "r2", "r4", "r5"}) || !slices.Equal(resp.Missing, []string{"r3"}) {
+		t.Errorf("metrics = %v, missing = %v", ids, resp.Missing)
+	}
+}
+
+func TestGetMetricsByIDsLimitsDistinctIDs(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	ids := make([]string, 0, maxBulkMetricsIDs+1)
+	for i := range maxBulkMetricsIDs + 1 {
+		ids = append(ids, fmt.Sprintf("r%d", i))
+	}
+	if _, err := svc.GetMetricsByIDs(context.Background(), ids); !IsValidation(err) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	// Duplicates do not count towards the limit.
+	dup := append(ids[:maxBulkMetricsIDs:maxBulkMetricsIDs], ids[0])
+	if _, err := svc.GetMetricsByIDs(context.Background(), dup); err != nil {
+		t.Fatalf("GetMetricsByIDs at the limit: %v", err)
+	}
+	if _, err := svc.GetMetricsByIDs(context.Background(), nil); !IsValidation(err) {
+		t.Errorf("no ids: err = %v, want a validation error", err)
+	}
+	if len(fake.Matching("WHERE rm.run_id IN")) == 0 {
+		t.Error("no select for the request at the limit")
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+// File: internal/services/normalize.go
+// Purpose: A run's metrics scaled by its job count, for comparing runs of different sizes.
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/models"
+)
+
+// NormalizedMetrics returns runID's metrics relative to the run's job count (see
+// normalizationJobs). It returns ErrRunNotFound for an unknown run and
+// ErrMetricsNotFound before metrics exist.
+func (s *RunService) NormalizedMetrics(ctx context.Context, runID string) (*models.NormalizedRunMetrics, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	metrics, err := s.store.GetRunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if metrics == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMetricsNotFound, runID)
+	}
+	jobs, source := normalizationJobs(run, metrics)
+	return normalizeMetrics(metrics, jobs, source), nil
+}
+
+// normalizationJobs picks the job count a run's metrics are divided by: the run's
+// stored jobs override, else the total_jobs the simulator reported, else the
+// scale preset. The source is "override", "metrics" or "preset".
+func normalizationJobs(run *models.Run, m *models.RunMetrics) (int, string) {
+	switch {
+	case run.JobsCount != nil:
+		return *run.JobsCount, "override"
+	case m.TotalJobs > 0:
+		return m.TotalJobs, "metrics"
+	}
+	return config.ScaleMap[run.Scale].Jobs, "preset"
+}
+
+// normalizeMetrics divides the count-like metrics (distance, completed and failed
+// jobs) by jobs; with no jobs those are null rather than a division by zero. Rates
+// and per-job averages are already size-independent and are copied unchanged.
+func normalizeMetrics(m *models.RunMetrics, jobs int, source string) *models.NormalizedRunMetrics {
+	out := &models.NormalizedRunMetrics{
+		RunID:             m.RunID,
+		Jobs:              jobs,
+		FleetSource:       source,
+		OnTimeRate:        m.OnTimeRate,
+		AvgCompletionTime: m.AvgCompletionTime,
+		MaxLateness:       m.MaxLateness,
+	}
+	if jobs <= 0 {
+		return out
+	}
+	n := float64(jobs)
+	distance := m.TotalDistance / n
+	completed := float64(m.CompletedJobs) / n
+	failed := float64(m.FailedJobs) / n
+	out.DistancePerJob = &distance
+	out.CompletedPerJob = &completed
+	out.FailedPerJob = &failed
+	return out
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestNormalizeMetricsDividesCountsByJobs(t *testing.T) {
+	m := &models.RunMetrics{
+		RunID: "run-1", OnTimeRate: 0.9, TotalDistance: 500, AvgCompletionTime: 41.5,
+		MaxLateness: 12, CompletedJobs: 45, FailedJobs: 5, TotalJobs: 50,
+	}
+	got := normalizeMetrics(m, 50, "metrics")
+	if got.Jobs != 50 || got.FleetSource != "metrics" {
+		t.Errorf("jobs = %d (%s), want 50 (metrics)", got.Jobs, got.FleetSource)
+	}
+	for name, tc := range map[string]struct {
+		got  *float64
+		want float64
+	}{
+		"distance_per_job":  {got.DistancePerJob, 10},
+		"completed_per_job": {got.CompletedPerJob, 0.9},
+		"failed_per_job":    {got.FailedPerJob, 0.1},
+	} {
+		if tc.got == nil || *tc.got != tc.want {
+			t.Errorf("%s = %v, want %v", name, tc.got, tc.want)
+		}
+	}
+	if got.OnTimeRate != 0.9 || got.AvgCompletionTime != 41.5 || got.MaxLateness != 12 {
+		t.Errorf("size-independent metrics changed: %+v", got)
+	}
+}
+
+func TestNormalizeMetricsZeroJobsIsNull(t *testing.T) {
+	got := normalizeMetrics(&models.RunMetrics{TotalDistance: 10, CompletedJobs: 1}, 0, "preset")
+	if got.DistancePerJob != nil || got.CompletedPerJob != nil || got.FailedPerJob != nil {
+		t.Errorf("per-job values = %v/%v/%v, want null", got.DistancePerJob, got.CompletedPerJob, got.FailedPerJob)
+	}
+}
+
+func TestNormalizationJobsPrefersStoredCounts(t *testing.T) {
+	testConfig(t) // loads the scale presets
+	jobs := 30
+	for _, tc := range []struct {
+		name       string
+		run        models.Run
+		totalJobs  int
+		wantJobs   int
+		wantSource string
+	}{
+		{"jobs override", models.Run{Scale: "demo", JobsCount: &jobs}, 40, 30, "override"},
+		{"reported total", models.Run{Scale: "demo"}, 40, 40, "metrics"},
+		{"preset", models.Run{Scale: "demo"}, 0, 50, "preset"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, source := normalizationJobs(&tc.run, &models.RunMetrics{TotalJobs: tc.totalJobs})
+			if got != tc.wantJobs || source != tc.wantSource {
+				t.Errorf("jobs = %d (%s), want %d (%s)", got, source, tc.wantJobs, tc.wantSource)
+			}
+		})
+	}
+}
+
+func TestNormalizedMetricsUsesReportedTotalJobs(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.8, 200.0, 30.0, 5.0, 16, 4, 20, now, "completed"},
+	}})
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+	}})
+
+	got, err := svc.NormalizedMetrics(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("NormalizedMetrics: %v", err)
+	}
+	if got.Jobs != 20 || got.FleetSource != "metrics" {
+		t.Fatalf("jobs = %d (%s), want 20 (metrics) rather than the demo preset", got.Jobs, got.FleetSource)
+	}
+	if got.DistancePerJob == nil || *got.DistancePerJob != 10 {
+		t.Errorf("distance_per_job = %v, want 10", got.DistancePerJob)
+	}
+}
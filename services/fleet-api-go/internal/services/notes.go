@@ -0,0 +1,77 @@
+package services
+
+// File: internal/services/notes.go
+// Purpose: Free-text notes attached to runs after creation.
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fleet-api-go/internal/models"
+)
+
+const (
+	maxNoteTextLength   = 2000
+	maxNoteAuthorLength = 64
+)
+
+// AddRunNote validates and stores a note for an existing run.
+func (s *RunService) AddRunNote(ctx context.Context, runID string, req models.CreateRunNoteRequest) (*models.RunNote, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, invalidf("text is required")
+	}
+	if utf8.RuneCountInString(text) > maxNoteTextLength {
+		return nil, invalidf("text must be at most %d characters", maxNoteTextLength)
+	}
+	author := strings.TrimSpace(req.Author)
+	if author == "" {
+		author = "anonymous"
+	}
+	if utf8.RuneCountInString(author) > maxNoteAuthorLength {
+		return nil, invalidf("author must be at most %d characters", maxNoteAuthorLength)
+	}
+
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+
+	note := &models.RunNote{
+		RunID:     runID,
+		Author:    author,
+		Text:      text,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := s.store.InsertRunNote(ctx, note); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// ListRunNotes returns a page of notes for an existing run, oldest first.
+func (s *RunService) ListRunNotes(ctx context.Context, runID string, limit, offset int) (*models.RunNoteListResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	notes, total, err := s.store.ListRunNotes(ctx, runID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RunNoteListResponse{
+		RunID:  runID,
+		Notes:  notes,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// noteRun scripts the run row the note calls check first.
+func noteRun(fake *dbtest.Fake) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+	}})
+}
+
+func TestAddRunNoteValidates(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		req     models.CreateRunNoteRequest
+		wantErr string
+	}{
+		{"empty text", models.CreateRunNoteRequest{Text: "  "}, "text is required"},
+		{"text too long", models.CreateRunNoteRequest{Text: strings.Repeat("x", maxNoteTextLength+1)}, "text must be at most 2000 characters"},
+		// Length counts characters, so 2000 multibyte runes are still accepted.
+		{"multibyte text at the limit", models.CreateRunNoteRequest{Text: strings.Repeat("é", maxNoteTextLength)}, ""},
+		{"author too long", models.CreateRunNoteRequest{Text: "ok", Author: strings.Repeat("a", maxNoteAuthorLength+1)}, "author must be at most 64 characters"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			noteRun(fake)
+			_, err := svc.AddRunNote(context.Background(), "run-1", tc.req)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("AddRunNote: %v", err)
+				}
+				return
+			}
+			if !IsValidation(err) || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want validation error %q", err, tc.wantErr)
+			}
+			if n := len(fake.Matching("INSERT INTO run_notes")); n != 0 {
+				t.Errorf("inserted %d notes for an invalid request", n)
+			}
+		})
+	}
+}
+
+func TestRunNotesWriteReadCycle(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	noteRun(fake)
+	var stored [][]any
+	fake.On("INSERT INTO run_notes", func(args []any) dbtest.Result {
+		stored = append(stored, append([]any{int64(len(stored) + 1)}, args...))
+		return dbtest.Result{RowsAffected: 1, LastInsertID: int64(len(stored))}
+	})
+	fake.On("SELECT COUNT(*) FROM run_notes", func([]any) dbtest.Result {
+		return dbtest.Result{Rows: [][]any{{len(stored)}}}
+	})
+	fake.On("FROM run_notes", func(args []any) dbtest.Result {
+		limit, offset := int(args[1].(int64)), int(args[2].(int64))
+		end := min(offset+limit, len(stored))
+		return dbtest.Result{Rows: stored[min(offset, end):end]}
+	})
+
+	ctx := context.Background()
+	first, err := svc.AddRunNote(ctx, "run-1", models.CreateRunNoteRequest{Author: " alice ", Text: " bad run, sensor glitch "})
+	if err != nil {
+		t.Fatalf("AddRunNote: %v", err)
+	}
+	if first.ID != 1 || first.Author != "alice" || first.Text != "bad run, sensor glitch" || first.CreatedAt.IsZero() {
+		t.Errorf("note = %+v, want ID 1 with trimmed author and text", first)
+	}
+	second, err := svc.AddRunNote(ctx, "run-1", models.CreateRunNoteRequest{Text: "rerun scheduled"})
+	if err != nil {
+		t.Fatalf("AddRunNote: %v", err)
+	}
+	if second.ID != 2 || second.Author != "anonymous" {
+		t.Errorf("note = %+v, want ID 2 by anonymous", second)
+	}
+
+	page, err := svc.ListRunNotes(ctx, "run-1", 1, 1)
+	if err != nil {
+		t.Fatalf("ListRunNotes: %v", err)
+	}
+	if page.Total != 2 || page.Limit != 1 || page.Offset != 1 || len(page.Notes) != 1 {
+		t.Fatalf("page = %+v, want the second of 2 notes", page)
+	}
+	if got := page.Notes[0]; got.ID != 2 || got.Text != "rerun scheduled" || got.RunID != "run-1" {
+		t.Errorf("note = %+v, want the second note back", got)
+	}
+}
+
+func TestRunNotesUnknownRun(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	ctx := context.Background()
+	if _, err := svc.AddRunNote(ctx, "missing", models.CreateRunNoteRequest{Text: "hi"}); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("AddRunNote err = %v, want ErrRunNotFound", err)
+	}
+	if _, err := svc.ListRunNotes(ctx, "missing", 50, 0); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("ListRunNotes err = %v, want ErrRunNotFound", err)
+	}
+	if n := len(fake.Matching("run_notes")); n != 0 {
+		t.Errorf("touched run_notes %d times for an unknown run", n)
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+// File: internal/services/progress.go
+// Purpose: Simulator-reported run progress (PATCH /runs/{id}/progress).
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// UpdateRunProgress records a started run's progress percentage (0-100) and returns
+// its lightweight status. Terminal runs are rejected with ErrRunTerminal.
+func (s *RunService) UpdateRunProgress(ctx context.Context, runID string, req models.RunProgressUpdate) (*models.RunStatusResponse, error) {
+	if req.ProgressPct == nil {
+		return nil, invalidf("progress_pct is required")
+	}
+	pct := *req.ProgressPct
+	if pct < 0 || pct > 100 {
+		return nil, invalidf("progress_pct must be between 0 and 100")
+	}
+	updated, err := s.store.UpdateRunProgress(ctx, runID, pct)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.store.GetRunStatus(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return nil, ErrRunNotFound
+	}
+	if !updated {
+		return nil, fmt.Errorf("%w: %s", ErrRunTerminal, st.Status)
+	}
+	return st, nil
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestUpdateRunProgress(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.Return("SELECT id, status, error_message, progress_pct FROM runs", dbtest.Result{Rows: [][]any{{"run-1", "started", nil, 42.5}}})
+
+	st, err := svc.UpdateRunProgress(context.Background(), "run-1", models.RunProgressUpdate{ProgressPct: ptr(42.5)})
+	if err != nil {
+		t.Fatalf("UpdateRunProgress: %v", err)
+	}
+	if st.Status != "started" || st.ProgressPct == nil || *st.ProgressPct != 42.5 {
+		t.Errorf("status = %+v, want started at 42.5%%", st)
+	}
+	updates := fake.Matching("UPDATE runs SET progress_pct")
+	if len(updates) != 1 || updates[0].Args[0] != 42.5 || updates[0].Args[1] != "run-1" {
+		t.Errorf("progress updates = %+v", updates)
+	}
+}
+
+func TestUpdateRunProgressRange(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pct  *float64
+		want string // "" for accepted
+	}{
+		{"missing", nil, "progress_pct is required"},
+		{"negative", ptr(-0.1), "progress_pct must be between 0 and 100"},
+		{"over 100", ptr(100.5), "progress_pct must be between 0 and 100"},
+		{"zero", ptr(0.0), ""},
+		{"hundred", ptr(100.0), ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			fake.Return("SELECT id, status, error_message, progress_pct FROM runs", dbtest.Result{Rows: [][]any{{"run-1", "started", nil, nil}}})
+
+			_, err := svc.UpdateRunProgress(context.Background(), "run-1", models.RunProgressUpdate{ProgressPct: tc.pct})
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("err = %v, want the update accepted", err)
+				}
+				return
+			}
+			if !IsValidation(err) || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want a validation error containing %q", err, tc.want)
+			}
+			if got := len(fake.Matching("UPDATE runs SET progress_pct")); got != 0 {
+				t.Errorf("progress updates = %d, want none for an invalid value", got)
+			}
+		})
+	}
+}
+
+func TestUpdateRunProgressNotStarted(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.Return("UPDATE runs SET progress_pct", dbtest.Result{RowsAffected: 0})
+	fake.On("SELECT id, status, error_message, progress_pct FROM runs", func(args []any) dbtest.Result {
+		if args[0] == "missing" {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{args[0], "completed", nil, 100.0}}}
+	})
+
+	_, err := svc.UpdateRunProgress(context.Background(), "run-1", models.RunProgressUpdate{ProgressPct: ptr(50.0)})
+	if !errors.Is(err, ErrRunTerminal) || !strings.Contains(err.Error(), "completed") {
+		t.Errorf("finished run: err = %v, want ErrRunTerminal naming the status", err)
+	}
+	if _, err := svc.UpdateRunProgress(context.Background(), "missing", models.RunProgressUpdate{ProgressPct: ptr(50.0)}); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("missing run: err = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestBulkUpdateStatusCompletesProgress(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started"}))
+	if _, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{{ID: "run-1", Status: "completed"}}); err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	update := fake.Matching("UPDATE runs SET status")[0]
+	if !strings.Contains(update.Query, "progress_pct = IF(? = 'completed', 100, progress_pct)") || update.Args[3] != "completed" {
+		t.Errorf("status update %q %v, want progress forced to 100 on completion", update.Query, update.Args)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+// File: internal/services/repro.go
+// Purpose: Reproduction recipe for a stored run (request body + curl command).
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// Repro describes how to re-create runID: a POST /runs body with the stored seed,
+// scale, mode and ga_params, and the fleet size pinned to the resolved robots/jobs so
+// the copy does not drift if scale presets change. The curl URL carries API_BASE_PATH.
+// It returns nil when the run does not exist.
+func (s *RunService) Repro(ctx context.Context, runID string) (*models.RunReproResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil || run == nil {
+		return nil, err
+	}
+	robots, jobs, source := resolveFleetSize(run.Scale, run.RobotsCount, run.JobsCount)
+	seed := run.Seed
+	req := models.CreateRunRequest{
+		Mode:            run.Mode,
+		Seed:            &seed,
+		Scale:           run.Scale,
+		Robots:          &robots,
+		Jobs:            &jobs,
+		GAParams:        run.GAParams,
+		ReplanIntervalS: run.ReplanIntervalS,
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal repro request: %w", err)
+	}
+	return &models.RunReproResponse{
+		RunID:        run.ID,
+		ScenarioHash: run.ScenarioHash,
+		Effective:    models.EffectiveFleet{Robots: robots, Jobs: jobs, Source: source},
+		Request:      req,
+		Command: fmt.Sprintf(
+			`curl -sS -X POST "${FLEET_API_URL:-http://localhost:8000}%s/v1/runs" -H 'Content-Type: application/json' -d '%s'`,
+			s.cfg.APIBasePath, body,
+		),
+	}, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+)
+
+func TestReproCommandUsesBasePath(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		basePath, want string
+	}{
+		{"", `"${FLEET_API_URL:-http://localhost:8000}/v1/runs"`},
+		{"/fleet", `"${FLEET_API_URL:-http://localhost:8000}/fleet/v1/runs"`},
+	} {
+		cfg := testConfig(t)
+		cfg.APIBasePath = tc.basePath
+		svc, fake, _ := newTestService(t, cfg)
+		fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+			{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+		}})
+
+		resp, err := svc.Repro(context.Background(), "run-1")
+		if err != nil {
+			t.Fatalf("Repro: %v", err)
+		}
+		if !strings.Contains(resp.Command, tc.want) {
+			t.Errorf("base path %q: command = %s, want URL %s", tc.basePath, resp.Command, tc.want)
+		}
+	}
+}
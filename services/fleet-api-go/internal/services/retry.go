@@ -0,0 +1,84 @@
+package services
+
+// File: internal/services/retry.go
+// Purpose: Retrying failed runs as new runs linked to the original (retry_of), and lineage lookups.
+
+import (
+	"context"
+	"fmt"
+
+	"fleet-api-go/internal/models"
+)
+
+// RetryRun creates a new run with the original's parameters (mode, seed, scale,
+// robots/jobs overrides, ga_params) and retry_of set to runID, then publishes
+// run.created for it like any other run. Unlike CloneRun nothing can be replaced,
+// and the lineage is recorded. Only failed runs are retried unless force is set.
+func (s *RunService) RetryRun(ctx context.Context, runID string, force bool) (*models.CreateRunResponse, error) {
+	original, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrRunNotFound
+	}
+	if original.Status != "failed" && !force {
+		return nil, fmt.Errorf("%w: status is %s (use force=true to retry anyway)", ErrRunNotFailed, original.Status)
+	}
+	return s.createRun(ctx, "run.retry", models.CreateRunRequest{
+		Mode:     original.Mode,
+		Seed:     &original.Seed,
+		Scale:    original.Scale,
+		Robots:   original.RobotsCount,
+		Jobs:     original.JobsCount,
+		GAParams: original.GAParams,
+		// The replan override travels with the GA settings.
+		ReplanIntervalS: original.ReplanIntervalS,
+		// Retries stay in the failed run's experiment.
+		ExperimentID: original.ExperimentID,
+	}, &original.ID)
+}
+
+// Retries returns the retry chain around runID: its own retries (direct and indirect)
+// and the full lineage from the original run. It returns ErrRunNotFound when the run
+// does not exist.
+func (s *RunService) Retries(ctx context.Context, runID string) (*models.RunRetriesResponse, error) {
+	lineage, err := s.store.GetRunLineage(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lineage) == 0 {
+		return nil, ErrRunNotFound
+	}
+	for i := range lineage {
+		setDuration(&lineage[i])
+	}
+	return &models.RunRetriesResponse{
+		RunID:   runID,
+		RootID:  lineage[0].ID,
+		Retries: descendantRuns(lineage, runID),
+		Lineage: lineage,
+	}, nil
+}
+
+// descendantRuns keeps the runs of lineage that retry runID directly or through
+// another retry, preserving lineage order.
+func descendantRuns(lineage []models.Run, runID string) []models.Run {
+	parent := make(map[string]string, len(lineage))
+	for _, run := range lineage {
+		if run.RetryOf != nil {
+			parent[run.ID] = *run.RetryOf
+		}
+	}
+	out := []models.Run{}
+	for _, run := range lineage {
+		// Walk up from run; the depth bound guards against a retry_of cycle.
+		for id, depth := parent[run.ID], 0; id != "" && depth < len(lineage); id, depth = parent[id], depth+1 {
+			if id == runID {
+				out = append(out, run)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// retryRun answers GetRun with a ga run in status that had robots/jobs overrides and ga_params.
+func retryRun(fake *dbtest.Fake, status string) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 7, "large", 4, 20, "hash", 2, status, nil, now, now, now, `{"generations":30}`, nil, nil, nil, 15},
+	}})
+}
+
+func TestRetryRunCopiesParametersAndLinksOriginal(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	retryRun(fake, "failed")
+
+	resp, err := svc.RetryRun(context.Background(), "run-1", false)
+	if err != nil {
+		t.Fatalf("RetryRun: %v", err)
+	}
+	if resp.RunID == "run-1" || resp.RetryOf == nil || *resp.RetryOf != "run-1" {
+		t.Errorf("run_id = %s, retry_of = %v, want a new run linked to run-1", resp.RunID, resp.RetryOf)
+	}
+	if resp.Mode != "ga" || resp.Seed != 7 || resp.Scale != "large" || *resp.Robots != 4 || *resp.Jobs != 20 {
+		t.Errorf("resp = %+v, want the original's mode, seed, scale and fleet", resp)
+	}
+	if resp.GAParams == nil || *resp.GAParams.Generations != 30 || resp.ReplanIntervalS == nil || *resp.ReplanIntervalS != 15 {
+		t.Errorf("ga_params = %+v, replan = %v, want the original's GA settings", resp.GAParams, resp.ReplanIntervalS)
+	}
+
+	inserts := fake.Matching("INSERT INTO runs")
+	if len(inserts) != 1 || inserts[0].Args[12] != "run-1" {
+		t.Fatalf("inserts = %+v, want one storing retry_of run-1", inserts)
+	}
+	events := pub.published("run.created")
+	if len(events) != 1 || events[0].Payload["retry_of"] != "run-1" {
+		t.Errorf("run.created events = %+v, want one carrying retry_of", events)
+	}
+}
+
+func TestRetryRunRequiresFailedUnlessForced(t *testing.T) {
+	for _, status := range []string{"started", "completed", "cancelled"} {
+		t.Run(status, func(t *testing.T) {
+			svc, fake, pub := newTestService(t, testConfig(t))
+			retryRun(fake, status)
+
+			if _, err := svc.RetryRun(context.Background(), "run-1", false); !errors.Is(err, ErrRunNotFailed) {
+				t.Fatalf("err = %v, want ErrRunNotFailed", err)
+			}
+			if len(fake.Matching("INSERT INTO runs")) != 0 || len(pub.published("run.created")) != 0 {
+				t.Fatal("a refused retry was stored or published")
+			}
+
+			resp, err := svc.RetryRun(context.Background(), "run-1", true)
+			if err != nil {
+				t.Fatalf("forced RetryRun: %v", err)
+			}
+			if resp.RetryOf == nil || *resp.RetryOf != "run-1" {
+				t.Errorf("retry_of = %v, want run-1", resp.RetryOf)
+			}
+		})
+	}
+}
+
+func TestRetryRunNotFound(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.RetryRun(context.Background(), "missing", true); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("err = %v, want ErrRunNotFound", err)
+	}
+	if got := len(fake.Matching("INSERT INTO runs")); got != 0 {
+		t.Errorf("run inserts = %d, want 0", got)
+	}
+}
+
+func TestRetryRunAuditsAsRetry(t *testing.T) {
+	svc, fake := newAuditedService(t)
+	retryRun(fake, "failed")
+	resp, err := svc.RetryRun(context.Background(), "run-1", false)
+	if err != nil {
+		t.Fatalf("RetryRun: %v", err)
+	}
+	entries := auditEntries(fake)
+	if want := (auditEntry{action: "run.retry", runID: resp.RunID, outcome: "ok"}); len(entries) != 1 || entries[0] != want {
+		t.Errorf("audit entries = %+v, want only %+v", entries, want)
+	}
+}
+
+// retryChain answers the lineage walks with run-a <- run-b <- run-c and a second
+// retry of the root, run-d.
+func retryChain(fake *dbtest.Fake) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("WITH RECURSIVE up", dbtest.Result{Rows: [][]any{{"run-a"}}})
+	row := func(id, status string, retryOf any, minute int) []any {
+		at := now.Add(time.Duration(minute) * time.Minute)
+		return []any{id, "ga", 7, "demo", nil, nil, "hash", 2, status, nil, at, at, at.Add(90 * time.Second), nil, retryOf, nil, nil, nil}
+	}
+	fake.Return("WITH RECURSIVE down", dbtest.Result{Rows: [][]any{
+		row("run-a", "failed", nil, 0),
+		row("run-b", "failed", "run-a", 1),
+		row("run-d", "completed", "run-a", 2),
+		row("run-c", "completed", "run-b", 3),
+	}})
+}
+
+func TestRetriesMultiRetryChain(t *testing.T) {
+	for _, tc := range []struct {
+		runID   string
+		retries []string
+	}{
+		{"run-a", []string{"run-b", "run-d", "run-c"}},
+		{"run-b", []string{"run-c"}},
+		{"run-c", nil},
+	} {
+		t.Run(tc.runID, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			retryChain(fake)
+
+			resp, err := svc.Retries(context.Background(), tc.runID)
+			if err != nil {
+				t.Fatalf("Retries: %v", err)
+			}
+			if resp.RunID != tc.runID || resp.RootID != "run-a" || len(resp.Lineage) != 4 {
+				t.Fatalf("resp = %+v, want the whole chain rooted at run-a", resp)
+			}
+			var got []string
+			for _, run := range resp.Retries {
+				got = append(got, run.ID)
+			}
+			if !slices.Equal(got, tc.retries) {
+				t.Errorf("retries = %v, want %v", got, tc.retries)
+			}
+			if d := resp.Lineage[0].DurationSeconds; d == nil || *d != 90 {
+				t.Errorf("lineage duration = %v, want 90", d)
+			}
+		})
+	}
+}
+
+func TestRetriesNotFound(t *testing.T) {
+	svc, _, _ := newTestService(t, testConfig(t))
+	if _, err := svc.Retries(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("err = %v, want ErrRunNotFound", err)
+	}
+}
+
+func TestDescendantRunsToleratesCycles(t *testing.T) {
+	a, b := "run-a", "run-b"
+	// A corrupt pair of rows retrying each other must not loop forever; every run
+	// in the cycle counts as a descendant, itself included.
+	lineage := []models.Run{{ID: "run-a", RetryOf: &b}, {ID: "run-b", RetryOf: &a}, {ID: "run-c", RetryOf: &b}}
+	var got []string
+	for _, run := range descendantRuns(lineage, "run-a") {
+		got = append(got, run.ID)
+	}
+	if !slices.Equal(got, []string{"run-a", "run-b", "run-c"}) {
+		t.Errorf("descendants = %v", got)
+	}
+}
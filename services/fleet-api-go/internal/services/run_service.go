@@ -2,11 +2,19 @@
 package services
 
 // File: internal/services/run_service.go
-// Purpose: Run orchestration (persist + publish run.started).
+// Purpose: Run orchestration (persist + publish run.created).
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
 	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,136 +29,710 @@ import (
 type RunService struct {
 	cfg       *config.Config
 	store     *db.Store
-	publisher *mq.Publisher
+	publisher EventPublisher
+	startedAt time.Time
+	newRunID  idGenerator
+	// runsCreated counts runs persisted by CreateRun since startup. Handlers call
+	// CreateRun concurrently, so it is only touched through sync/atomic.
+	runsCreated atomic.Int64
+	// compareCache holds recent Compare reads; nil when COMPARE_CACHE_SIZE or
+	// COMPARE_CACHE_TTL_MS is 0.
+	compareCache *compareCache
+}
+
+// EventPublisher is the part of *mq.Publisher the service uses, so tests can
+// record events instead of sending them.
+type EventPublisher interface {
+	PublishContext(ctx context.Context, routingKey string, payload map[string]any) error
+	RecordRetry()
+	Stats() mq.Stats
 }
 
 // NewRunService constructs a RunService with dependencies.
-func NewRunService(cfg *config.Config, store *db.Store, publisher *mq.Publisher) *RunService {
-	return &RunService{cfg: cfg, store: store, publisher: publisher}
+func NewRunService(cfg *config.Config, store *db.Store, publisher EventPublisher) *RunService {
+	return &RunService{
+		cfg:          cfg,
+		store:        store,
+		publisher:    publisher,
+		startedAt:    time.Now(),
+		newRunID:     newIDGenerator(cfg.RunIDScheme),
+		compareCache: newCompareCache(cfg.CompareCacheSize, cfg.CompareCacheTTL),
+	}
 }
 
-// CreateRun validates input, persists a run, and publishes run.started.
+// CreateRun validates input, persists a run, and publishes run.created.
 func (s *RunService) CreateRun(ctx context.Context, req models.CreateRunRequest) (*models.CreateRunResponse, error) {
+	return s.createRun(ctx, "run.create", req, nil)
+}
+
+// createRun is CreateRun with an optional retry_of link to the run being retried.
+// The attempt is audited under action, including the new run ID once assigned.
+func (s *RunService) createRun(ctx context.Context, action string, req models.CreateRunRequest, retryOf *string) (_ *models.CreateRunResponse, err error) {
+	var runID string
+	defer func() { s.audit(ctx, action, runID, err) }()
+
+	scale := req.Scale
+	if scale == "" {
+		scale = s.cfg.DefaultScale
+	}
+	scale, err = canonicalScale(scale)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(s.cfg.CompareOnlyScales, scale) {
+		return nil, fmt.Errorf("%w: %s is compare-only and cannot be used to create runs", ErrScaleNotAllowed, scale)
+	}
+
 	mode := req.Mode
 	if mode == "" {
-		mode = s.cfg.DefaultMode
+		mode = s.cfg.ModeForScale(scale)
 	}
 	if mode != "baseline" && mode != "ga" {
-		return nil, fmt.Errorf("mode must be baseline or ga")
+		return nil, invalidf("mode must be baseline or ga")
 	}
-
-	scale := req.Scale
-	if scale == "" {
-		scale = s.cfg.DefaultScale
+	if req.GAParams != nil {
+		if mode != "ga" {
+			return nil, fmt.Errorf("%w (mode is %s)", ErrGAParamsRequireGA, mode)
+		}
+		if err := validateGAParams(req.GAParams); err != nil {
+			return nil, err
+		}
 	}
-	if _, ok := config.ScaleMap[scale]; !ok {
-		return nil, fmt.Errorf("invalid scale: %s", scale)
+	if req.ReplanIntervalS != nil {
+		if mode != "ga" {
+			return nil, fmt.Errorf("%w (mode is %s)", ErrReplanIntervalRequiresGA, mode)
+		}
+		if *req.ReplanIntervalS < minReplanIntervalS {
+			return nil, invalidf("replan_interval_s must be at least %d seconds", minReplanIntervalS)
+		}
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
+	if req.Seed != nil && req.RandomSeed {
+		return nil, invalidf("seed and random_seed are mutually exclusive")
+	}
 	seed := s.cfg.DefaultSeed
-	if req.Seed != nil {
+	switch {
+	case req.Seed != nil:
 		seed = *req.Seed
+	case req.RandomSeed:
+		seed, err = randomSeed()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if (req.Robots == nil) != (req.Jobs == nil) {
-		return nil, fmt.Errorf("robots and jobs overrides must be provided together")
+		return nil, invalidf("robots and jobs overrides must be provided together")
 	}
 	if req.Robots != nil && *req.Robots <= 0 {
-		return nil, fmt.Errorf("robots must be > 0")
+		return nil, invalidf("robots must be > 0")
 	}
 	if req.Jobs != nil && *req.Jobs <= 0 {
-		return nil, fmt.Errorf("jobs must be > 0")
+		return nil, invalidf("jobs must be > 0")
+	}
+	strict := s.cfg.StrictFleet
+	if req.StrictFleet != nil {
+		strict = *req.StrictFleet
+	}
+	robots, jobs, source := resolveFleetSize(scale, req.Robots, req.Jobs)
+	if strict && robots > jobs {
+		return nil, fmt.Errorf("%w: %d robots for %d jobs (%s)", ErrFleetTooManyRobots, robots, jobs, source)
 	}
 
-	runID := uuid.NewString()
-	run := models.Run{
-		ID:           runID,
-		Mode:         mode,
-		Seed:         seed,
-		Scale:        scale,
-		RobotsCount:  req.Robots,
-		JobsCount:    req.Jobs,
-		ScenarioHash: "pending",
-		Status:       "started",
+	if req.ExperimentID != nil {
+		exp, err := s.store.GetExperiment(ctx, *req.ExperimentID)
+		if err != nil {
+			return nil, err
+		}
+		if exp == nil {
+			return nil, fmt.Errorf("%w: %s", ErrExperimentNotFound, *req.ExperimentID)
+		}
 	}
-	if err := s.store.CreateRun(ctx, run); err != nil {
+
+	if err := s.checkMaintenanceWindow(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.checkActiveRunLimit(ctx); err != nil {
 		return nil, err
 	}
 
-	event := map[string]any{
-		"event_id":   uuid.NewString(),
-		"event_type": "run.started",
-		"run_id":     runID,
-		"mode":       mode,
-		"seed":       seed,
-		"scale":      scale,
-		"sim_time_s": 0,
+	runID, err = s.newRunID()
+	if err != nil {
+		return nil, err
 	}
-	if req.Robots != nil && req.Jobs != nil {
-		event["robots"] = *req.Robots
-		event["jobs"] = *req.Jobs
+	// TIMESTAMP columns have second precision; truncate so the response matches the row.
+	now := time.Now().UTC().Truncate(time.Second)
+	run := models.Run{
+		ID:                  runID,
+		Mode:                mode,
+		Seed:                seed,
+		Scale:               scale,
+		RobotsCount:         req.Robots,
+		JobsCount:           req.Jobs,
+		ScenarioHash:        "pending",
+		ScenarioHashVersion: config.ScenarioHashVersion,
+		Status:              "started",
+		CreatedAt:           now,
+		StartedAt:           &now,
+		GAParams:            req.GAParams,
+		RetryOf:             retryOf,
+		ExperimentID:        req.ExperimentID,
+		ReplanIntervalS:     req.ReplanIntervalS,
+		Tags:                tags,
 	}
-	if err := s.publisher.Publish("run.started", event); err != nil {
-		return nil, fmt.Errorf("publish run.started: %w", err)
+	if err := s.insertRun(ctx, run); err != nil {
+		return nil, err
+	}
+	s.runsCreated.Add(1)
+
+	if err := s.publishRunCreated(ctx, run); err != nil {
+		return nil, err
 	}
 
 	return &models.CreateRunResponse{
-		RunID:  runID,
-		Mode:   mode,
-		Seed:   seed,
-		Scale:  scale,
-		Robots: req.Robots,
-		Jobs:   req.Jobs,
-		Status: "started",
+		RunID:           runID,
+		Mode:            mode,
+		Seed:            seed,
+		Scale:           scale,
+		Robots:          req.Robots,
+		Jobs:            req.Jobs,
+		Status:          "started",
+		CreatedAt:       now,
+		GAParams:        req.GAParams,
+		RetryOf:         retryOf,
+		ExperimentID:    req.ExperimentID,
+		ReplanIntervalS: req.ReplanIntervalS,
+		Tags:            tags,
+		Effective:       models.EffectiveFleet{Robots: robots, Jobs: jobs, Source: source},
 	}, nil
 }
 
+// insertRun stores run, together with its tags in one transaction when it has any.
+func (s *RunService) insertRun(ctx context.Context, run models.Run) error {
+	if len(run.Tags) == 0 {
+		return s.store.CreateRun(ctx, run)
+	}
+	return s.store.WithTx(ctx, nil, func(tx *db.Store) error {
+		if err := tx.CreateRun(ctx, run); err != nil {
+			return err
+		}
+		return tx.InsertRunTags(ctx, run.ID, run.Tags)
+	})
+}
+
+// checkActiveRunLimit enforces MAX_ACTIVE_RUNS (0 = unlimited). The check is not
+// atomic with the insert, so concurrent creates may briefly overshoot the limit.
+func (s *RunService) checkActiveRunLimit(ctx context.Context) error {
+	if s.cfg.MaxActiveRuns <= 0 {
+		return nil
+	}
+	active, err := s.store.CountActiveRuns(ctx)
+	if err != nil {
+		return err
+	}
+	if active >= s.cfg.MaxActiveRuns {
+		return fmt.Errorf("%w: %d active (max %d)", ErrTooManyActiveRuns, active, s.cfg.MaxActiveRuns)
+	}
+	return nil
+}
+
+// CloneRun creates a fresh run with the parameters of runID (scale, seed, mode,
+// robots/jobs overrides, experiment and, for ga clones, ga_params), optionally replacing mode or seed, and publishes run.created for it.
+func (s *RunService) CloneRun(ctx context.Context, runID string, req models.CloneRunRequest) (*models.CreateRunResponse, error) {
+	original, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrRunNotFound
+	}
+	create := models.CreateRunRequest{
+		Mode:   original.Mode,
+		Seed:   &original.Seed,
+		Scale:  original.Scale,
+		Robots: original.RobotsCount,
+		Jobs:   original.JobsCount,
+		// Clones stay in the original's experiment.
+		ExperimentID: original.ExperimentID,
+	}
+	if req.Mode != "" {
+		create.Mode = req.Mode
+	}
+	if req.Seed != nil {
+		create.Seed = req.Seed
+	}
+	// GA tuning only carries over while the clone stays a ga run.
+	if create.Mode == "ga" {
+		create.GAParams = original.GAParams
+		create.ReplanIntervalS = original.ReplanIntervalS
+	}
+	return s.createRun(ctx, "run.clone", create, nil)
+}
+
+// RepublishRun re-emits run.created for an existing run using its stored parameters.
+// Terminal runs are rejected unless force is set.
+func (s *RunService) RepublishRun(ctx context.Context, runID string, force bool) (*models.Run, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	if isTerminalStatus(run.Status) && !force {
+		return nil, fmt.Errorf("%w: %s (use force=true to republish)", ErrRunTerminal, run.Status)
+	}
+	if err := s.publishRunCreated(ctx, *run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
 // GetRun fetches run metadata by ID.
 func (s *RunService) GetRun(ctx context.Context, runID string) (*models.Run, error) {
-	return s.store.GetRun(ctx, runID)
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil || run == nil {
+		return run, err
+	}
+	runs := []models.Run{*run}
+	if err := s.store.AttachRunTags(ctx, runs); err != nil {
+		return nil, err
+	}
+	run = &runs[0]
+	setDuration(run)
+	return run, nil
+}
+
+// setDuration fills DurationSeconds for finished runs with a start time. Rows
+// written before completed_at was validated may still end before they started
+// (simulator clock skew); those report 0 rather than a negative duration.
+func setDuration(run *models.Run) {
+	if run.CompletedAt == nil || run.StartedAt == nil {
+		return
+	}
+	d := int64(run.CompletedAt.Sub(*run.StartedAt) / time.Second)
+	d = max(d, 0)
+	run.DurationSeconds = &d
 }
 
-// GetMetrics fetches metrics for a run ID.
-func (s *RunService) GetMetrics(ctx context.Context, runID string) (*models.RunMetrics, error) {
-	return s.store.GetRunMetrics(ctx, runID)
+// GetRunStatus fetches just a run's status and error message.
+func (s *RunService) GetRunStatus(ctx context.Context, runID string) (*models.RunStatusResponse, error) {
+	return s.store.GetRunStatus(ctx, runID)
 }
 
-// Compare fetches the latest completed baseline and GA metrics for a scenario.
-func (s *RunService) Compare(ctx context.Context, seed int, scale string, robots *int, jobs *int) (*models.CompareRunsResponse, error) {
-	if _, ok := config.ScaleMap[scale]; !ok {
-		return nil, fmt.Errorf("invalid scale: %s", scale)
+// GetMetrics fetches metrics for a run ID and evaluates them against thresholds.
+// A non-empty thresholdSpec (same syntax as METRIC_THRESHOLDS) replaces the configured thresholds.
+func (s *RunService) GetMetrics(ctx context.Context, runID, thresholdSpec string) (*models.RunMetrics, error) {
+	thresholds := s.cfg.MetricThresholds
+	if thresholdSpec != "" {
+		parsed, err := config.ParseThresholds(thresholdSpec)
+		if err != nil {
+			return nil, invalidf("%s", err.Error())
+		}
+		thresholds = parsed
+	}
+	metrics, err := s.store.GetRunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	evaluateThresholds(metrics, thresholds)
+	return metrics, nil
+}
+
+// maxBulkMetricsIDs caps the distinct run IDs accepted by GetMetricsByIDs.
+const maxBulkMetricsIDs = 1000
+
+// GetMetricsByIDs returns metrics for several runs at once, applying the
+// configured METRIC_THRESHOLDS to each. IDs without metrics are reported in Missing.
+func (s *RunService) GetMetricsByIDs(ctx context.Context, runIDs []string) (*models.BulkMetricsResponse, error) {
+	if len(runIDs) == 0 {
+		return nil, invalidf("ids is required")
+	}
+	distinct := make(map[string]struct{}, len(runIDs))
+	for _, id := range runIDs {
+		distinct[id] = struct{}{}
+	}
+	if len(distinct) > maxBulkMetricsIDs {
+		return nil, invalidf("too many ids: %d (max %d)", len(distinct), maxBulkMetricsIDs)
+	}
+	metrics, err := s.store.GetRunMetricsByIDs(ctx, runIDs, s.cfg.MetricsChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.BulkMetricsResponse{Metrics: metrics, Missing: []string{}}
+	for i := range resp.Metrics {
+		evaluateThresholds(&resp.Metrics[i], s.cfg.MetricThresholds)
+		delete(distinct, resp.Metrics[i].RunID)
+	}
+	for _, id := range runIDs {
+		if _, ok := distinct[id]; ok {
+			resp.Missing = append(resp.Missing, id)
+			delete(distinct, id)
+		}
+	}
+	return resp, nil
+}
+
+// maxBulkRunIDs caps the distinct run IDs accepted by GetRunsByIDs; it matches
+// the largest GET /runs page.
+const maxBulkRunIDs = 200
+
+// GetRunsByIDs returns the requested runs in request order, listing unknown IDs in NotFound.
+func (s *RunService) GetRunsByIDs(ctx context.Context, runIDs []string) (*models.RunBatchResponse, error) {
+	if len(runIDs) == 0 {
+		return nil, invalidf("ids is required")
+	}
+	distinct := make(map[string]struct{}, len(runIDs))
+	for _, id := range runIDs {
+		distinct[id] = struct{}{}
+	}
+	if len(distinct) > maxBulkRunIDs {
+		return nil, invalidf("too many ids: %d (max %d)", len(distinct), maxBulkRunIDs)
+	}
+	runs, err := s.store.GetRunsByIDs(ctx, runIDs, s.cfg.MetricsChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.AttachRunTags(ctx, runs); err != nil {
+		return nil, err
+	}
+	resp := &models.RunBatchResponse{Runs: runs, NotFound: []string{}}
+	for i := range resp.Runs {
+		setDuration(&resp.Runs[i])
+		delete(distinct, resp.Runs[i].ID)
+	}
+	for _, id := range runIDs {
+		if _, ok := distinct[id]; ok {
+			resp.NotFound = append(resp.NotFound, id)
+			delete(distinct, id)
+		}
+	}
+	return resp, nil
+}
+
+// ListRuns returns a page of runs, newest first, optionally filtered by status and error presence.
+func (s *RunService) ListRuns(ctx context.Context, f models.RunFilter, limit, offset int) (*models.RunListResponse, error) {
+	if f.Status != "" && f.Status != "started" && !isTerminalStatus(f.Status) {
+		return nil, invalidf("invalid status: %s", f.Status)
+	}
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		return nil, err
+	}
+	f.Tags = tags
+	runs, total, err := s.store.ListRuns(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.AttachRunTags(ctx, runs); err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		setDuration(&runs[i])
+	}
+	return &models.RunListResponse{Runs: runs, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// maxBaselineAvgRuns caps the rolling-average window of Compare's baseline=avg.
+const maxBaselineAvgRuns = 50
+
+// compareTxOptions gives Compare a consistent read-only snapshot.
+var compareTxOptions = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// Compare fetches the latest completed baseline and GA metrics for a scenario,
+// only considering runs hashed with the current scenario-hash version. A non-empty
+// weightsSpec (e.g. "on_time:0.5,distance:0.5") adds a weighted score per mode.
+// A mode's metrics are withheld (insufficient_data) until it has minRuns completed
+// runs; minRuns 0 uses COMPARE_MIN_RUNS. baselineAvg > 0 compares against the
+// average of the newest baselineAvg baseline runs (or all of them, if fewer).
+func (s *RunService) Compare(ctx context.Context, seed int, scale string, robots *int, jobs *int, weightsSpec string, minRuns, baselineAvg int) (*models.CompareRunsResponse, error) {
+	scale, err := canonicalScale(scale)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(s.cfg.CreateOnlyScales, scale) {
+		return nil, fmt.Errorf("%w: %s is creation-only and cannot be compared", ErrScaleNotAllowed, scale)
+	}
+	var weights map[string]float64
+	if weightsSpec != "" {
+		if weights, err = parseWeights(weightsSpec); err != nil {
+			return nil, err
+		}
 	}
 	if (robots == nil) != (jobs == nil) {
-		return nil, fmt.Errorf("robots and jobs compare filters must be provided together")
+		return nil, invalidf("robots and jobs compare filters must be provided together")
 	}
 	if robots != nil && *robots <= 0 {
-		return nil, fmt.Errorf("robots must be > 0")
+		return nil, invalidf("robots must be > 0")
 	}
 	if jobs != nil && *jobs <= 0 {
-		return nil, fmt.Errorf("jobs must be > 0")
+		return nil, invalidf("jobs must be > 0")
+	}
+	if minRuns < 0 {
+		return nil, invalidf("min_runs must be >= 1")
+	}
+	if minRuns == 0 {
+		minRuns = s.cfg.CompareMinRuns
+	}
+	if baselineAvg < 0 || baselineAvg > maxBaselineAvgRuns {
+		return nil, invalidf("n must be between 1 and %d", maxBaselineAvgRuns)
+	}
+
+	reads, err := s.compareReads(ctx, seed, scale, robots, jobs, baselineAvg)
+	if err != nil {
+		return nil, err
+	}
+	baseline, ga, averaged, counts := reads.baseline, reads.ga, reads.averaged, reads.counts
+	insufficient := []string{}
+	if baseline != nil && counts["baseline"] < minRuns {
+		baseline = nil
+		insufficient = append(insufficient, "baseline")
+	}
+	if ga != nil && counts["ga"] < minRuns {
+		ga = nil
+		insufficient = append(insufficient, "ga")
+	}
+	fleetRobots, fleetJobs, fleetSource := resolveFleetSize(scale, robots, jobs)
+	resp := &models.CompareRunsResponse{
+		Seed:         seed,
+		Scale:        scale,
+		Robots:       robots,
+		Jobs:         jobs,
+		Baseline:     baseline,
+		GA:           ga,
+		Delta:        buildCompareDelta(baseline, ga, fleetRobots, fleetJobs, fleetSource),
+		MissingModes: missingModes(baseline, ga),
+		MinRuns:      minRuns,
+		RunCounts:    counts,
+	}
+	resp.InsufficientData = insufficient
+	resp.Status, resp.Message = compareStatus(baseline, ga)
+	if len(insufficient) > 0 {
+		resp.Message = fmt.Sprintf("%s has fewer than %d completed runs for this scenario", strings.Join(insufficient, " and "), minRuns)
+	}
+	if weights != nil {
+		resp.Score = scoreCompare(baseline, ga, weights)
+	}
+	if baselineAvg > 0 && baseline != nil {
+		resp.BaselineAvg = &models.CompareBaselineAvg{N: baselineAvg, RunIDs: make([]string, len(averaged))}
+		for i, m := range averaged {
+			resp.BaselineAvg.RunIDs[i] = m.RunID
+		}
 	}
+	return resp, nil
+}
+
+// compareReads loads the metrics and run counts Compare needs, from compareCache
+// when a fresh entry exists.
+func (s *RunService) compareReads(ctx context.Context, seed int, scale string, robots, jobs *int, baselineAvg int) (compareReads, error) {
+	key := newCompareKey(seed, scale, robots, jobs, baselineAvg)
+	if reads, ok := s.compareCache.get(key); ok {
+		return reads, nil
+	}
+	// Read both modes (and their run counts) from one REPEATABLE READ snapshot so a
+	// run completing in between cannot pair metrics from two different points in time.
+	reads := compareReads{counts: map[string]int{}}
+	err := s.store.WithTx(ctx, compareTxOptions, func(tx *db.Store) error {
+		var err error
+		if baselineAvg > 0 {
+			if reads.averaged, err = tx.GetRecentRunMetricsByMode(ctx, seed, scale, "baseline", config.ScenarioHashVersion, robots, jobs, baselineAvg); err != nil {
+				return err
+			}
+			reads.baseline = averageMetrics(reads.averaged)
+		} else if reads.baseline, err = tx.GetLatestRunMetricsByMode(ctx, seed, scale, "baseline", config.ScenarioHashVersion, robots, jobs); err != nil {
+			return err
+		}
+		if reads.ga, err = tx.GetLatestRunMetricsByMode(ctx, seed, scale, "ga", config.ScenarioHashVersion, robots, jobs); err != nil {
+			return err
+		}
+		for _, mode := range []string{"baseline", "ga"} {
+			if reads.counts[mode], err = tx.CountCompletedRunsByMode(ctx, seed, scale, mode, config.ScenarioHashVersion, robots, jobs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return compareReads{}, err
+	}
+	s.compareCache.put(key, reads)
+	return reads, nil
+}
 
-	baseline, err := s.store.GetLatestRunMetricsByMode(ctx, seed, scale, "baseline", robots, jobs)
+// Peer returns the latest completed opposite-mode run for the same scenario as runID.
+// It returns ErrRunNotFound when the run does not exist and ErrPeerNotFound when no peer has completed.
+func (s *RunService) Peer(ctx context.Context, runID string) (*models.RunPeerResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
 	if err != nil {
 		return nil, err
 	}
-	ga, err := s.store.GetLatestRunMetricsByMode(ctx, seed, scale, "ga", robots, jobs)
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	peerMode := "ga"
+	if run.Mode == "ga" {
+		peerMode = "baseline"
+	}
+	peer, err := s.store.GetLatestRunMetricsByMode(ctx, run.Seed, run.Scale, peerMode, run.ScenarioHashVersion, run.RobotsCount, run.JobsCount)
 	if err != nil {
 		return nil, err
 	}
-	return &models.CompareRunsResponse{
-		Seed:     seed,
-		Scale:    scale,
-		Robots:   robots,
-		Jobs:     jobs,
-		Baseline: baseline,
-		GA:       ga,
+	if peer == nil {
+		return nil, ErrPeerNotFound
+	}
+	return &models.RunPeerResponse{
+		RunID:    run.ID,
+		Mode:     run.Mode,
+		PeerMode: peerMode,
+		Peer:     peer,
 	}, nil
 }
 
+// Version reports the API versions served and the current scenario-hash version.
+func (s *RunService) Version() models.VersionResponse {
+	return models.VersionResponse{
+		Service:             s.cfg.ServiceName,
+		APIVersions:         []string{"v1"},
+		ScenarioHashVersion: config.ScenarioHashVersion,
+	}
+}
+
+// RunsCreated returns the number of runs created since startup.
+func (s *RunService) RunsCreated() int64 {
+	return s.runsCreated.Load()
+}
+
+// Status reports process uptime and run counters for GET /status.
+func (s *RunService) Status(ctx context.Context) (*models.StatusResponse, error) {
+	active, err := s.store.CountActiveRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.StatusResponse{
+		Service:     s.cfg.ServiceName,
+		UptimeS:     int(time.Since(s.startedAt).Seconds()),
+		RunsCreated: s.RunsCreated(),
+		ActiveRuns:  active,
+		ReadOnly:    s.cfg.ReadOnly,
+		Publisher:   publisherStats(s.publisher.Stats()),
+	}, nil
+}
+
+// publisherStats maps mq counters onto the API model.
+func publisherStats(st mq.Stats) models.PublisherStats {
+	return models.PublisherStats{Published: st.Published, Failed: st.Failed, Retried: st.Retried, Sampled: st.Sampled}
+}
+
 // Health checks database connectivity.
 func (s *RunService) Health(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 	return s.store.Health(ctx)
 }
+
+// publishWithRetry publishes a run event, retrying transient broker failures up to
+// PUBLISH_RETRY_ATTEMPTS times with doubling backoff. It stops early when ctx ends.
+// Published events are recorded in the run's event history.
+func (s *RunService) publishWithRetry(ctx context.Context, routingKey string, payload map[string]any) error {
+	backoff := s.cfg.PublishBackoff
+	var err error
+	for attempt := 1; attempt <= s.cfg.PublishAttempts; attempt++ {
+		if err = s.publisher.PublishContext(ctx, routingKey, payload); err == nil {
+			s.recordRunEvent(ctx, routingKey, payload)
+			return nil
+		}
+		if ctx.Err() != nil || attempt == s.cfg.PublishAttempts {
+			break
+		}
+		log.Printf("publish %s attempt %d/%d failed, retrying in %s: %v", routingKey, attempt, s.cfg.PublishAttempts, backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		s.publisher.RecordRetry()
+		backoff *= 2
+	}
+	return err
+}
+
+// buildRunCreatedEvent builds the run.created event for a persisted run. GA runs
+// carry their replan interval: the run's override, else defaultReplan.
+func buildRunCreatedEvent(run models.Run, startedAlias bool, defaultReplan int) models.RunCreatedEvent {
+	event := models.RunCreatedEvent{
+		EventID:         uuid.NewString(),
+		EventType:       "run.created",
+		RunID:           run.ID,
+		Mode:            run.Mode,
+		Seed:            run.Seed,
+		Scale:           run.Scale,
+		SimTimeS:        0,
+		RunStartedAlias: &startedAlias,
+		GAParams:        run.GAParams,
+		RetryOf:         run.RetryOf,
+	}
+	if run.RobotsCount != nil && run.JobsCount != nil {
+		robots, jobs := *run.RobotsCount, *run.JobsCount
+		event.Robots = &robots
+		event.Jobs = &jobs
+	}
+	if run.Mode == "ga" {
+		replan := defaultReplan
+		if run.ReplanIntervalS != nil {
+			replan = *run.ReplanIntervalS
+		}
+		event.ReplanIntervalS = &replan
+	}
+	return event
+}
+
+// publishRunCreated publishes run.created and, while PUBLISH_RUN_STARTED_ALIAS is on,
+// the legacy run.started alias with the same fields for consumers not yet migrated.
+func (s *RunService) publishRunCreated(ctx context.Context, run models.Run) error {
+	event := buildRunCreatedEvent(run, s.cfg.RunStartedAlias, s.cfg.GAReplanInterval)
+	if err := s.publishWithRetry(ctx, "run.created", event.Payload()); err != nil {
+		return fmt.Errorf("publish run.created: %w", err)
+	}
+	if !s.cfg.RunStartedAlias {
+		return nil
+	}
+	legacy := event
+	legacy.EventID = uuid.NewString()
+	legacy.EventType = "run.started"
+	legacy.RunStartedAlias = nil
+	if err := s.publishWithRetry(ctx, "run.started", legacy.Payload()); err != nil {
+		return fmt.Errorf("publish run.started alias: %w", err)
+	}
+	return nil
+}
+
+// randomSeed returns a crypto-random non-negative seed that fits the runs.seed INT column.
+func randomSeed() (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt32))
+	if err != nil {
+		return 0, fmt.Errorf("generate random seed: %w", err)
+	}
+	return int(n.Int64()), nil
+}
+
+// canonicalScale lowercases a scale name and verifies it exists in ScaleMap, so
+// "Demo" and "LARGE" resolve to the stored canonical names.
+func canonicalScale(raw string) (string, error) {
+	scale := strings.ToLower(strings.TrimSpace(raw))
+	if _, ok := config.ScaleMap[scale]; !ok {
+		return "", invalidf("invalid scale: %s", raw)
+	}
+	return scale, nil
+}
+
+func isTerminalStatus(status string) bool {
+	switch status {
+	case "completed", "failed", "stopped", "cancelled":
+		return true
+	}
+	return false
+}
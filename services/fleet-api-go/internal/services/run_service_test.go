@@ -0,0 +1,719 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestCreateRunCountsConcurrentCreates(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	const creators = 64
+	ids := make(chan string, creators)
+	var wg sync.WaitGroup
+	for range creators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"})
+			if err != nil {
+				t.Errorf("CreateRun: %v", err)
+				return
+			}
+			ids <- resp.RunID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[string]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("duplicate run id %s", id)
+		}
+		seen[id] = true
+	}
+	if got := svc.RunsCreated(); got != creators {
+		t.Errorf("RunsCreated = %d, want %d", got, creators)
+	}
+	if got := len(fake.Matching("INSERT INTO runs")); got != creators {
+		t.Errorf("run inserts = %d, want %d", got, creators)
+	}
+	if got := len(pub.published("run.created")); got != creators {
+		t.Errorf("run.created events = %d, want %d", got, creators)
+	}
+
+	status, err := svc.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.RunsCreated != creators {
+		t.Errorf("Status.RunsCreated = %d, want %d", status.RunsCreated, creators)
+	}
+}
+
+func TestCreateRunFailedInsertIsNotCounted(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.Return("INSERT INTO runs", dbtest.Result{Err: context.DeadlineExceeded})
+
+	if _, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"}); err == nil {
+		t.Fatal("CreateRun succeeded with a failing insert")
+	}
+	if got := svc.RunsCreated(); got != 0 {
+		t.Errorf("RunsCreated = %d, want 0", got)
+	}
+	if got := len(pub.published("run.created")); got != 0 {
+		t.Errorf("run.created events = %d, want 0", got)
+	}
+}
+
+func TestBuildRunCreatedEvent(t *testing.T) {
+	robots, jobs, replan := 4, 20, 15
+	retryOf := "run-0"
+	generations := 80
+	for _, tc := range []struct {
+		name string
+		run  models.Run
+		want map[string]any
+	}{
+		{
+			"baseline preset",
+			models.Run{ID: "run-1", Mode: "baseline", Seed: 42, Scale: "demo"},
+			map[string]any{"run_id": "run-1", "mode": "baseline", "seed": 42, "scale": "demo", "sim_time_s": 0, "run_started_alias": true},
+		},
+		{
+			"ga with overrides",
+			models.Run{
+				ID: "run-2", Mode: "ga", Seed: 7, Scale: "small", RobotsCount: &robots, JobsCount: &jobs,
+				GAParams: &models.GAParams{Generations: &generations}, RetryOf: &retryOf, ReplanIntervalS: &replan,
+			},
+			map[string]any{
+				"run_id": "run-2", "mode": "ga", "seed": 7, "scale": "small", "sim_time_s": 0, "run_started_alias": true,
+				"robots": 4, "jobs": 20, "ga_params": &models.GAParams{Generations: &generations}, "retry_of": "run-0",
+				"replan_interval_s": 15,
+			},
+		},
+		{
+			"ga default replan",
+			models.Run{ID: "run-3", Mode: "ga", Seed: 1, Scale: "demo"},
+			map[string]any{"run_id": "run-3", "mode": "ga", "seed": 1, "scale": "demo", "sim_time_s": 0, "run_started_alias": true, "replan_interval_s": 30},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event := buildRunCreatedEvent(tc.run, true, 30)
+			payload := event.Payload()
+			if payload["event_type"] != "run.created" || payload["event_id"] == "" {
+				t.Errorf("event_type/event_id = %v/%v", payload["event_type"], payload["event_id"])
+			}
+			delete(payload, "event_type")
+			delete(payload, "event_id")
+			if !reflect.DeepEqual(payload, tc.want) {
+				t.Errorf("payload = %v\nwant      %v", payload, tc.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunCreatedEventCopiesOverrides(t *testing.T) {
+	robots, jobs := 4, 20
+	event := buildRunCreatedEvent(models.Run{ID: "run-1", Mode: "baseline", RobotsCount: &robots, JobsCount: &jobs}, false, 30)
+	robots, jobs = 99, 99
+	if *event.Robots != 4 || *event.Jobs != 20 {
+		t.Errorf("event fleet = %d/%d, want 4/20 after the run changed", *event.Robots, *event.Jobs)
+	}
+}
+
+func TestPublishRunCreatedAlias(t *testing.T) {
+	for _, alias := range []bool{true, false} {
+		cfg := testConfig(t)
+		cfg.RunStartedAlias = alias
+		svc, _, pub := newTestService(t, cfg)
+		if err := svc.publishRunCreated(context.Background(), models.Run{ID: "run-1", Mode: "baseline", Scale: "demo"}); err != nil {
+			t.Fatalf("publishRunCreated: %v", err)
+		}
+		created, started := pub.published("run.created"), pub.published("run.started")
+		if len(created) != 1 || created[0].Payload["run_started_alias"] != alias {
+			t.Fatalf("alias %v: run.created = %+v", alias, created)
+		}
+		if !alias {
+			if len(started) != 0 {
+				t.Errorf("run.started published with the alias off: %+v", started)
+			}
+			continue
+		}
+		if len(started) != 1 {
+			t.Fatalf("run.started = %+v, want one alias", started)
+		}
+		legacy := started[0].Payload
+		if legacy["event_type"] != "run.started" || legacy["event_id"] == created[0].Payload["event_id"] {
+			t.Errorf("alias event_type/event_id = %v/%v", legacy["event_type"], legacy["event_id"])
+		}
+		if _, ok := legacy["run_started_alias"]; ok {
+			t.Error("alias carries run_started_alias")
+		}
+	}
+}
+
+func TestCreateRunActiveRunLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		max, active   int
+		wantRejected  bool
+		wantCountRead bool
+	}{
+		{"unlimited", 0, 100, false, false},
+		{"below the limit", 3, 2, false, true},
+		{"at the limit", 3, 3, true, true},
+		{"over the limit", 3, 5, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.MaxActiveRuns = tc.max
+			svc, fake, pub := newTestService(t, cfg)
+			fake.Return("WHERE status = 'started'", dbtest.Result{Rows: [][]any{{tc.active}}})
+
+			_, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"})
+			if got := errors.Is(err, ErrTooManyActiveRuns); got != tc.wantRejected {
+				t.Fatalf("err = %v, want rejected %v", err, tc.wantRejected)
+			}
+			if !tc.wantRejected && err != nil {
+				t.Fatalf("CreateRun: %v", err)
+			}
+			if got := len(fake.Matching("WHERE status = 'started'")) > 0; got != tc.wantCountRead {
+				t.Errorf("counted active runs = %v, want %v", got, tc.wantCountRead)
+			}
+			inserts := len(fake.Matching("INSERT INTO runs"))
+			if tc.wantRejected && (inserts != 0 || len(pub.published("run.created")) != 0) {
+				t.Errorf("rejected create still inserted (%d) or published", inserts)
+			}
+		})
+	}
+}
+
+// createRunCase is one TestCreateRunValidation row. env is set before the config
+// loads and cfg adjusts it afterwards. wantErr is a sentinel the error must wrap;
+// wantInvalid expects a ValidationError instead. check, when set, inspects a
+// successful response.
+type createRunCase struct {
+	name        string
+	env         map[string]string
+	cfg         func(cfg *config.Config)
+	req         models.CreateRunRequest
+	wantErr     error
+	wantInvalid bool
+	check       func(t *testing.T, resp *models.CreateRunResponse)
+}
+
+func TestCreateRunValidation(t *testing.T) {
+	for _, tc := range []createRunCase{
+		{
+			name:  "mixed-case scale is canonicalized",
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: "Demo"},
+			check: wantScale("demo"),
+		},
+		{
+			name:  "upper-case scale with whitespace is canonicalized",
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: " LARGE "},
+			check: wantScale("large"),
+		},
+		{
+			name:        "unknown scale",
+			req:         models.CreateRunRequest{Mode: "baseline", Scale: "Gigantic"},
+			wantInvalid: true,
+		},
+		{
+			name:  "explicit seed is kept",
+			req:   models.CreateRunRequest{Mode: "baseline", Seed: ptr(7)},
+			check: wantSeed(7),
+		},
+		{
+			name:  "no seed uses the default",
+			cfg:   func(cfg *config.Config) { cfg.DefaultSeed = 99 },
+			req:   models.CreateRunRequest{Mode: "baseline"},
+			check: wantSeed(99),
+		},
+		{
+			name:        "seed and random_seed together",
+			req:         models.CreateRunRequest{Mode: "baseline", Seed: ptr(7), RandomSeed: true},
+			wantInvalid: true,
+		},
+		{
+			name:  "preset scale reports the preset fleet",
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: "large"},
+			check: wantFleet(20, 100, "preset"),
+		},
+		{
+			name:  "default scale reports its preset fleet",
+			req:   models.CreateRunRequest{Mode: "baseline"},
+			check: wantFleet(10, 50, "preset"),
+		},
+		{
+			name:  "custom scale reports its configured fleet",
+			env:   map[string]string{"CUSTOM_SCALES": `{"pair":{"robots":2,"jobs":3}}`},
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: "pair"},
+			check: wantFleet(2, 3, "preset"),
+		},
+		{
+			name: "overrides keep the nullable override fields",
+			req:  models.CreateRunRequest{Mode: "baseline", Scale: "large", Robots: ptr(3), Jobs: ptr(9)},
+			check: func(t *testing.T, resp *models.CreateRunResponse) {
+				wantFleet(3, 9, "override")(t, resp)
+				if resp.Robots == nil || *resp.Robots != 3 || resp.Jobs == nil || *resp.Jobs != 9 {
+					t.Errorf("robots = %v, jobs = %v, want the overrides echoed", resp.Robots, resp.Jobs)
+				}
+			},
+		},
+		{
+			name:  "strict fleet off by default allows more robots than jobs",
+			req:   models.CreateRunRequest{Mode: "baseline", Robots: ptr(6), Jobs: ptr(5)},
+			check: wantFleet(6, 5, "override"),
+		},
+		{
+			name:  "strict fleet allows robots equal to jobs",
+			req:   models.CreateRunRequest{Mode: "baseline", Robots: ptr(5), Jobs: ptr(5), StrictFleet: ptr(true)},
+			check: wantFleet(5, 5, "override"),
+		},
+		{
+			name:    "strict fleet rejects one robot over jobs",
+			req:     models.CreateRunRequest{Mode: "baseline", Robots: ptr(6), Jobs: ptr(5), StrictFleet: ptr(true)},
+			wantErr: ErrFleetTooManyRobots,
+		},
+		{
+			name:    "STRICT_FLEET applies to preset counts",
+			env:     map[string]string{"CUSTOM_SCALES": `{"swarm":{"robots":8,"jobs":4}}`},
+			cfg:     func(cfg *config.Config) { cfg.StrictFleet = true },
+			req:     models.CreateRunRequest{Mode: "baseline", Scale: "swarm"},
+			wantErr: ErrFleetTooManyRobots,
+		},
+		{
+			name:  "per-request strict_fleet=false overrides STRICT_FLEET",
+			cfg:   func(cfg *config.Config) { cfg.StrictFleet = true },
+			req:   models.CreateRunRequest{Mode: "baseline", Robots: ptr(6), Jobs: ptr(5), StrictFleet: ptr(false)},
+			check: wantFleet(6, 5, "override"),
+		},
+		{
+			name:  "omitted mode uses the scale default",
+			cfg:   func(cfg *config.Config) { cfg.ScaleDefaultMode = map[string]string{"large": "ga"} },
+			req:   models.CreateRunRequest{Scale: "Large"},
+			check: wantMode("ga"),
+		},
+		{
+			name: "omitted mode falls back to the global default for unmapped scales",
+			cfg: func(cfg *config.Config) {
+				cfg.DefaultMode = "ga"
+				cfg.ScaleDefaultMode = map[string]string{"mini": "baseline"}
+			},
+			req:   models.CreateRunRequest{Scale: "demo"},
+			check: wantMode("ga"),
+		},
+		{
+			name:  "explicit mode wins over the scale default",
+			cfg:   func(cfg *config.Config) { cfg.ScaleDefaultMode = map[string]string{"large": "ga"} },
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: "large"},
+			check: wantMode("baseline"),
+		},
+		{
+			name:        "invalid mode",
+			req:         models.CreateRunRequest{Mode: "random"},
+			wantInvalid: true,
+		},
+		{
+			name:    "compare-only scale cannot create runs",
+			cfg:     func(cfg *config.Config) { cfg.CompareOnlyScales = []string{"mini"} },
+			req:     models.CreateRunRequest{Mode: "baseline", Scale: "Mini"},
+			wantErr: ErrScaleNotAllowed,
+		},
+		{
+			name:  "create-only scale can create runs",
+			cfg:   func(cfg *config.Config) { cfg.CreateOnlyScales = []string{"mini"} },
+			req:   models.CreateRunRequest{Mode: "baseline", Scale: "mini"},
+			check: wantScale("mini"),
+		},
+		{
+			name:    "replan_interval_s on a baseline run",
+			req:     models.CreateRunRequest{Mode: "baseline", ReplanIntervalS: ptr(30)},
+			wantErr: ErrReplanIntervalRequiresGA,
+		},
+		{
+			name:        "replan_interval_s below the minimum",
+			req:         models.CreateRunRequest{Mode: "ga", ReplanIntervalS: ptr(minReplanIntervalS - 1)},
+			wantInvalid: true,
+		},
+		{
+			name:  "replan_interval_s at the minimum",
+			req:   models.CreateRunRequest{Mode: "ga", ReplanIntervalS: ptr(minReplanIntervalS)},
+			check: wantReplan(ptr(minReplanIntervalS)),
+		},
+		{
+			name:  "ga run without replan_interval_s keeps the config default",
+			req:   models.CreateRunRequest{Mode: "ga"},
+			check: wantReplan(nil),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			cfg := testConfig(t)
+			if tc.cfg != nil {
+				tc.cfg(cfg)
+			}
+			svc, fake, pub := newTestService(t, cfg)
+			fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+			resp, err := svc.CreateRun(context.Background(), tc.req)
+			switch {
+			case tc.wantErr != nil:
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+			case tc.wantInvalid:
+				if !IsValidation(err) {
+					t.Fatalf("err = %v, want a validation error", err)
+				}
+			case err != nil:
+				t.Fatalf("CreateRun: %v", err)
+			}
+			if err != nil {
+				if n := len(fake.Matching("INSERT INTO runs")); n != 0 || len(pub.published("run.created")) != 0 {
+					t.Errorf("rejected create still inserted (%d) or published", n)
+				}
+				return
+			}
+			if tc.check != nil {
+				tc.check(t, resp)
+			}
+		})
+	}
+}
+
+func wantScale(scale string) func(t *testing.T, resp *models.CreateRunResponse) {
+	return func(t *testing.T, resp *models.CreateRunResponse) {
+		t.Helper()
+		if resp.Scale != scale {
+			t.Errorf("Scale = %q, want %q", resp.Scale, scale)
+		}
+	}
+}
+
+func wantSeed(seed int) func(t *testing.T, resp *models.CreateRunResponse) {
+	return func(t *testing.T, resp *models.CreateRunResponse) {
+		t.Helper()
+		if resp.Seed != seed {
+			t.Errorf("Seed = %d, want %d", resp.Seed, seed)
+		}
+	}
+}
+
+func wantFleet(robots, jobs int, source string) func(t *testing.T, resp *models.CreateRunResponse) {
+	return func(t *testing.T, resp *models.CreateRunResponse) {
+		t.Helper()
+		if got := resp.Effective; got.Robots != robots || got.Jobs != jobs || got.Source != source {
+			t.Errorf("effective = %+v, want %d robots, %d jobs from %s", got, robots, jobs, source)
+		}
+	}
+}
+
+func wantMode(mode string) func(t *testing.T, resp *models.CreateRunResponse) {
+	return func(t *testing.T, resp *models.CreateRunResponse) {
+		t.Helper()
+		if resp.Mode != mode {
+			t.Errorf("Mode = %q, want %q", resp.Mode, mode)
+		}
+	}
+}
+
+func wantReplan(interval *int) func(t *testing.T, resp *models.CreateRunResponse) {
+	return func(t *testing.T, resp *models.CreateRunResponse) {
+		t.Helper()
+		if !reflect.DeepEqual(resp.ReplanIntervalS, interval) {
+			t.Errorf("ReplanIntervalS = %v, want %v", resp.ReplanIntervalS, interval)
+		}
+	}
+}
+
+func TestCreateRunRandomSeed(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.Return("SELECT COUNT(*) FROM runs", dbtest.Result{Rows: [][]any{{0}}})
+
+	seeds := map[int]bool{}
+	for range 2 {
+		resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline", RandomSeed: true})
+		if err != nil {
+			t.Fatalf("CreateRun: %v", err)
+		}
+		if resp.Seed < 0 || resp.Seed > math.MaxInt32 {
+			t.Errorf("seed %d does not fit runs.seed", resp.Seed)
+		}
+		seeds[resp.Seed] = true
+	}
+	if len(seeds) != 2 {
+		t.Errorf("seeds = %v, want two distinct random seeds", seeds)
+	}
+	events := pub.published("run.created")
+	if len(events) != 2 {
+		t.Fatalf("run.created events = %d, want 2", len(events))
+	}
+	for _, e := range events {
+		if seed, ok := e.Payload["seed"].(int); !ok || !seeds[seed] {
+			t.Errorf("run.created seed = %v, want one of the returned seeds %v", e.Payload["seed"], seeds)
+		}
+	}
+}
+
+func TestGetRunsByIDsReportsNotFound(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	completed := started.Add(90 * time.Second)
+	fake.On("FROM runs WHERE id IN", func(args []any) dbtest.Result {
+		var rows [][]any
+		for _, id := range args {
+			if id != "r2" {
+				rows = append(rows, []any{id, "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, started, started, completed, nil, nil, nil, nil, nil})
+			}
+		}
+		return dbtest.Result{Rows: rows}
+	})
+	fake.Return("FROM run_tags", dbtest.Result{Rows: [][]any{{"r3", "exp:q3"}}})
+
+	resp, err := svc.GetRunsByIDs(context.Background(), []string{"r3", "r2", "r1", "r2"})
+	if err != nil {
+		t.Fatalf("GetRunsByIDs: %v", err)
+	}
+	if len(resp.Runs) != 2 || resp.Runs[0].ID != "r3" || resp.Runs[1].ID != "r1" {
+		t.Fatalf("runs = %+v, want r3 then r1", resp.Runs)
+	}
+	if !reflect.DeepEqual(resp.NotFound, []string{"r2"}) {
+		t.Errorf("not_found = %v, want [r2] once", resp.NotFound)
+	}
+	if !reflect.DeepEqual(resp.Runs[0].Tags, []string{"exp:q3"}) || resp.Runs[0].DurationSeconds == nil || *resp.Runs[0].DurationSeconds != 90 {
+		t.Errorf("run = %+v, want tags and a 90s duration", resp.Runs[0])
+	}
+}
+
+func TestGetRunsByIDsLimitsDistinctIDs(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	ids := make([]string, maxBulkRunIDs+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("r%d", i)
+	}
+	if _, err := svc.GetRunsByIDs(context.Background(), ids); !IsValidation(err) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if _, err := svc.GetRunsByIDs(context.Background(), nil); !IsValidation(err) {
+		t.Errorf("no ids: err = %v, want a validation error", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+	dup := append(ids[:maxBulkRunIDs:maxBulkRunIDs], ids[0])
+	if _, err := svc.GetRunsByIDs(context.Background(), dup); err != nil {
+		t.Fatalf("GetRunsByIDs at the limit: %v", err)
+	}
+}
+
+func TestSetDuration(t *testing.T) {
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name      string
+		completed time.Time
+		want      int64
+	}{
+		{"normal run", started.Add(90 * time.Second), 90},
+		{"sub-second run", started.Add(400 * time.Millisecond), 0},
+		{"skewed clock clamps to zero", started.Add(-30 * time.Second), 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			run := models.Run{StartedAt: &started, CompletedAt: &tc.completed}
+			setDuration(&run)
+			if run.DurationSeconds == nil || *run.DurationSeconds != tc.want {
+				t.Errorf("DurationSeconds = %v, want %d", run.DurationSeconds, tc.want)
+			}
+		})
+	}
+
+	run := models.Run{StartedAt: &started}
+	setDuration(&run)
+	if run.DurationSeconds != nil {
+		t.Errorf("DurationSeconds = %d for an unfinished run, want nil", *run.DurationSeconds)
+	}
+}
+
+func TestRepublishRun(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		status      string
+		force       bool
+		wantErr     error
+		wantPublish bool
+	}{
+		{"started", "started", false, nil, true},
+		{"terminal without force", "completed", false, ErrRunTerminal, false},
+		{"terminal with force", "failed", true, nil, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, pub := newTestService(t, testConfig(t))
+			created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+				{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, tc.status, nil, created, created, nil, nil, nil, nil, nil, nil},
+			}})
+
+			run, err := svc.RepublishRun(context.Background(), "run-1", tc.force)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && (run == nil || run.ID != "run-1" || run.Status != tc.status) {
+				t.Errorf("run = %+v, want run-1 in %s", run, tc.status)
+			}
+			events := pub.published("run.created")
+			if got := len(events) == 1; got != tc.wantPublish {
+				t.Fatalf("run.created events = %+v, want published %v", events, tc.wantPublish)
+			}
+			if tc.wantPublish && (events[0].Payload["run_id"] != "run-1" || events[0].Payload["seed"] != 42) {
+				t.Errorf("payload = %v, want the stored run parameters", events[0].Payload)
+			}
+		})
+	}
+}
+
+func TestRepublishRunNotFound(t *testing.T) {
+	svc, _, pub := newTestService(t, testConfig(t))
+	if _, err := svc.RepublishRun(context.Background(), "missing", true); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("err = %v, want ErrRunNotFound", err)
+	}
+	if len(pub.published("run.created")) != 0 {
+		t.Error("published for a missing run")
+	}
+}
+
+func TestPublishWithRetry(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		failures    int
+		wantErr     bool
+		wantAttempt int
+		wantRetries int
+	}{
+		{"first attempt succeeds", 0, false, 1, 0},
+		{"fails twice then succeeds", 2, false, 3, 2},
+		{"exhausts every attempt", 5, true, 3, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.PublishAttempts = 3
+			cfg.PublishBackoff = time.Millisecond
+			svc, fake, pub := newTestService(t, cfg)
+			attempts := 0
+			pub.fail = func(string) error {
+				attempts++
+				if attempts <= tc.failures {
+					return fmt.Errorf("broker down (attempt %d)", attempts)
+				}
+				return nil
+			}
+
+			err := svc.publishWithRetry(context.Background(), "run.created", map[string]any{"run_id": "run-1"})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr && err.Error() != "broker down (attempt 3)" {
+				t.Errorf("err = %v, want the last attempt's error", err)
+			}
+			if attempts != tc.wantAttempt || pub.Stats().Retried != uint64(tc.wantRetries) {
+				t.Errorf("attempts = %d, retries = %d, want %d and %d", attempts, pub.Stats().Retried, tc.wantAttempt, tc.wantRetries)
+			}
+			wantRecorded := 1
+			if tc.wantErr {
+				wantRecorded = 0
+			}
+			if got := len(pub.published("run.created")); got != wantRecorded {
+				t.Errorf("published = %d, want %d", got, wantRecorded)
+			}
+			if got := len(fake.Matching("INSERT INTO run_events")); got != wantRecorded {
+				t.Errorf("run_events inserts = %d, want %d", got, wantRecorded)
+			}
+		})
+	}
+}
+
+func TestPublishWithRetryStopsOnCancel(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PublishAttempts = 5
+	cfg.PublishBackoff = time.Hour
+	svc, _, pub := newTestService(t, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	pub.fail = func(string) error {
+		attempts++
+		cancel()
+		return errors.New("broker down")
+	}
+
+	if err := svc.publishWithRetry(ctx, "run.created", map[string]any{"run_id": "run-1"}); err == nil {
+		t.Fatal("publishWithRetry succeeded")
+	}
+	if attempts != 1 || pub.Stats().Retried != 0 {
+		t.Errorf("attempts = %d, retries = %d, want one attempt and no retry", attempts, pub.Stats().Retried)
+	}
+}
+
+func TestPeer(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		name, mode, wantPeerMode string
+		robots, jobs             any
+		wantArgs                 []any
+	}{
+		{"ga run", "ga", "baseline", nil, nil, []any{int64(42), "demo", "baseline", int64(2)}},
+		{"baseline run", "baseline", "ga", nil, nil, []any{int64(42), "demo", "ga", int64(2)}},
+		{"fleet override", "ga", "baseline", 7, 30, []any{int64(42), "demo", "baseline", int64(2), int64(7), int64(30)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, fake, _ := newTestService(t, testConfig(t))
+			fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+				{"run-1", tc.mode, 42, "demo", tc.robots, tc.jobs, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+			}})
+			fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+				{"run-2", 0.9, 100.0, 30.0, 5.0, 9, 1, 10, now, "completed"},
+			}})
+
+			resp, err := svc.Peer(context.Background(), "run-1")
+			if err != nil {
+				t.Fatalf("Peer: %v", err)
+			}
+			if resp.RunID != "run-1" || resp.Mode != tc.mode || resp.PeerMode != tc.wantPeerMode || resp.Peer == nil || resp.Peer.RunID != "run-2" {
+				t.Errorf("resp = %+v, want run-2 as the %s peer", resp, tc.wantPeerMode)
+			}
+			reads := fake.Matching("FROM run_metrics rm")
+			if len(reads) != 1 || !reflect.DeepEqual(reads[0].Args, tc.wantArgs) {
+				t.Errorf("peer read args = %+v, want %v", reads, tc.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPeerNotFound(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.Peer(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Errorf("unknown run err = %v, want ErrRunNotFound", err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, now, now, now, nil, nil, nil, nil, nil},
+	}})
+	if _, err := svc.Peer(context.Background(), "run-1"); !errors.Is(err, ErrPeerNotFound) {
+		t.Errorf("no completed peer err = %v, want ErrPeerNotFound", err)
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+// File: internal/services/score.go
+// Purpose: Weighted multi-objective score for baseline vs GA comparisons.
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"fleet-api-go/internal/models"
+)
+
+// scoreMetrics lists the metrics a weight may target and whether lower values are better.
+var scoreMetrics = map[string]bool{
+	"on_time_rate":        false,
+	"total_distance":      true,
+	"avg_completion_time": true,
+	"max_lateness":        true,
+	"completed_jobs":      false,
+	"failed_jobs":         true,
+}
+
+// scoreAliases are the short names accepted in the weights query parameter.
+var scoreAliases = map[string]string{
+	"on_time":    "on_time_rate",
+	"distance":   "total_distance",
+	"completion": "avg_completion_time",
+	"lateness":   "max_lateness",
+	"completed":  "completed_jobs",
+	"failed":     "failed_jobs",
+}
+
+// parseWeights parses "on_time:0.5,distance:0.3,lateness:0.2" into metric weights
+// normalized to sum to 1. Full metric names are accepted as well as the aliases.
+func parseWeights(spec string) (map[string]float64, error) {
+	weights := map[string]float64{}
+	sum := 0.0
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, raw, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, invalidf("invalid weight %q: expected metric:weight", item)
+		}
+		name = strings.TrimSpace(name)
+		if alias, ok := scoreAliases[name]; ok {
+			name = alias
+		}
+		if _, ok := scoreMetrics[name]; !ok {
+			return nil, invalidf("invalid weight %q: unknown metric %q", item, name)
+		}
+		if _, dup := weights[name]; dup {
+			return nil, invalidf("duplicate weight for %s", name)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || w < 0 || math.IsInf(w, 0) || math.IsNaN(w) {
+			return nil, invalidf("invalid weight %q: must be a non-negative number", item)
+		}
+		weights[name] = w
+		sum += w
+	}
+	if len(weights) == 0 {
+		return nil, invalidf("weights must name at least one metric")
+	}
+	if sum == 0 {
+		return nil, invalidf("weights must not all be zero")
+	}
+	for name := range weights {
+		weights[name] /= sum
+	}
+	return weights, nil
+}
+
+// scoreCompare scores both modes on [0, 1] and picks a winner. Each metric is
+// normalized against the better of the two values (value/best when higher is
+// better, best/value when lower is better), so the better mode gets 1 and the
+// other its fraction of it; equal values, including both zero, score 1 each.
+// Returns nil when either mode is missing.
+func scoreCompare(baseline, ga *models.RunMetrics, weights map[string]float64) *models.CompareScore {
+	if baseline == nil || ga == nil {
+		return nil
+	}
+	score := &models.CompareScore{
+		Weights:  weights,
+		Baseline: models.ModeScore{Contributions: map[string]float64{}},
+		GA:       models.ModeScore{Contributions: map[string]float64{}},
+	}
+	for name, weight := range weights {
+		b, g := *metricValue(baseline, name), *metricValue(ga, name)
+		nb, ng := normalizePair(b, g, scoreMetrics[name])
+		score.Baseline.Contributions[name] = weight * nb
+		score.GA.Contributions[name] = weight * ng
+		score.Baseline.Total += weight * nb
+		score.GA.Total += weight * ng
+	}
+	const epsilon = 1e-9
+	switch {
+	case score.GA.Total-score.Baseline.Total > epsilon:
+		score.Winner = "ga"
+	case score.Baseline.Total-score.GA.Total > epsilon:
+		score.Winner = "baseline"
+	default:
+		score.Winner = "tie"
+	}
+	return score
+}
+
+// normalizePair maps two non-negative metric values to [0, 1] relative to the better one.
+func normalizePair(a, b float64, lowerIsBetter bool) (float64, float64) {
+	if a == b {
+		return 1, 1
+	}
+	if lowerIsBetter {
+		best := math.Min(a, b)
+		return ratio(best, a), ratio(best, b)
+	}
+	best := math.Max(a, b)
+	return ratio(a, best), ratio(b, best)
+}
+
+// ratio returns num/den, treating 0/0 as a perfect score (the value equals the best).
+func ratio(num, den float64) float64 {
+	if num == den {
+		return 1
+	}
+	if den <= 0 {
+		return 0
+	}
+	return num / den
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/models"
+)
+
+func TestParseWeights(t *testing.T) {
+	got, err := parseWeights("on_time:0.5, distance:0.3,max_lateness:0.2")
+	if err != nil {
+		t.Fatalf("parseWeights: %v", err)
+	}
+	want := map[string]float64{"on_time_rate": 0.5, "total_distance": 0.3, "max_lateness": 0.2}
+	if !maps.EqualFunc(got, want, approx) {
+		t.Errorf("weights = %v, want %v", got, want)
+	}
+
+	// Weights are normalized to sum to 1.
+	got, err = parseWeights("on_time:2,failed:6")
+	if err != nil {
+		t.Fatalf("parseWeights: %v", err)
+	}
+	if !maps.EqualFunc(got, map[string]float64{"on_time_rate": 0.25, "failed_jobs": 0.75}, approx) {
+		t.Errorf("weights = %v, want 0.25/0.75", got)
+	}
+
+	for _, tc := range []struct{ spec, want string }{
+		{"on_time", "expected metric:weight"},
+		{"speed:1", `unknown metric "speed"`},
+		{"on_time:1,on_time_rate:1", "duplicate weight for on_time_rate"},
+		{"on_time:-1", "must be a non-negative number"},
+		{"on_time:NaN", "must be a non-negative number"},
+		{" , ", "at least one metric"},
+		{"on_time:0,distance:0", "must not all be zero"},
+	} {
+		if _, err := parseWeights(tc.spec); !IsValidation(err) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parseWeights(%q) err = %v, want a validation error containing %q", tc.spec, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizePair(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		a, b          float64
+		lowerIsBetter bool
+		wantA, wantB  float64
+	}{
+		{"higher is better", 0.8, 0.4, false, 1, 0.5},
+		{"lower is better", 100, 200, true, 1, 0.5},
+		{"lower is better, reversed", 200, 100, true, 0.5, 1},
+		{"equal values", 3, 3, true, 1, 1},
+		{"both zero", 0, 0, false, 1, 1},
+		{"zero is the best lower value", 0, 5, true, 1, 0},
+		{"zero loses when higher is better", 0, 5, false, 0, 1},
+	} {
+		a, b := normalizePair(tc.a, tc.b, tc.lowerIsBetter)
+		if !approx(a, tc.wantA) || !approx(b, tc.wantB) {
+			t.Errorf("%s: normalizePair(%v, %v) = %v, %v, want %v, %v", tc.name, tc.a, tc.b, a, b, tc.wantA, tc.wantB)
+		}
+	}
+}
+
+func TestScoreCompare(t *testing.T) {
+	baseline := &models.RunMetrics{OnTimeRate: 0.8, TotalDistance: 100, MaxLateness: 10}
+	ga := &models.RunMetrics{OnTimeRate: 0.9, TotalDistance: 200, MaxLateness: 5}
+	weights := map[string]float64{"on_time_rate": 0.5, "total_distance": 0.3, "max_lateness": 0.2}
+
+	score := scoreCompare(baseline, ga, weights)
+	if score == nil {
+		t.Fatal("score is nil with both modes present")
+	}
+	// GA: 0.5*1 + 0.3*0.5 + 0.2*1 = 0.85; baseline: 0.5*(0.8/0.9) + 0.3*1 + 0.2*0.5.
+	wantGA := map[string]float64{"on_time_rate": 0.5, "total_distance": 0.15, "max_lateness": 0.2}
+	wantBaseline := map[string]float64{"on_time_rate": 0.5 * 0.8 / 0.9, "total_distance": 0.3, "max_lateness": 0.1}
+	if !maps.EqualFunc(score.GA.Contributions, wantGA, approx) || !approx(score.GA.Total, 0.85) {
+		t.Errorf("ga = %+v, want contributions %v totalling 0.85", score.GA, wantGA)
+	}
+	if !maps.EqualFunc(score.Baseline.Contributions, wantBaseline, approx) || !approx(score.Baseline.Total, 0.5*0.8/0.9+0.4) {
+		t.Errorf("baseline = %+v, want contributions %v", score.Baseline, wantBaseline)
+	}
+	if score.Winner != "ga" {
+		t.Errorf("winner = %q, want ga", score.Winner)
+	}
+
+	// Weighting distance alone flips the winner, since lower distance is better.
+	if got := scoreCompare(baseline, ga, map[string]float64{"total_distance": 1}).Winner; got != "baseline" {
+		t.Errorf("distance-only winner = %q, want baseline", got)
+	}
+	if got := scoreCompare(baseline, baseline, weights).Winner; got != "tie" {
+		t.Errorf("identical metrics winner = %q, want tie", got)
+	}
+	if scoreCompare(nil, ga, weights) != nil || scoreCompare(baseline, nil, weights) != nil {
+		t.Error("scored a comparison with a missing mode; want nil")
+	}
+}
+
+func TestCompareWeights(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	compareScenario(fake, map[string][]float64{"baseline": {0.8}, "ga": {0.9}}, map[string]int{"baseline": 1, "ga": 1})
+	ctx := context.Background()
+
+	resp, err := svc.Compare(ctx, 42, "demo", nil, nil, "on_time:1", 0, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Score == nil || resp.Score.Winner != "ga" || !approx(resp.Score.GA.Total, 1) {
+		t.Errorf("score = %+v, want ga winning with a total of 1", resp.Score)
+	}
+
+	resp, err = svc.Compare(ctx, 42, "demo", nil, nil, "", 0, 0)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if resp.Score != nil {
+		t.Errorf("score = %+v without weights, want none", resp.Score)
+	}
+
+	if _, err := svc.Compare(ctx, 42, "demo", nil, nil, "speed:1", 0, 0); !IsValidation(err) {
+		t.Errorf("invalid weights err = %v, want a validation error", err)
+	}
+}
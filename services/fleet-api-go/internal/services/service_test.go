@@ -0,0 +1,91 @@
+package services
+
+// File: internal/services/service_test.go
+// Purpose: Shared fixtures for service tests: a RunService over the dbtest fake
+// store and an in-memory publisher.
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/mq"
+)
+
+// publishedEvent is one event handed to fakePublisher.
+type publishedEvent struct {
+	RoutingKey string
+	Payload    map[string]any
+}
+
+// fakePublisher records events instead of sending them. fail, when set, is
+// consulted before each publish and its error returned.
+type fakePublisher struct {
+	mu      sync.Mutex
+	events  []publishedEvent
+	retries int
+	fail    func(routingKey string) error
+}
+
+func (p *fakePublisher) PublishContext(ctx context.Context, routingKey string, payload map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail != nil {
+		if err := p.fail(routingKey); err != nil {
+			return err
+		}
+	}
+	p.events = append(p.events, publishedEvent{RoutingKey: routingKey, Payload: payload})
+	return nil
+}
+
+func (p *fakePublisher) RecordRetry() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries++
+}
+
+func (p *fakePublisher) Stats() mq.Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return mq.Stats{Published: uint64(len(p.events)), Retried: uint64(p.retries)}
+}
+
+// published returns the recorded events with routingKey.
+func (p *fakePublisher) published(routingKey string) []publishedEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out []publishedEvent
+	for _, e := range p.events {
+		if e.RoutingKey == routingKey {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// testConfig loads the default configuration with auditing off, so tests do
+// not write audit lines unless they opt in.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	cfg.AuditLog = "off"
+	return cfg
+}
+
+// newTestService returns a RunService for cfg over a fresh fake store and publisher.
+func newTestService(t *testing.T, cfg *config.Config) (*RunService, *dbtest.Fake, *fakePublisher) {
+	t.Helper()
+	store, fake := dbtest.Open(t)
+	pub := &fakePublisher{}
+	svc := NewRunService(cfg, store, pub)
+	return svc, fake, pub
+}
@@ -0,0 +1,158 @@
+package services
+
+// File: internal/services/status.go
+// Purpose: Bulk terminal status updates reported by the simulator.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"fleet-api-go/internal/db"
+	"fleet-api-go/internal/models"
+)
+
+// BulkUpdateStatus validates and applies a batch of terminal status updates in one
+// transaction, then publishes one run.completed event per run. When any item is
+// invalid, missing or no longer started, nothing is applied and ErrBatchRejected is
+// returned together with per-item results (also wrapping ErrRunNotStarted in the
+// last case). Every item is audited as run.status.
+func (s *RunService) BulkUpdateStatus(ctx context.Context, updates []models.RunStatusUpdate) (*models.BulkStatusResponse, error) {
+	resp, err := s.bulkUpdateStatus(ctx, updates)
+	s.auditStatusBatch(ctx, updates, resp, err)
+	return resp, err
+}
+
+func (s *RunService) bulkUpdateStatus(ctx context.Context, updates []models.RunStatusUpdate) (*models.BulkStatusResponse, error) {
+	if len(updates) == 0 {
+		return nil, invalidf("at least one status update is required")
+	}
+	if max := s.cfg.BulkStatusMax; max > 0 && len(updates) > max {
+		return nil, invalidf("batch too large: %d items (max %d)", len(updates), max)
+	}
+
+	seen := make(map[string]bool, len(updates))
+	for i, u := range updates {
+		if err := validateStatusUpdate(u, seen); err != nil {
+			return rejectedBatch(updates, i, err), ErrBatchRejected
+		}
+		if u.ErrorMessage != nil {
+			msg := sanitizeErrorMessage(*u.ErrorMessage, s.cfg.ErrorMessageMax)
+			updates[i].ErrorMessage = &msg
+		}
+	}
+
+	runs, err := s.store.BulkUpdateRunStatus(ctx, updates)
+	if err != nil {
+		var itemErr *db.ItemError
+		if errors.As(err, &itemErr) {
+			switch {
+			case errors.Is(itemErr.Err, db.ErrNotFound):
+				return rejectedBatch(updates, itemErr.Index, ErrRunNotFound), ErrBatchRejected
+			case errors.Is(itemErr.Err, db.ErrRunNotStarted):
+				return rejectedBatch(updates, itemErr.Index, itemErr.Err), fmt.Errorf("%w: %w", ErrBatchRejected, ErrRunNotStarted)
+			case errors.Is(itemErr.Err, db.ErrCompletedBeforeStart):
+				return rejectedBatch(updates, itemErr.Index, itemErr.Err), ErrBatchRejected
+			}
+		}
+		return nil, err
+	}
+
+	resp := &models.BulkStatusResponse{Applied: true, Results: make([]models.RunStatusUpdateResult, len(runs))}
+	for i, run := range runs {
+		if run.Status == "completed" {
+			s.compareCache.invalidateScenario(run.Seed, run.Scale)
+		}
+		resp.Results[i] = models.RunStatusUpdateResult{ID: run.ID, Result: "updated"}
+		// The rows are committed; a publish failure is reported per item rather than failing the batch.
+		if err := s.publishWithRetry(ctx, "run.completed", buildRunCompletedEvent(run, updates[i].Metrics)); err != nil {
+			log.Printf("publish run.completed run_id=%s: %v", run.ID, err)
+			continue
+		}
+		resp.Results[i].Published = true
+	}
+	return resp, nil
+}
+
+func validateStatusUpdate(u models.RunStatusUpdate, seen map[string]bool) error {
+	if u.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if seen[u.ID] {
+		return fmt.Errorf("duplicate id in batch")
+	}
+	seen[u.ID] = true
+	// Cancellation goes through POST /runs/{id}/cancel so that run.cancelled is published.
+	if !isTerminalStatus(u.Status) || u.Status == "cancelled" {
+		return fmt.Errorf("status must be completed, failed or stopped")
+	}
+	if u.Metrics != nil && u.Status != "completed" {
+		return fmt.Errorf("metrics are only accepted for completed runs")
+	}
+	return nil
+}
+
+// sanitizeErrorMessage makes a simulator error safe to store and log: tabs and line
+// breaks become spaces, other control characters are dropped, and the result is cut
+// to max characters (runes) ending in an ellipsis. max <= 0 disables truncation.
+func sanitizeErrorMessage(msg string, max int) string {
+	msg = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, msg)
+	if runes := []rune(msg); max > 0 && len(runes) > max {
+		msg = string(runes[:max-1]) + "…"
+	}
+	return msg
+}
+
+// rejectedBatch marks the failing item as rejected and every other item as rolled back.
+func rejectedBatch(updates []models.RunStatusUpdate, failed int, cause error) *models.BulkStatusResponse {
+	resp := &models.BulkStatusResponse{Results: make([]models.RunStatusUpdateResult, len(updates))}
+	for i, u := range updates {
+		resp.Results[i] = models.RunStatusUpdateResult{ID: u.ID, Result: "rolled_back"}
+	}
+	resp.Results[failed].Result = "rejected"
+	resp.Results[failed].Error = cause.Error()
+	return resp
+}
+
+// buildRunCompletedEvent mirrors the run.completed payload sim-runner emits.
+func buildRunCompletedEvent(run models.Run, metrics *models.RunMetrics) map[string]any {
+	event := map[string]any{
+		"event_id":      uuid.NewString(),
+		"event_type":    "run.completed",
+		"run_id":        run.ID,
+		"mode":          run.Mode,
+		"seed":          run.Seed,
+		"scale":         run.Scale,
+		"sim_time_s":    0,
+		"scenario_hash": run.ScenarioHash,
+		"status":        run.Status,
+	}
+	if run.ErrorMessage != nil {
+		event["error"] = *run.ErrorMessage
+	}
+	if metrics != nil {
+		event["metrics"] = map[string]any{
+			"on_time_rate":        metrics.OnTimeRate,
+			"total_distance":      metrics.TotalDistance,
+			"avg_completion_time": metrics.AvgCompletionTime,
+			"max_lateness":        metrics.MaxLateness,
+			"completed_jobs":      metrics.CompletedJobs,
+			"failed_jobs":         metrics.FailedJobs,
+			"total_jobs":          metrics.TotalJobs,
+		}
+	}
+	return event
+}
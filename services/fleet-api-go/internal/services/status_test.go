@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+// statusRows answers the bulk status FOR UPDATE select with each run's status from statuses.
+func statusRows(statuses map[string]string) dbtest.Rule {
+	return func(args []any) dbtest.Result {
+		status, ok := statuses[args[0].(string)]
+		if !ok {
+			return dbtest.Result{}
+		}
+		return dbtest.Result{Rows: [][]any{{args[0], "baseline", 42, "small", nil, nil, "hash", status, time.Now().Add(-time.Hour)}}}
+	}
+}
+
+func TestBulkUpdateStatusConflictsOnFinishedRun(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started", "run-2": "cancelled"}))
+
+	resp, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "completed"},
+	})
+	if !errors.Is(err, ErrBatchRejected) || !errors.Is(err, ErrRunNotStarted) {
+		t.Fatalf("err = %v, want ErrBatchRejected and ErrRunNotStarted", err)
+	}
+	if resp == nil || resp.Applied {
+		t.Fatalf("resp = %+v, want an unapplied batch", resp)
+	}
+	if r := resp.Results[0]; r.Result != "rolled_back" {
+		t.Errorf("item 0 = %+v, want rolled_back", r)
+	}
+	if r := resp.Results[1]; r.Result != "rejected" || r.Error != "run is not started (status cancelled)" {
+		t.Errorf("item 1 = %+v, want rejected with the run's status", r)
+	}
+	if got := len(pub.published("run.completed")); got != 0 {
+		t.Errorf("run.completed events = %d, want 0", got)
+	}
+}
+
+func TestBulkUpdateStatusMissingRunIsNotAConflict(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started"}))
+
+	resp, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-9", Status: "completed"},
+	})
+	if !errors.Is(err, ErrBatchRejected) || errors.Is(err, ErrRunNotStarted) {
+		t.Fatalf("err = %v, want ErrBatchRejected only", err)
+	}
+	if r := resp.Results[1]; r.Result != "rejected" || r.Error != ErrRunNotFound.Error() {
+		t.Errorf("item 1 = %+v, want rejected as not found", r)
+	}
+}
+
+func TestBulkUpdateStatusPublishesCompletedRuns(t *testing.T) {
+	svc, fake, pub := newTestService(t, testConfig(t))
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started", "run-2": "started"}))
+
+	resp, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "completed"},
+		{ID: "run-2", Status: "failed"},
+	})
+	if err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	if !resp.Applied || !resp.Results[0].Published || !resp.Results[1].Published {
+		t.Errorf("resp = %+v, want applied and published", resp)
+	}
+	if got := len(pub.published("run.completed")); got != 2 {
+		t.Errorf("run.completed events = %d, want 2", got)
+	}
+}
+
+func TestSanitizeErrorMessage(t *testing.T) {
+	for _, tc := range []struct {
+		name, msg string
+		max       int
+		want      string
+	}{
+		{"plain message", "planner crashed", 2000, "planner crashed"},
+		{"newlines and tabs become spaces", "panic:\n\tat plan()\r", 2000, "panic:  at plan() "},
+		{"other control chars are dropped", "bad\x1b[31m\x00state\x7f", 2000, "bad[31mstate"},
+		{"truncated with an ellipsis", "abcdefghij", 5, "abcd…"},
+		{"exactly at the limit", "abcde", 5, "abcde"},
+		{"multibyte runes are not split", "ééééé", 3, "éé…"},
+		{"no limit", strings.Repeat("x", 3000), 0, strings.Repeat("x", 3000)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sanitizeErrorMessage(tc.msg, tc.max)
+			if got != tc.want {
+				t.Errorf("sanitizeErrorMessage(%q, %d) = %q, want %q", tc.msg, tc.max, got, tc.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("result %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestBulkUpdateStatusSanitizesErrorMessage(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ErrorMessageMax = 10
+	svc, fake, _ := newTestService(t, cfg)
+	fake.On("FOR UPDATE", statusRows(map[string]string{"run-1": "started"}))
+
+	msg := "trace:\n\x1b[0mline one\nline two"
+	if _, err := svc.BulkUpdateStatus(context.Background(), []models.RunStatusUpdate{
+		{ID: "run-1", Status: "failed", ErrorMessage: &msg},
+	}); err != nil {
+		t.Fatalf("BulkUpdateStatus: %v", err)
+	}
+	updates := fake.Matching("UPDATE runs SET status")
+	if len(updates) != 1 {
+		t.Fatalf("status updates = %d, want 1", len(updates))
+	}
+	got, ok := updates[0].Args[1].(string)
+	if !ok || got != "trace: [0…" {
+		t.Errorf("persisted error_message = %v, want the sanitized, truncated message", updates[0].Args[1])
+	}
+}
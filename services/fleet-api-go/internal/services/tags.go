@@ -0,0 +1,50 @@
+package services
+
+// File: internal/services/tags.go
+// Purpose: Validation of run tags, shared by POST /runs and the GET /runs tag filter.
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	maxRunTags   = 16
+	maxTagLength = 64
+)
+
+// normalizeTags trims, validates, deduplicates and sorts tags. Tags are 1-64 of
+// [A-Za-z0-9:._-], so "exp:q3" and "owner:alice" style key:value labels work.
+func normalizeTags(raw []string) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return nil, invalidf("tags must not be empty")
+		}
+		if len(tag) > maxTagLength {
+			return nil, invalidf("tag %q must be at most %d characters", tag, maxTagLength)
+		}
+		if i := strings.IndexFunc(tag, func(c rune) bool { return !isTagChar(c) }); i >= 0 {
+			return nil, invalidf("tag %q has invalid character %q (allowed: letters, digits, ':', '.', '_', '-')", tag, tag[i])
+		}
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) > maxRunTags {
+		return nil, invalidf("too many tags: %d (max %d)", len(tags), maxRunTags)
+	}
+	return tags, nil
+}
+
+func isTagChar(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return c == ':' || c == '.' || c == '_' || c == '-'
+}
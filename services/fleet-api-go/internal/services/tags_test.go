@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"fleet-api-go/internal/models"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tooMany := make([]string, maxRunTags+1)
+	for i := range tooMany {
+		tooMany[i] = "t" + strings.Repeat("x", i+1)
+	}
+	for _, tc := range []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr string
+	}{
+		{"none", nil, nil, ""},
+		{"sorted and deduplicated", []string{"owner:alice", " exp:q3 ", "owner:alice"}, []string{"exp:q3", "owner:alice"}, ""},
+		{"empty tag", []string{"exp:q3", " "}, nil, "tags must not be empty"},
+		{"bad character", []string{"owner alice"}, nil, `invalid character ' '`},
+		{"too long", []string{strings.Repeat("a", maxTagLength+1)}, nil, "must be at most 64 characters"},
+		{"too many", tooMany, nil, "too many tags: 17 (max 16)"},
+		{"duplicates do not count", append(slices.Clone(tooMany[:maxRunTags]), tooMany[0]), nil, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := normalizeTags(tc.in)
+			if tc.wantErr != "" {
+				if !IsValidation(err) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("err = %v, want a validation error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeTags: %v", err)
+			}
+			if tc.want != nil && !slices.Equal(got, tc.want) {
+				t.Errorf("tags = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateRunStoresTagsWithTheRun(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+
+	resp, err := svc.CreateRun(context.Background(), models.CreateRunRequest{
+		Mode: "baseline",
+		Tags: []string{"owner:alice", "exp:q3"},
+	})
+	if err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	if !slices.Equal(resp.Tags, []string{"exp:q3", "owner:alice"}) {
+		t.Errorf("response tags = %v", resp.Tags)
+	}
+
+	var seq []string
+	for _, st := range fake.Statements() {
+		switch {
+		case st.Query == "BEGIN", st.Query == "COMMIT", st.Query == "ROLLBACK":
+			seq = append(seq, st.Query)
+		case strings.Contains(st.Query, "INSERT INTO runs"):
+			seq = append(seq, "runs")
+		case strings.Contains(st.Query, "INSERT INTO run_tags"):
+			seq = append(seq, "run_tags")
+			want := []any{resp.RunID, "exp:q3", resp.RunID, "owner:alice"}
+			if !slices.Equal(st.Args, want) {
+				t.Errorf("run_tags args = %v, want %v", st.Args, want)
+			}
+		}
+	}
+	if want := []string{"BEGIN", "runs", "run_tags", "COMMIT"}; !slices.Equal(seq, want) {
+		t.Errorf("statements = %v, want %v", seq, want)
+	}
+}
+
+func TestCreateRunWithoutTagsSkipsTheTransaction(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.CreateRun(context.Background(), models.CreateRunRequest{Mode: "baseline"}); err != nil {
+		t.Fatalf("CreateRun: %v", err)
+	}
+	if n := len(fake.Matching("BEGIN")) + len(fake.Matching("run_tags")); n != 0 {
+		t.Errorf("got %d BEGIN/run_tags statements for an untagged run", n)
+	}
+}
+
+func TestListRunsRejectsInvalidTag(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	_, err := svc.ListRuns(context.Background(), models.RunFilter{Tags: []string{"exp q3"}}, 50, 0)
+	if !IsValidation(err) {
+		t.Fatalf("err = %v, want a validation error", err)
+	}
+	if len(fake.Statements()) != 0 {
+		t.Errorf("statements = %+v, want none", fake.Statements())
+	}
+}
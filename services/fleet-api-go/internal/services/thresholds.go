@@ -0,0 +1,46 @@
+package services
+
+// File: internal/services/thresholds.go
+// Purpose: Pass/fail evaluation of run metrics against configured or per-request thresholds.
+
+import (
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/models"
+)
+
+// evaluateThresholds sets m.Passed and m.FailedCriteria. With no thresholds the
+// metrics are left unevaluated so the response carries neither field.
+func evaluateThresholds(m *models.RunMetrics, thresholds []config.MetricThreshold) {
+	if m == nil || len(thresholds) == 0 {
+		return
+	}
+	var failed []models.ThresholdFailure
+	for _, t := range thresholds {
+		actual := metricValue(m, t.Metric)
+		if actual == nil || !thresholdMet(*actual, t) {
+			f := models.ThresholdFailure{Criterion: t.String(), Metric: t.Metric, Threshold: t.Value}
+			if actual != nil {
+				f.Actual = *actual
+			}
+			failed = append(failed, f)
+		}
+	}
+	passed := len(failed) == 0
+	m.Passed = &passed
+	m.FailedCriteria = failed
+}
+
+func thresholdMet(actual float64, t config.MetricThreshold) bool {
+	switch t.Op {
+	case ">=":
+		return actual >= t.Value
+	case "<=":
+		return actual <= t.Value
+	case ">":
+		return actual > t.Value
+	case "<":
+		return actual < t.Value
+	default:
+		return false
+	}
+}
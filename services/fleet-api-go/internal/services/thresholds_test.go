@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/config"
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+func TestEvaluateThresholds(t *testing.T) {
+	metrics := func() *models.RunMetrics {
+		return &models.RunMetrics{OnTimeRate: 0.92, MaxLateness: 90, FailedJobs: 0, TotalJobs: 20}
+	}
+	for _, tc := range []struct {
+		name       string
+		spec       string
+		wantPassed bool
+		wantFailed []string
+	}{
+		{"all met", "on_time_rate>=0.9,max_lateness<=120,failed_jobs<1", true, nil},
+		{"boundary is inclusive", "on_time_rate>=0.92,max_lateness<=90", true, nil},
+		{"strict boundary fails", "max_lateness<90", false, []string{"max_lateness<90"}},
+		{"several failures", "on_time_rate>=0.95,total_jobs>20,failed_jobs<1", false, []string{"on_time_rate>=0.95", "total_jobs>20"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			thresholds, err := config.ParseThresholds(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := metrics()
+			evaluateThresholds(m, thresholds)
+			if m.Passed == nil || *m.Passed != tc.wantPassed {
+				t.Fatalf("passed = %v, want %v", m.Passed, tc.wantPassed)
+			}
+			var failed []string
+			for _, f := range m.FailedCriteria {
+				failed = append(failed, f.Criterion)
+			}
+			if strings.Join(failed, ",") != strings.Join(tc.wantFailed, ",") {
+				t.Errorf("failed criteria = %v, want %v", failed, tc.wantFailed)
+			}
+		})
+	}
+
+	m := metrics()
+	evaluateThresholds(m, []config.MetricThreshold{{Metric: "on_time_rate", Op: ">=", Value: 0.95}})
+	if f := m.FailedCriteria[0]; f.Metric != "on_time_rate" || f.Threshold != 0.95 || f.Actual != 0.92 {
+		t.Errorf("failure = %+v, want metric, threshold and actual value", f)
+	}
+
+	m = metrics()
+	evaluateThresholds(m, nil)
+	if m.Passed != nil || m.FailedCriteria != nil {
+		t.Errorf("no thresholds evaluated to passed=%v %v, want both unset", m.Passed, m.FailedCriteria)
+	}
+}
+
+func TestGetMetricsThresholds(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.MetricThresholds = []config.MetricThreshold{{Metric: "on_time_rate", Op: ">=", Value: 0.9}}
+	svc, fake, _ := newTestService(t, cfg)
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.8, 200.0, 30.0, 5.0, 16, 4, 20, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), "completed"},
+	}})
+	ctx := context.Background()
+
+	m, err := svc.GetMetrics(ctx, "run-1", "")
+	if err != nil {
+		t.Fatalf("GetMetrics: %v", err)
+	}
+	if m.Passed == nil || *m.Passed || len(m.FailedCriteria) != 1 {
+		t.Errorf("configured thresholds: passed=%v failed=%+v, want a failing on_time_rate", m.Passed, m.FailedCriteria)
+	}
+
+	// A per-request spec replaces the configured thresholds rather than adding to them.
+	m, err = svc.GetMetrics(ctx, "run-1", "on_time_rate>=0.5,failed_jobs<=4")
+	if err != nil {
+		t.Fatalf("GetMetrics: %v", err)
+	}
+	if m.Passed == nil || !*m.Passed || len(m.FailedCriteria) != 0 {
+		t.Errorf("request thresholds: passed=%v failed=%+v, want passed", m.Passed, m.FailedCriteria)
+	}
+
+	if _, err := svc.GetMetrics(ctx, "run-1", "speed>=1"); !IsValidation(err) {
+		t.Errorf("invalid spec err = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+// File: internal/services/timeline.go
+// Purpose: Assemble a run's lifecycle into one chronological timeline.
+
+import (
+	"context"
+	"sort"
+
+	"fleet-api-go/internal/models"
+)
+
+// timelineEventLimit caps how many run_events rows are merged into a timeline.
+const timelineEventLimit = 500
+
+// Timeline returns the lifecycle of an existing run — creation, start, metrics
+// computation, terminal status — merged with its stored event history, oldest first.
+func (s *RunService) Timeline(ctx context.Context, runID string) (*models.RunTimelineResponse, error) {
+	run, err := s.store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, ErrRunNotFound
+	}
+	metrics, err := s.store.GetRunMetrics(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.store.ListRunEvents(ctx, runID, timelineEventLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RunTimelineResponse{
+		RunID:  run.ID,
+		Status: run.Status,
+		Events: buildTimeline(*run, metrics, events),
+	}, nil
+}
+
+// buildTimeline derives entries from the run row and metrics, then merges the
+// event history. Entries at the same instant keep that order (run, metrics, events).
+func buildTimeline(run models.Run, metrics *models.RunMetrics, events []models.RunEventRecord) []models.TimelineEntry {
+	entries := []models.TimelineEntry{
+		{At: run.CreatedAt, Type: "created", Source: "run"},
+	}
+	if run.StartedAt != nil {
+		entries = append(entries, models.TimelineEntry{At: *run.StartedAt, Type: "started", Source: "run"})
+	}
+	if metrics != nil && !metrics.ComputedAt.IsZero() {
+		entries = append(entries, models.TimelineEntry{At: metrics.ComputedAt, Type: "metrics_computed", Source: "metrics"})
+	}
+	if run.CompletedAt != nil {
+		entry := models.TimelineEntry{At: *run.CompletedAt, Type: run.Status, Source: "run"}
+		if run.ErrorMessage != nil {
+			entry.Detail = *run.ErrorMessage
+		}
+		entries = append(entries, entry)
+	}
+	for _, e := range events {
+		entries = append(entries, models.TimelineEntry{At: e.CreatedAt, Type: e.EventType, Source: "event", Detail: e.RoutingKey})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.Before(entries[j].At)
+	})
+	return entries
+}
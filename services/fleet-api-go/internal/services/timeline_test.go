@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"fleet-api-go/internal/db/dbtest"
+	"fleet-api-go/internal/models"
+)
+
+var timelineStart = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// timelineAt returns the timeline start plus s seconds.
+func timelineAt(s int) time.Time { return timelineStart.Add(time.Duration(s) * time.Second) }
+
+// timelineTypes flattens entries to "source:type" for comparison.
+func timelineTypes(entries []models.TimelineEntry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Source + ":" + e.Type
+	}
+	return out
+}
+
+func TestBuildTimelineCompletedRun(t *testing.T) {
+	run := models.Run{CreatedAt: timelineAt(0), StartedAt: ptr(timelineAt(0)), CompletedAt: ptr(timelineAt(60)), Status: "completed"}
+	metrics := &models.RunMetrics{ComputedAt: timelineAt(60)}
+	events := []models.RunEventRecord{
+		{EventType: "run.created", RoutingKey: "run.created", CreatedAt: timelineAt(0)},
+		{EventType: "run.progress", RoutingKey: "run.progress", CreatedAt: timelineAt(30)},
+		{EventType: "run.completed", RoutingKey: "run.completed", CreatedAt: timelineAt(61)},
+	}
+
+	got := buildTimeline(run, metrics, events)
+	// Entries at the same instant keep run, metrics, events order.
+	want := []string{
+		"run:created", "run:started", "event:run.created",
+		"event:run.progress",
+		"metrics:metrics_computed", "run:completed",
+		"event:run.completed",
+	}
+	if types := timelineTypes(got); !reflect.DeepEqual(types, want) {
+		t.Errorf("timeline = %v, want %v", types, want)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].At.Before(got[i-1].At) {
+			t.Errorf("entry %d (%s) is before entry %d", i, got[i].Type, i-1)
+		}
+	}
+	if got[3].Detail != "run.progress" {
+		t.Errorf("event detail = %q, want the routing key", got[3].Detail)
+	}
+}
+
+func TestBuildTimelineIncompleteRun(t *testing.T) {
+	// Still started: no metrics and no terminal entry yet.
+	run := models.Run{CreatedAt: timelineAt(0), StartedAt: ptr(timelineAt(1)), Status: "started"}
+	got := buildTimeline(run, nil, nil)
+	if types := timelineTypes(got); !reflect.DeepEqual(types, []string{"run:created", "run:started"}) {
+		t.Errorf("started run timeline = %v", types)
+	}
+
+	// Zero-valued metrics (not computed yet) add no entry.
+	pending := models.Run{CreatedAt: timelineAt(0), Status: "pending"}
+	if types := timelineTypes(buildTimeline(pending, &models.RunMetrics{}, nil)); !reflect.DeepEqual(types, []string{"run:created"}) {
+		t.Errorf("pending run timeline = %v", types)
+	}
+}
+
+func TestBuildTimelineFailedRunCarriesTheError(t *testing.T) {
+	run := models.Run{CreatedAt: timelineAt(0), StartedAt: ptr(timelineAt(0)), CompletedAt: ptr(timelineAt(5)), Status: "failed", ErrorMessage: ptr("robot 3 lost")}
+	got := buildTimeline(run, nil, nil)
+	last := got[len(got)-1]
+	if last.Type != "failed" || last.Detail != "robot 3 lost" || !last.At.Equal(timelineAt(5)) {
+		t.Errorf("last entry = %+v, want the failure with its message", last)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	fake.Return("FROM runs WHERE id = ?", dbtest.Result{Rows: [][]any{
+		{"run-1", "ga", 42, "demo", nil, nil, "hash", 2, "completed", nil, timelineAt(0), timelineAt(1), timelineAt(60), nil, nil, nil, nil, nil},
+	}})
+	fake.Return("FROM run_metrics rm", dbtest.Result{Rows: [][]any{
+		{"run-1", 0.9, 100.0, 30.0, 5.0, 9, 1, 10, timelineAt(59), "completed"},
+	}})
+	fake.Return("FROM run_events", dbtest.Result{Rows: [][]any{
+		{int64(1), "run-1", "run.started", "run.started", []byte(`{}`), timelineAt(1)},
+	}})
+
+	resp, err := svc.Timeline(context.Background(), "run-1")
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	want := []string{"run:created", "run:started", "event:run.started", "metrics:metrics_computed", "run:completed"}
+	if resp.RunID != "run-1" || resp.Status != "completed" || !reflect.DeepEqual(timelineTypes(resp.Events), want) {
+		t.Errorf("resp = %s %s %v, want %v", resp.RunID, resp.Status, timelineTypes(resp.Events), want)
+	}
+	if reads := fake.Matching("FROM run_events"); len(reads) != 1 || reads[0].Args[2] != int64(timelineEventLimit) {
+		t.Errorf("event reads = %+v, want one capped at %d", reads, timelineEventLimit)
+	}
+}
+
+func TestTimelineUnknownRun(t *testing.T) {
+	svc, fake, _ := newTestService(t, testConfig(t))
+	if _, err := svc.Timeline(context.Background(), "missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("err = %v, want ErrRunNotFound", err)
+	}
+	if n := len(fake.Matching("run_events")); n != 0 {
+		t.Errorf("read events %d times for an unknown run", n)
+	}
+}
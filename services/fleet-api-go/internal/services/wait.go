@@ -0,0 +1,66 @@
+package services
+
+// File: internal/services/wait.go
+// Purpose: Create-and-wait for synchronous clients (POST /runs/wait), polling the run's status.
+
+import (
+	"context"
+	"time"
+
+	"fleet-api-go/internal/models"
+)
+
+// runWaitPollInterval is how often CreateRunAndWait re-reads the run's status.
+const runWaitPollInterval = 500 * time.Millisecond
+
+// RunWaitTimeout resolves a requested wait: zero uses the default (60s, or RUN_WAIT_MAX_S
+// if lower) and longer requests are capped at RUN_WAIT_MAX_S.
+func (s *RunService) RunWaitTimeout(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		requested = time.Minute
+	}
+	return min(requested, s.cfg.RunWaitMax)
+}
+
+// CreateRunAndWait creates a run like CreateRun, then polls until it reaches a terminal
+// status or timeout elapses. Terminal runs come back with the run and its metrics (nil
+// if the simulator reported none); otherwise TimedOut is set and the run keeps going.
+// Once the run exists, a failed read returns the response so far together with the
+// error, so callers can still hand out the run ID.
+func (s *RunService) CreateRunAndWait(ctx context.Context, req models.CreateRunRequest, timeout time.Duration) (*models.RunWaitResponse, error) {
+	created, err := s.createRun(ctx, "run.create", req, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp := &models.RunWaitResponse{RunID: created.RunID, Status: created.Status}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(runWaitPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			resp.TimedOut = true
+			return resp, nil
+		case <-ticker.C:
+		}
+		st, err := s.store.GetRunStatus(ctx, created.RunID)
+		if err != nil {
+			return resp, err
+		}
+		if st == nil || !isTerminalStatus(st.Status) {
+			continue
+		}
+		resp.Status = st.Status
+		if resp.Run, err = s.GetRun(ctx, created.RunID); err != nil {
+			return resp, err
+		}
+		if resp.Metrics, err = s.GetMetrics(ctx, created.RunID, ""); err != nil {
+			return resp, err
+		}
+		return resp, nil
+	}
+}
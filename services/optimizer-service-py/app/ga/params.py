@@ -0,0 +1,25 @@
+from __future__ import annotations
+
+"""
+File: services/optimizer-service-py/app/ga/params.py
+Purpose: Per-run GA parameter resolution.
+Key responsibilities:
+- Overlay a run's ga_params (from fleet-api, via dispatcher-worker) on the env defaults.
+- Keep the elite smaller than the population when only one of them was overridden.
+"""
+
+from typing import Any, Mapping
+
+GA_PARAM_NAMES = ("population_size", "generations", "elite_size", "mutation_rate", "crossover_rate")
+
+
+def resolve_ga_params(overrides: Mapping[str, Any] | None, defaults: Any) -> dict[str, Any]:
+    """Return optimize_assignments GA keyword arguments: overrides where set, else defaults' attributes."""
+    params = {name: getattr(defaults, name) for name in GA_PARAM_NAMES}
+    for name, value in (overrides or {}).items():
+        if name in params and value is not None:
+            params[name] = value
+    # fleet-api only checks elite < population when both are sent, so a lone override
+    # can meet the other's default; leave at least one slot for offspring.
+    params["elite_size"] = min(params["elite_size"], params["population_size"] - 1)
+    return params
@@ -5,7 +5,8 @@ File: services/optimizer-service-py/app/main.py
 Purpose: FastAPI entrypoint for the deterministic GA optimizer.
 Key responsibilities:
 - Expose /health and /optimize endpoints.
-- Delegate optimization to GA engine with env-configured parameters.
+- Delegate optimization to GA engine with env-configured parameters, overridden
+  per run by the request's ga_params.
 Key entrypoints:
 - health()
 - optimize()
@@ -19,6 +20,7 @@ Config/env vars:
 from fastapi import FastAPI
 
 from app.ga.optimizer import optimize_assignments
+from app.ga.params import resolve_ga_params
 from app.schemas import OptimizeRequest, OptimizeResponse
 from app.settings import settings
 
@@ -34,15 +36,12 @@ def health() -> dict[str, str]:
 @app.post("/optimize", response_model=OptimizeResponse)
 def optimize(req: OptimizeRequest) -> OptimizeResponse:
     """Run deterministic GA optimization and return assignments + metadata."""
+    overrides = req.ga_params.model_dump(exclude_none=True) if req.ga_params else None
     assignments, meta = optimize_assignments(
         robots=req.robots,
         jobs=req.pending_jobs,
         seed=req.seed,
         service_time_s=settings.service_time_s,
-        population_size=settings.population_size,
-        generations=settings.generations,
-        elite_size=settings.elite_size,
-        crossover_rate=settings.crossover_rate,
-        mutation_rate=settings.mutation_rate,
+        **resolve_ga_params(overrides, settings),
     )
     return OptimizeResponse(assignments=assignments, meta=meta)
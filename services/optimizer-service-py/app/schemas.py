@@ -7,7 +7,7 @@ Key responsibilities:
 - Validate incoming /optimize payloads.
 - Define assignment and metadata schema.
 Key entrypoints:
-- OptimizeRequest, OptimizeResponse
+- GAParams, OptimizeRequest, OptimizeResponse
 """
 
 from pydantic import BaseModel, Field
@@ -41,6 +41,15 @@ class Job(BaseModel):
     state: JobState = "pending"
 
 
+class GAParams(BaseModel):
+    """Per-run GA settings (a run's ga_params); unset fields keep the env defaults."""
+    population_size: Optional[int] = Field(default=None, ge=2, le=1000)
+    generations: Optional[int] = Field(default=None, ge=1, le=1000)
+    elite_size: Optional[int] = Field(default=None, ge=0)
+    mutation_rate: Optional[float] = Field(default=None, ge=0, le=1)
+    crossover_rate: Optional[float] = Field(default=None, ge=0, le=1)
+
+
 class OptimizeRequest(BaseModel):
     """Request body for /optimize."""
     run_id: str
@@ -50,6 +59,7 @@ class OptimizeRequest(BaseModel):
     sim_time_s: int = 0
     robots: list[Robot]
     pending_jobs: list[Job]
+    ga_params: Optional[GAParams] = None
 
 
 class Assignment(BaseModel):
@@ -0,0 +1,31 @@
+from types import SimpleNamespace
+
+from app.ga.params import resolve_ga_params
+
+DEFAULTS = SimpleNamespace(population_size=64, generations=80, elite_size=4, mutation_rate=0.1, crossover_rate=0.9)
+
+
+def test_ga_params_default_to_settings():
+    assert resolve_ga_params(None, DEFAULTS) == {
+        "population_size": 64,
+        "generations": 80,
+        "elite_size": 4,
+        "mutation_rate": 0.1,
+        "crossover_rate": 0.9,
+    }
+
+
+def test_ga_params_override_set_fields_only():
+    params = resolve_ga_params({"generations": 100, "mutation_rate": 0.05, "elite_size": None}, DEFAULTS)
+    assert params["generations"] == 100
+    assert params["mutation_rate"] == 0.05
+    assert params["elite_size"] == 4
+    assert params["population_size"] == 64
+
+
+def test_ga_params_ignore_unknown_keys():
+    assert "seed" not in resolve_ga_params({"seed": 7}, DEFAULTS)
+
+
+def test_ga_params_keep_elite_below_population():
+    assert resolve_ga_params({"population_size": 3}, DEFAULTS)["elite_size"] == 2
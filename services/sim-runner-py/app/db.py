@@ -5,7 +5,7 @@ File: services/sim-runner-py/app/db.py
 Purpose: MySQL helper functions for simulation persistence.
 Key responsibilities:
 - Update scenario hash and run status.
-- Upsert jobs, insert telemetry, insert metrics.
+- Upsert jobs, insert telemetry, insert metrics (and their history).
 """
 
 from contextlib import contextmanager
@@ -105,8 +105,27 @@ def insert_telemetry_batch(run_id: str, rows: Iterable[dict]) -> None:
         )
 
 
-def insert_metrics(run_id: str, metrics: dict) -> None:
-    """Insert or update run metrics."""
+_METRICS_HISTORY_INSERT = """
+    INSERT INTO run_metrics_history (run_id, sim_time_s, on_time_rate, total_distance, avg_completion_time, max_lateness, completed_jobs, failed_jobs, total_jobs)
+    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+"""
+
+
+def _metrics_values(metrics: dict) -> tuple:
+    """Metric columns in run_metrics order."""
+    return (
+        float(metrics["on_time_rate"]),
+        float(metrics["total_distance"]),
+        float(metrics["avg_completion_time"]),
+        float(metrics["max_lateness"]),
+        int(metrics["completed_jobs"]),
+        int(metrics["failed_jobs"]),
+        int(metrics["total_jobs"]),
+    )
+
+
+def insert_metrics(run_id: str, metrics: dict, sim_time_s: int | None = None) -> None:
+    """Insert or update run metrics and append them to the run's metrics history."""
     with db_cursor() as cur:
         cur.execute(
             """
@@ -121,23 +140,36 @@ def insert_metrics(run_id: str, metrics: dict) -> None:
                 failed_jobs=VALUES(failed_jobs),
                 total_jobs=VALUES(total_jobs)
             """,
-            (
-                run_id,
-                float(metrics["on_time_rate"]),
-                float(metrics["total_distance"]),
-                float(metrics["avg_completion_time"]),
-                float(metrics["max_lateness"]),
-                int(metrics["completed_jobs"]),
-                int(metrics["failed_jobs"]),
-                int(metrics["total_jobs"]),
-            ),
+            (run_id, *_metrics_values(metrics)),
+        )
+        cur.execute(_METRICS_HISTORY_INSERT, (run_id, sim_time_s, *_metrics_values(metrics)))
+
+
+def insert_metrics_snapshot(run_id: str, metrics: dict, sim_time_s: int) -> None:
+    """Append an in-progress metrics snapshot to run_metrics_history (run_metrics is left alone)."""
+    with db_cursor() as cur:
+        cur.execute(_METRICS_HISTORY_INSERT, (run_id, sim_time_s, *_metrics_values(metrics)))
+
+
+def update_run_progress(run_id: str, progress_pct: float) -> None:
+    """Persist the run's progress percentage while it is still running."""
+    with db_cursor() as cur:
+        cur.execute(
+            "UPDATE runs SET progress_pct=%s WHERE id=%s AND status='started'",
+            (progress_pct, run_id),
         )
 
 
 def complete_run(run_id: str, status: str, error_message: str | None = None) -> None:
-    """Mark a run completed or failed and persist error details if any."""
+    """Mark a run completed or failed and persist error details if any.
+
+    A run cancelled through fleet-api keeps its cancelled status. Completed runs
+    report 100% progress; failed runs keep the last progress reported.
+    """
     with db_cursor() as cur:
         cur.execute(
-            "UPDATE runs SET status=%s, error_message=%s, completed_at=UTC_TIMESTAMP() WHERE id=%s",
-            (status, error_message, run_id),
+            "UPDATE runs SET status=%s, error_message=%s, completed_at=UTC_TIMESTAMP(), "
+            "progress_pct=IF(%s = 'completed', 100, progress_pct) "
+            "WHERE id=%s AND status <> 'cancelled'",
+            (status, error_message, status, run_id),
         )
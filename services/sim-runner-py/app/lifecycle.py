@@ -0,0 +1,36 @@
+from __future__ import annotations
+
+"""
+File: services/sim-runner-py/app/lifecycle.py
+Purpose: Run lifecycle event rules (run.created vs run.started, run.cancelled) for sim-runner.
+Key responsibilities:
+- Recognize sim-runner's own run.started events when they echo back on its queue.
+- Decide whether sim-runner publishes run.started when a simulation begins.
+- Identify which simulation a run.cancelled event stops.
+"""
+
+from typing import Any
+
+EVENT_SOURCE = "sim-runner"
+
+
+def is_own_event(event: dict[str, Any]) -> bool:
+    """Return True for events this service published (its own run.started echoes back)."""
+    return event.get("source") == EVENT_SOURCE
+
+
+def should_announce_start(routing_key: str, event: dict[str, Any]) -> bool:
+    """Return True when sim-runner must publish run.started itself.
+
+    That is the case for run.created unless fleet-api already published the legacy
+    run.started alias for the same run (signalled by run_started_alias).
+    """
+    return routing_key == "run.created" and not bool(event.get("run_started_alias", False))
+
+
+def cancelled_run_id(event: dict[str, Any]) -> str | None:
+    """Return the run a run.cancelled event targets, or None when the event is unusable."""
+    if event.get("event_type") not in (None, "run.cancelled"):
+        return None
+    run_id = str(event.get("run_id") or "")
+    return run_id or None
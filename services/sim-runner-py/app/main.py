@@ -2,10 +2,12 @@ from __future__ import annotations
 
 """
 File: services/sim-runner-py/app/main.py
-Purpose: Simulation runner that consumes run.started and executes deterministic runs.
+Purpose: Simulation runner that consumes run.created and executes deterministic runs.
 Key responsibilities:
+- Publish run.started when a simulation actually begins (unless fleet-api sent the legacy alias).
 - Generate deterministic scenarios and publish job/robot events.
 - Apply job assignments and emit snapshots/telemetry.
+- Stop in-flight simulations on run.cancelled.
 - Persist jobs, telemetry, and metrics to MySQL.
 Key entrypoints:
 - SimRunner.run()
@@ -26,11 +28,12 @@ from typing import Any
 import aio_pika
 
 from app import db
+from app.lifecycle import EVENT_SOURCE, cancelled_run_id, is_own_event, should_announce_start
 from app.mq import connect, publish_event, setup_topology
 from app.settings import rabbit_url, settings
 from app.sim.engine import Assignment, SimulationEngine
 from app.sim.entities import SimulationState
-from app.sim.metrics import compute_metrics
+from app.sim.metrics import compute_metrics, progress_pct
 from app.sim.world import generate_scenario
 
 logging.basicConfig(level=logging.INFO, format="%(asctime)s %(levelname)s sim-runner %(message)s")
@@ -50,34 +53,40 @@ class SimRunner:
         channel = await connection.channel()
         await channel.set_qos(prefetch_count=200)
 
-        exchange, q_run_started, q_job_assigned = await setup_topology(channel, settings.exchange_name)
+        exchange, q_run_started, q_job_assigned, q_run_cancelled = await setup_topology(channel, settings.exchange_name)
         self.exchange = exchange
 
-        await q_run_started.consume(self._on_run_started)
+        await q_run_started.consume(self._on_run_requested)
         await q_job_assigned.consume(self._on_job_assigned)
+        await q_run_cancelled.consume(self._on_run_cancelled)
 
         logger.info("sim-runner started")
         await asyncio.Future()
 
-    async def _on_run_started(self, message: aio_pika.IncomingMessage) -> None:
-        """Handle run.started by creating a simulation task."""
+    async def _on_run_requested(self, message: aio_pika.IncomingMessage) -> None:
+        """Handle run.created (or the legacy run.started alias) by creating a simulation task."""
+        routing_key = message.routing_key or ""
         try:
             event = json.loads(message.body.decode("utf-8"))
+            if is_own_event(event):
+                return
             run_id = str(event.get("run_id", ""))
             if not run_id:
-                logger.warning("run.started missing run_id")
+                logger.warning("%s missing run_id", routing_key)
                 return
             if run_id in self.run_tasks:
-                logger.warning("run already active run_id=%s", run_id)
+                # Expected while fleet-api publishes both run.created and the run.started alias.
+                logger.info("run already active run_id=%s routing_key=%s", run_id, routing_key)
                 return
 
             queue: asyncio.Queue[dict[str, Any]] = asyncio.Queue()
             self.assignment_queues[run_id] = queue
-            task = asyncio.create_task(self._simulate_run(event, queue))
+            announce = should_announce_start(routing_key, event)
+            task = asyncio.create_task(self._simulate_run(event, queue, announce_start=announce))
             self.run_tasks[run_id] = task
             task.add_done_callback(lambda _task, rid=run_id: self._cleanup_run(rid))
         except Exception as exc:  # noqa: BLE001
-            logger.exception("run.started handler error: %s", exc)
+            logger.exception("%s handler error: %s", routing_key, exc)
         finally:
             await message.ack()
 
@@ -95,12 +104,36 @@ class SimRunner:
         finally:
             await message.ack()
 
+    async def _on_run_cancelled(self, message: aio_pika.IncomingMessage) -> None:
+        """Stop the simulation task for a run cancelled through fleet-api."""
+        try:
+            event = json.loads(message.body.decode("utf-8"))
+            run_id = cancelled_run_id(event)
+            if run_id is None:
+                logger.warning("run.cancelled missing run_id")
+                return
+            task = self.run_tasks.get(run_id)
+            if task is None:
+                logger.info("run.cancelled for inactive run run_id=%s", run_id)
+                return
+            logger.info("cancelling run run_id=%s reason=%s", run_id, event.get("reason"))
+            task.cancel()
+        except Exception as exc:  # noqa: BLE001
+            logger.exception("run.cancelled handler error: %s", exc)
+        finally:
+            await message.ack()
+
     def _cleanup_run(self, run_id: str) -> None:
         """Remove run state when a simulation completes."""
         self.assignment_queues.pop(run_id, None)
         self.run_tasks.pop(run_id, None)
 
-    async def _simulate_run(self, event: dict[str, Any], assignment_queue: asyncio.Queue[dict[str, Any]]) -> None:
+    async def _simulate_run(
+        self,
+        event: dict[str, Any],
+        assignment_queue: asyncio.Queue[dict[str, Any]],
+        announce_start: bool = False,
+    ) -> None:
         """Run a deterministic simulation loop for a single run."""
         run_id = str(event["run_id"])
         mode = str(event.get("mode", settings.fleet_mode))
@@ -134,6 +167,27 @@ class SimRunner:
 
             state = SimulationState(run_id=run_id, mode=mode, seed=seed, scale=scale, robots=robots, jobs=jobs)
 
+            if announce_start:
+                started_payload: dict[str, Any] = {
+                    "event_id": self._event_id(run_id, "run.started", "run", 0),
+                    "event_type": "run.started",
+                    "run_id": run_id,
+                    "mode": mode,
+                    "seed": seed,
+                    "scale": scale,
+                    "sim_time_s": 0,
+                    "source": EVENT_SOURCE,
+                    "ts_utc": datetime.now(timezone.utc).isoformat(),
+                }
+                if robots_override is not None and jobs_override is not None:
+                    started_payload["robots"] = robots_override
+                    started_payload["jobs"] = jobs_override
+                if event.get("replan_interval_s") is not None:
+                    started_payload["replan_interval_s"] = int(event["replan_interval_s"])
+                if isinstance(event.get("ga_params"), dict):
+                    started_payload["ga_params"] = event["ga_params"]
+                await publish_event(self.exchange, "run.started", started_payload)
+
             robot_events: list[dict[str, Any]] = []
 
             def robot_update_sink(payload: dict[str, Any]) -> None:
@@ -178,6 +232,7 @@ class SimRunner:
 
             last_telemetry_sim_s = -1
             previous_job_states = {job.id: job.state for job in jobs}
+            last_progress = -1.0
 
             while not engine.should_stop():
                 current_sim_time_s = engine.current_sim_time_s()
@@ -263,6 +318,13 @@ class SimRunner:
                                 },
                             )
 
+                progress = progress_pct(jobs)
+                if progress != last_progress:
+                    db.update_run_progress(run_id, progress)
+                    # Snapshot metrics when a job finishes (and on the first tick) rather than every tick.
+                    db.insert_metrics_snapshot(run_id, compute_metrics(jobs, robots), int(sim_time_s))
+                    last_progress = progress
+
                 await asyncio.sleep(1.0 / settings.sim_tick_hz)
 
             engine.finalize()
@@ -288,7 +350,7 @@ class SimRunner:
                     )
 
             metrics = compute_metrics(jobs, robots)
-            db.insert_metrics(run_id, metrics)
+            db.insert_metrics(run_id, metrics, int(engine.current_sim_time_s()))
             db.complete_run(run_id, "completed")
 
             logger.info("run completed run_id=%s metrics=%s", run_id, metrics)
@@ -308,6 +370,10 @@ class SimRunner:
                     "ts_utc": datetime.now(timezone.utc).isoformat(),
                 },
             )
+        except asyncio.CancelledError:
+            # fleet-api already marked the run cancelled; publish nothing further for it.
+            logger.info("run cancelled run_id=%s", run_id)
+            raise
         except Exception as exc:  # noqa: BLE001
             logger.exception("run failed run_id=%s err=%s", run_id, exc)
             db.complete_run(run_id, "failed", error_message=str(exc))
@@ -26,10 +26,15 @@ async def setup_topology(channel: aio_pika.abc.AbstractRobustChannel, exchange_n
 
     queue_run_started = await channel.declare_queue("sim_runner.run_started", durable=True)
     queue_job_assigned = await channel.declare_queue("sim_runner.job_assigned", durable=True)
+    queue_run_cancelled = await channel.declare_queue("sim_runner.run_cancelled", durable=True)
 
+    # run.created triggers a simulation; run.started is still bound for fleet-api
+    # releases that publish it at creation (compatibility alias).
+    await queue_run_started.bind(exchange, routing_key="run.created")
     await queue_run_started.bind(exchange, routing_key="run.started")
     await queue_job_assigned.bind(exchange, routing_key="job.assigned")
-    return exchange, queue_run_started, queue_job_assigned
+    await queue_run_cancelled.bind(exchange, routing_key="run.cancelled")
+    return exchange, queue_run_started, queue_job_assigned, queue_run_cancelled
 
 
 async def publish_event(exchange: aio_pika.abc.AbstractExchange, routing_key: str, payload: dict[str, Any]) -> None:
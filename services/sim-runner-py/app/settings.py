@@ -3,10 +3,11 @@ File: services/sim-runner-py/app/settings.py
 Purpose: Environment-backed configuration for sim-runner.
 Key responsibilities:
 - Parse RabbitMQ/MySQL settings.
-- Define scale presets and simulation parameters.
+- Define scale presets (built-in plus CUSTOM_SCALES) and simulation parameters.
 """
 
 from dataclasses import dataclass
+import json
 import os
 
 
@@ -26,14 +27,57 @@ def _int_env(name: str, default: int = 0) -> int:
     return int(raw)
 
 
+def _bool_env(name: str, default: bool = False) -> bool:
+    """Parse a boolean env var (true/1/yes) with a fallback."""
+    raw = os.getenv(name, "")
+    if raw == "":
+        return default
+    return raw.strip().lower() in ("1", "true", "yes")
+
+
+def _parse_custom_scales(raw: str) -> dict[str, dict[str, int]]:
+    """Parse CUSTOM_SCALES, a JSON object of name -> {"robots": n, "jobs": n}."""
+    if raw.strip() == "":
+        return {}
+    entries = json.loads(raw)
+    if not isinstance(entries, dict):
+        raise ValueError("invalid CUSTOM_SCALES: must be a JSON object of scale to {robots, jobs}")
+    scales: dict[str, dict[str, int]] = {}
+    for name, size in entries.items():
+        key = name.strip().lower()
+        if key == "":
+            raise ValueError("invalid CUSTOM_SCALES: empty scale name")
+        if len(key) > 32:
+            raise ValueError(f"invalid CUSTOM_SCALES: scale {name!r} is longer than 32 characters")
+        if not isinstance(size, dict):
+            raise ValueError(f"invalid CUSTOM_SCALES: scale {name!r} must be an object with robots and jobs")
+        robots = size.get("robots")
+        jobs = size.get("jobs")
+        if any(not isinstance(v, int) or isinstance(v, bool) or v <= 0 for v in (robots, jobs)):
+            raise ValueError(f"invalid CUSTOM_SCALES: scale {name!r} needs positive integer robots and jobs")
+        scales[key] = {"robots": robots, "jobs": jobs}
+    return scales
+
+
 def _build_scale_map() -> dict[str, dict[str, int]]:
-    """Return the scale map with optional global overrides."""
+    """Return the scale map with optional global overrides and CUSTOM_SCALES.
+
+    Custom scales are applied after FLEET_ROBOTS/FLEET_JOBS so explicit sizes win. Redefining
+    a built-in scale raises unless ALLOW_SCALE_OVERRIDE is set, mirroring fleet-api-go.
+    """
     scale_map = {key: value.copy() for key, value in DEFAULT_SCALE_MAP.items()}
     robots = _int_env("FLEET_ROBOTS", 0)
     jobs = _int_env("FLEET_JOBS", 0)
     if robots > 0 and jobs > 0:
         for key in scale_map:
             scale_map[key] = {"robots": robots, "jobs": jobs}
+    allow_override = _bool_env("ALLOW_SCALE_OVERRIDE", False)
+    for key, size in _parse_custom_scales(os.getenv("CUSTOM_SCALES", "")).items():
+        if key in DEFAULT_SCALE_MAP and not allow_override:
+            raise ValueError(
+                f"invalid CUSTOM_SCALES: {key!r} shadows a built-in scale (set ALLOW_SCALE_OVERRIDE=true to redefine it)"
+            )
+        scale_map[key] = size
     return scale_map
 
 
@@ -5,6 +5,7 @@ File: services/sim-runner-py/app/sim/metrics.py
 Purpose: Compute aggregate run metrics from job and robot state.
 Key responsibilities:
 - On-time rate, distance, completion time, lateness totals.
+- Run progress (share of jobs finished) while the simulation runs.
 """
 
 from app.sim.entities import Job, Robot
@@ -35,3 +36,11 @@ def compute_metrics(jobs: list[Job], robots: list[Robot]) -> dict[str, float | i
         "failed_jobs": failed_jobs,
         "total_jobs": total_jobs,
     }
+
+
+def progress_pct(jobs: list[Job]) -> float:
+    """Return the percentage (0-100) of jobs that reached a terminal state."""
+    if not jobs:
+        return 100.0
+    finished = sum(1 for j in jobs if j.state in {"completed", "failed"})
+    return round(finished / len(jobs) * 100.0, 2)
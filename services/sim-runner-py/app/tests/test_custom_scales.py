@@ -0,0 +1,30 @@
+import pytest
+
+from app.settings import _build_scale_map
+
+
+def test_custom_scale_added(monkeypatch):
+    monkeypatch.setenv("CUSTOM_SCALES", '{"XL": {"robots": 40, "jobs": 200}}')
+    monkeypatch.delenv("ALLOW_SCALE_OVERRIDE", raising=False)
+    scale_map = _build_scale_map()
+    assert scale_map["xl"] == {"robots": 40, "jobs": 200}
+    assert scale_map["demo"] == {"robots": 10, "jobs": 50}
+
+
+def test_custom_scale_shadowing_builtin_rejected(monkeypatch):
+    monkeypatch.setenv("CUSTOM_SCALES", '{"demo": {"robots": 3, "jobs": 9}}')
+    monkeypatch.delenv("ALLOW_SCALE_OVERRIDE", raising=False)
+    with pytest.raises(ValueError, match="shadows a built-in scale"):
+        _build_scale_map()
+
+
+def test_custom_scale_shadowing_builtin_allowed(monkeypatch):
+    monkeypatch.setenv("CUSTOM_SCALES", '{"demo": {"robots": 3, "jobs": 9}}')
+    monkeypatch.setenv("ALLOW_SCALE_OVERRIDE", "true")
+    assert _build_scale_map()["demo"] == {"robots": 3, "jobs": 9}
+
+
+def test_custom_scale_requires_positive_sizes(monkeypatch):
+    monkeypatch.setenv("CUSTOM_SCALES", '{"xl": {"robots": 0, "jobs": 10}}')
+    with pytest.raises(ValueError, match="positive integer"):
+        _build_scale_map()
@@ -0,0 +1,62 @@
+from contextlib import contextmanager
+
+from app import db
+
+
+class RecordingCursor:
+    def __init__(self):
+        self.statements = []
+
+    def execute(self, query, args):
+        self.statements.append((" ".join(query.split()), args))
+
+
+def _metrics(on_time_rate, completed_jobs):
+    return {
+        "on_time_rate": on_time_rate,
+        "total_distance": 120.5,
+        "avg_completion_time": 30.0,
+        "max_lateness": 4.0,
+        "completed_jobs": completed_jobs,
+        "failed_jobs": 0,
+        "total_jobs": 10,
+    }
+
+
+def _record(monkeypatch):
+    cur = RecordingCursor()
+
+    @contextmanager
+    def fake_cursor():
+        yield cur
+
+    monkeypatch.setattr(db, "db_cursor", fake_cursor)
+    return cur
+
+
+def _history(cur):
+    return [args for query, args in cur.statements if query.startswith("INSERT INTO run_metrics_history")]
+
+
+def test_each_metrics_update_appends_a_history_row(monkeypatch):
+    cur = _record(monkeypatch)
+    db.insert_metrics_snapshot("r1", _metrics(1.0, 3), 60)
+    db.insert_metrics_snapshot("r1", _metrics(0.8, 6), 120)
+    db.insert_metrics("r1", _metrics(0.7, 10), 180)
+
+    assert _history(cur) == [
+        ("r1", 60, 1.0, 120.5, 30.0, 4.0, 3, 0, 10),
+        ("r1", 120, 0.8, 120.5, 30.0, 4.0, 6, 0, 10),
+        ("r1", 180, 0.7, 120.5, 30.0, 4.0, 10, 0, 10),
+    ]
+
+
+def test_snapshots_leave_final_metrics_alone(monkeypatch):
+    cur = _record(monkeypatch)
+    db.insert_metrics_snapshot("r1", _metrics(1.0, 3), 60)
+    assert not [q for q, _ in cur.statements if q.startswith("INSERT INTO run_metrics ")]
+
+    db.insert_metrics("r1", _metrics(0.7, 10))
+    upserts = [args for q, args in cur.statements if q.startswith("INSERT INTO run_metrics (")]
+    assert upserts == [("r1", 0.7, 120.5, 30.0, 4.0, 10, 0, 10)]
+    assert _history(cur)[-1][1] is None
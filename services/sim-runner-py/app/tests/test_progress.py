@@ -0,0 +1,16 @@
+from app.sim.metrics import progress_pct
+from app.sim.world import generate_scenario
+
+
+def test_progress_counts_finished_jobs():
+    _, jobs, _ = generate_scenario(seed=42, scale="demo", world_size=100, robots_override=2, jobs_override=4)
+    assert progress_pct(jobs) == 0.0
+    jobs[0].state = "completed"
+    jobs[1].state = "failed"
+    assert progress_pct(jobs) == 50.0
+    jobs[2].state = "in_progress"
+    assert progress_pct(jobs) == 50.0
+
+
+def test_progress_of_empty_run_is_complete():
+    assert progress_pct([]) == 100.0
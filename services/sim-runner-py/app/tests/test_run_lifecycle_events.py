@@ -0,0 +1,37 @@
+from app.lifecycle import EVENT_SOURCE, cancelled_run_id, is_own_event, should_announce_start
+
+
+def test_run_created_without_alias_announces_start():
+    assert should_announce_start("run.created", {"run_id": "r1", "run_started_alias": False})
+    assert should_announce_start("run.created", {"run_id": "r1"})
+
+
+def test_run_created_with_alias_does_not_announce_start():
+    assert not should_announce_start("run.created", {"run_id": "r1", "run_started_alias": True})
+
+
+def test_legacy_run_started_does_not_announce_start():
+    assert not should_announce_start("run.started", {"run_id": "r1"})
+
+
+def test_own_run_started_is_recognized():
+    assert is_own_event({"run_id": "r1", "source": EVENT_SOURCE})
+    assert not is_own_event({"run_id": "r1"})
+
+
+def test_run_cancelled_targets_its_run():
+    event = {
+        "event_type": "run.cancelled",
+        "run_id": "r1",
+        "mode": "ga",
+        "seed": 42,
+        "scale": "demo",
+        "scenario_hash": "abc",
+        "scenario_hash_version": 1,
+    }
+    assert cancelled_run_id(event) == "r1"
+
+
+def test_run_cancelled_without_run_id_is_ignored():
+    assert cancelled_run_id({"event_type": "run.cancelled"}) is None
+    assert cancelled_run_id({"event_type": "run.created", "run_id": "r1"}) is None